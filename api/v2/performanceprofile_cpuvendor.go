@@ -0,0 +1,27 @@
+package v2
+
+// CPUVendorLabel is the node label with the CPU vendor reported by the node feature discovery
+const CPUVendorLabel = "feature.node.kubernetes.io/cpu-model.vendor_id"
+
+// GetCPUVendor returns the CPU vendor of nodes selected by the profile, the vendor specified under the profile
+// takes precedence over the vendor selected by node labels under the node selector
+func GetCPUVendor(profile *PerformanceProfile) CPUVendor {
+	spec := &profile.Spec
+	if spec.CPU != nil && spec.CPU.Vendor != nil {
+		return *spec.CPU.Vendor
+	}
+
+	switch CPUVendor(spec.NodeSelector[CPUVendorLabel]) {
+	case CPUVendorIntel:
+		return CPUVendorIntel
+	case CPUVendorAMD:
+		return CPUVendorAMD
+	case CPUVendorARM:
+		return CPUVendorARM
+	}
+
+	if GetArchitecture(profile) == ArchitectureARM64 {
+		return CPUVendorARM
+	}
+	return CPUVendorIntel
+}
@@ -0,0 +1,232 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+	"github.com/openshift-kni/performance-addon-operators/pkg/hardware"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hardware snapshots are read directly from the API server, so the webhook does not cache all ConfigMaps of the cluster
+var hardwareReader client.Reader
+
+// validateHardware validates the profile against the hardware snapshot referenced by the profile
+func (r *PerformanceProfile) validateHardware() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.HardwareRef == nil {
+		return allErrs
+	}
+
+	path := field.NewPath("spec.hardwareRef")
+	if r.Spec.HardwareRef.Name == "" {
+		allErrs = append(allErrs, field.Required(path.Child("name"), "the ConfigMap name required"))
+	}
+
+	if r.Spec.HardwareRef.Namespace == "" {
+		allErrs = append(allErrs, field.Required(path.Child("namespace"), "the ConfigMap namespace required"))
+	} else if r.Spec.HardwareRef.Namespace != operatorNamespace {
+		allErrs = append(allErrs, field.NotSupported(path.Child("namespace"), r.Spec.HardwareRef.Namespace, []string{operatorNamespace}))
+	}
+
+	if len(allErrs) != 0 || hardwareReader == nil {
+		return allErrs
+	}
+
+	configMap := &corev1.ConfigMap{}
+	key := types.NamespacedName{Name: r.Spec.HardwareRef.Name, Namespace: r.Spec.HardwareRef.Namespace}
+	if err := hardwareReader.Get(context.TODO(), key, configMap); err != nil {
+		if apierrors.IsNotFound(err) {
+			return append(allErrs, field.NotFound(path, key.String()))
+		}
+		return append(allErrs, field.InternalError(path, err))
+	}
+
+	data, ok := configMap.BinaryData[HardwareSnapshotKey]
+	if !ok {
+		return append(allErrs, field.Invalid(path, key.String(), fmt.Sprintf("the ConfigMap does not have the %q binary data", HardwareSnapshotKey)))
+	}
+
+	info, err := hardware.LoadSnapshot(data)
+	if err != nil {
+		return append(allErrs, field.Invalid(path, key.String(), fmt.Sprintf("failed to load the hardware snapshot: %v", err)))
+	}
+
+	allErrs = append(allErrs, r.validateHardwareCPUs(info)...)
+	allErrs = append(allErrs, r.validateHardwareHugePages(info)...)
+	allErrs = append(allErrs, r.validateHardwareNetDevices(info)...)
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateHardwareCPUs(info *hardware.Info) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.CPU == nil {
+		return allErrs
+	}
+
+	online := cpuallocation.TotalCPUSet(info.Topology.Nodes)
+	validateCPUSet := func(path *field.Path, cpus *CPUSet) {
+		if cpus == nil {
+			return
+		}
+
+		set, err := cpuset.Parse(string(*cpus))
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(path, err))
+			return
+		}
+
+		if missing := set.Difference(online); !missing.IsEmpty() {
+			allErrs = append(allErrs, field.Invalid(path, *cpus, fmt.Sprintf("CPUs %s do not exist on the hardware, the hardware has CPUs %s", missing, online)))
+		}
+	}
+
+	// the kubelet allocates whole physical cores, so reserved CPUs should not leave a part of the core
+	fullPCPUsOnly := r.hasCPUManagerPolicyOption(CPUManagerPolicyOptionFullPCPUsOnly)
+	validateReservedCores := func(path *field.Path, cpus *CPUSet) {
+		if cpus == nil || !fullPCPUsOnly {
+			return
+		}
+
+		set, err := cpuset.Parse(string(*cpus))
+		if err != nil {
+			return
+		}
+
+		if split := getSplitCores(set, info.Topology.Nodes); !split.IsEmpty() {
+			allErrs = append(allErrs, field.Invalid(path, *cpus, fmt.Sprintf("reserved CPUs should take whole physical cores with the %s CPU manager policy option, cores of CPUs %s are split", CPUManagerPolicyOptionFullPCPUsOnly, split)))
+		}
+	}
+
+	validateCPUSet(field.NewPath("spec.cpu.reserved"), r.Spec.CPU.Reserved)
+	validateReservedCores(field.NewPath("spec.cpu.reserved"), r.Spec.CPU.Reserved)
+	validateCPUSet(field.NewPath("spec.cpu.isolated"), r.Spec.CPU.Isolated)
+	for i, override := range r.Spec.CPU.NodeOverrides {
+		path := field.NewPath("spec.cpu.nodeOverrides").Index(i)
+		validateCPUSet(path.Child("isolated"), override.Isolated)
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateHardwareHugePages(info *hardware.Info) field.ErrorList {
+	var allErrs field.ErrorList
+
+	footprint, err := GetHugePagesFootprint(r.Spec.HugePages)
+	if err != nil {
+		return append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages, err.Error()))
+	}
+
+	if footprint == nil {
+		return allErrs
+	}
+
+	numaNodes := map[int]bool{}
+	for _, node := range info.Topology.Nodes {
+		numaNodes[node.ID] = true
+	}
+
+	for i, page := range r.Spec.HugePages.Pages {
+		if page.Node != nil && !numaNodes[int(*page.Node)] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages").Index(i).Child("node"), *page.Node, fmt.Sprintf("the NUMA node %d does not exist on the hardware", *page.Node)))
+		}
+	}
+
+	if footprint.Total.Value() > info.TotalUsableMemory {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages,
+			fmt.Sprintf("huge pages take %s, more than %s of the usable memory", footprint.Total.String(), formatBytes(info.TotalUsableMemory))))
+	}
+
+	// pages without the NUMA node are allocated equally between NUMA nodes
+	numaTotal := map[int]int64{}
+	for _, node := range info.Topology.Nodes {
+		if footprint.Distributed != nil {
+			numaTotal[node.ID] = footprint.Distributed.Value() / int64(len(info.Topology.Nodes))
+		}
+	}
+	for _, numaNode := range footprint.NUMANodes {
+		numaTotal[int(numaNode.Node)] += numaNode.Memory.Value()
+	}
+
+	for _, node := range info.Topology.Nodes {
+		memory, ok := info.NUMAMemory[node.ID]
+		if !ok || numaTotal[node.ID] <= memory {
+			continue
+		}
+
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages,
+			fmt.Sprintf("huge pages take %s on the NUMA node %d, more than %s of the NUMA node memory", formatBytes(numaTotal[node.ID]), node.ID, formatBytes(memory))))
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateHardwareNetDevices(info *hardware.Info) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// old snapshots do not have network devices
+	if r.Spec.Net == nil || len(info.NetDevices) == 0 {
+		return allErrs
+	}
+
+	for i, device := range r.Spec.Net.Devices {
+		if device.VendorID == nil {
+			continue
+		}
+
+		found := false
+		for _, netDevice := range info.NetDevices {
+			if !isSameHexID(*device.VendorID, netDevice.VendorID) {
+				continue
+			}
+
+			if device.DeviceID == nil || isSameHexID(*device.DeviceID, netDevice.DeviceID) {
+				found = true
+				break
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		message := fmt.Sprintf("the network device with the vendor ID %s does not exist on the hardware", *device.VendorID)
+		if device.DeviceID != nil {
+			message = fmt.Sprintf("the network device with the vendor ID %s and the device ID %s does not exist on the hardware", *device.VendorID, *device.DeviceID)
+		}
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.net.devices").Index(i), device, message))
+	}
+
+	return allErrs
+}
+
+// isSameHexID compares hexadecimal IDs, so 0x00ab and 0xAB are the same IDs
+func isSameHexID(id1, id2 string) bool {
+	v1, err := strconv.ParseUint(id1, 0, 16)
+	if err != nil {
+		return false
+	}
+
+	v2, err := strconv.ParseUint(id2, 0, 16)
+	if err != nil {
+		return false
+	}
+
+	return v1 == v2
+}
+
+func formatBytes(bytes int64) string {
+	return resource.NewQuantity(bytes, resource.BinarySI).String()
+}
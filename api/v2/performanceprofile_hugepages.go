@@ -0,0 +1,410 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// hugePagesMinFreeMemory is the memory reserved by the generated kubelet config for the system and kubelet,
+// huge pages that leave less memory make the node unusable for workloads
+var hugePagesMinFreeMemory = resource.MustParse("1Gi")
+
+// GetHugePageSizeBytes returns the size of the huge page in bytes
+func GetHugePageSizeBytes(size HugePageSize) (int64, error) {
+	// huge page sizes use binary units without the "i" suffix
+	quantity, err := resource.ParseQuantity(string(size) + "i")
+	if err != nil {
+		return 0, fmt.Errorf("invalid huge page size %q: %v", size, err)
+	}
+	return quantity.Value(), nil
+}
+
+// the CPU architectures reported by the node architecture label
+const (
+	ArchitectureAMD64   = "amd64"
+	ArchitectureARM64   = "arm64"
+	ArchitecturePPC64LE = "ppc64le"
+	ArchitectureS390X   = "s390x"
+)
+
+// the page sizes of arm64 kernels, huge page sizes of arm64 depend on the page granule of the kernel
+const (
+	KernelPageSize4K  = "4K"
+	KernelPageSize16K = "16K"
+	KernelPageSize64K = "64K"
+)
+
+// HugePagesArchitecture describes huge pages supported by the CPU architecture
+type HugePagesArchitecture struct {
+	// Architecture is the CPU architecture of nodes
+	Architecture string
+	// KernelPageSize is the page size of the kernel, it is set only for architectures with huge page sizes
+	// that depend on it
+	KernelPageSize string
+	// Sizes contains huge page sizes supported by the architecture
+	Sizes []HugePageSize
+	// DefaultSize is the default huge page size of the kernel when the default size is not specified
+	DefaultSize HugePageSize
+	// RegisteredSizes contains huge page sizes that the kernel registers on boot regardless of the default
+	// huge page size, other sizes should be registered via kernel arguments to be allocated at runtime
+	RegisteredSizes []HugePageSize
+}
+
+// hugePagesArchitectures contains huge pages supported by each architecture
+var hugePagesArchitectures = map[string]HugePagesArchitecture{
+	ArchitectureAMD64: {
+		Sizes:           []HugePageSize{hugepagesSize1G, hugepagesSize2M},
+		DefaultSize:     hugepagesSize2M,
+		RegisteredSizes: []HugePageSize{hugepagesSize1G},
+	},
+	ArchitecturePPC64LE: {
+		Sizes:           []HugePageSize{"2M", "16M", "1G", "16G"},
+		DefaultSize:     "2M",
+		RegisteredSizes: []HugePageSize{"2M", "16M", "1G", "16G"},
+	},
+	ArchitectureS390X: {
+		Sizes:           []HugePageSize{"1M", "2G"},
+		DefaultSize:     "1M",
+		RegisteredSizes: []HugePageSize{"1M", "2G"},
+	},
+}
+
+// arm64HugePages contains huge pages supported by arm64 kernels with each page size, the sizes of contiguous
+// PTE, PMD and contiguous PMD mappings, and PUD mappings with 4K pages, the default size is the PMD size
+var arm64HugePages = map[string]HugePagesArchitecture{
+	KernelPageSize4K: {
+		Sizes:           []HugePageSize{"64K", "2M", "32M", "1G"},
+		DefaultSize:     "2M",
+		RegisteredSizes: []HugePageSize{"64K", "2M", "32M", "1G"},
+	},
+	KernelPageSize16K: {
+		Sizes:           []HugePageSize{"2M", "32M", "1G"},
+		DefaultSize:     "32M",
+		RegisteredSizes: []HugePageSize{"2M", "32M", "1G"},
+	},
+	KernelPageSize64K: {
+		Sizes:           []HugePageSize{"2M", "512M", "16G"},
+		DefaultSize:     "512M",
+		RegisteredSizes: []HugePageSize{"2M", "512M", "16G"},
+	},
+}
+
+// GetArchitecture returns the architecture of nodes selected by the profile, the architecture under the profile
+// annotation set from nodes is preferred, otherwise nodes are expected to have the architecture of the node
+// selector label, or the arm64 architecture with the ARM CPU vendor and the amd64 architecture otherwise
+func GetArchitecture(profile *PerformanceProfile) string {
+	if arch, ok := profile.Annotations[ArchitectureAnnotation]; ok {
+		return arch
+	}
+
+	if arch, ok := profile.Spec.NodeSelector[corev1.LabelArchStable]; ok {
+		return arch
+	}
+
+	if profile.Spec.CPU != nil && profile.Spec.CPU.Vendor != nil && *profile.Spec.CPU.Vendor == CPUVendorARM {
+		return ArchitectureARM64
+	}
+	return ArchitectureAMD64
+}
+
+// GetKernelPageSize returns the kernel page size of nodes selected by the profile, nodes are expected
+// to run kernels with 4K pages when the profile annotation does not specify the page size
+func GetKernelPageSize(profile *PerformanceProfile) string {
+	if pageSize, ok := profile.Annotations[KernelPageSizeAnnotation]; ok {
+		return pageSize
+	}
+	return KernelPageSize4K
+}
+
+// GetHugePagesArchitecture returns huge pages supported by the architecture and the kernel page size of nodes
+// selected by the profile
+func GetHugePagesArchitecture(profile *PerformanceProfile) (*HugePagesArchitecture, error) {
+	arch := GetArchitecture(profile)
+	if arch == ArchitectureARM64 {
+		pageSize := GetKernelPageSize(profile)
+		hugePagesArch, ok := arm64HugePages[pageSize]
+		if !ok {
+			return nil, fmt.Errorf("unsupported kernel page size %q of the %s architecture", pageSize, arch)
+		}
+		hugePagesArch.Architecture = arch
+		hugePagesArch.KernelPageSize = pageSize
+		return &hugePagesArch, nil
+	}
+
+	hugePagesArch, ok := hugePagesArchitectures[arch]
+	if !ok {
+		return nil, fmt.Errorf("unsupported architecture %q", arch)
+	}
+	hugePagesArch.Architecture = arch
+	return &hugePagesArch, nil
+}
+
+// String returns the description of the architecture used by messages
+func (a *HugePagesArchitecture) String() string {
+	if a.KernelPageSize == "" {
+		return fmt.Sprintf("%s architecture", a.Architecture)
+	}
+	return fmt.Sprintf("%s architecture with %s kernel pages", a.Architecture, a.KernelPageSize)
+}
+
+// IsSupportedSize returns whether or not the architecture supports the huge page size
+func (a *HugePagesArchitecture) IsSupportedSize(size HugePageSize) bool {
+	return ContainsHugePageSize(a.Sizes, size)
+}
+
+// IsRegisteredSize returns whether or not the kernel registers the huge page size on boot
+// when the default huge page size is set to the specified one
+func (a *HugePagesArchitecture) IsRegisteredSize(size HugePageSize, defaultSize HugePageSize) bool {
+	if defaultSize == "" {
+		defaultSize = a.DefaultSize
+	}
+	return size == defaultSize || ContainsHugePageSize(a.RegisteredSizes, size)
+}
+
+// ContainsHugePageSize returns whether or not the huge page size is one of the sizes
+func ContainsHugePageSize(sizes []HugePageSize, size HugePageSize) bool {
+	for _, s := range sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
+
+// getNodeKernelPageSize returns the page size of the kernel run by the node, arm64 kernels with other than 4K
+// pages have the page size suffix in the kernel release, for example 5.14.0-284.el9.aarch64+64k
+func getNodeKernelPageSize(node *corev1.Node) string {
+	kernelVersion := strings.ToUpper(node.Status.NodeInfo.KernelVersion)
+	for _, pageSize := range []string{KernelPageSize16K, KernelPageSize64K} {
+		if strings.HasSuffix(kernelVersion, "+"+pageSize) || strings.HasSuffix(kernelVersion, "-"+pageSize) {
+			return pageSize
+		}
+	}
+	return KernelPageSize4K
+}
+
+// SetNodesArchitecture sets the architecture and the kernel page size of nodes under profile annotations,
+// nodes without the architecture label are skipped, so the profile is not changed when none of the nodes
+// report the architecture, nodes selected by the profile should have the same architecture and page size
+func SetNodesArchitecture(profile *PerformanceProfile, nodes []corev1.Node) error {
+	architectures := map[string]bool{}
+	pageSizes := map[string]bool{}
+	for i := range nodes {
+		node := &nodes[i]
+		arch, ok := node.Labels[corev1.LabelArchStable]
+		if !ok {
+			continue
+		}
+
+		architectures[arch] = true
+		if arch == ArchitectureARM64 && node.Status.NodeInfo.KernelVersion != "" {
+			pageSizes[getNodeKernelPageSize(node)] = true
+		}
+	}
+
+	if len(architectures) == 0 {
+		return nil
+	}
+
+	if len(architectures) > 1 {
+		return fmt.Errorf("nodes selected by the profile have different architectures %s", strings.Join(getSortedKeys(architectures), ", "))
+	}
+
+	if len(pageSizes) > 1 {
+		return fmt.Errorf("nodes selected by the profile run kernels with different page sizes %s", strings.Join(getSortedKeys(pageSizes), ", "))
+	}
+
+	if profile.Annotations == nil {
+		profile.Annotations = map[string]string{}
+	}
+
+	for arch := range architectures {
+		profile.Annotations[ArchitectureAnnotation] = arch
+	}
+
+	for pageSize := range pageSizes {
+		profile.Annotations[KernelPageSizeAnnotation] = pageSize
+	}
+	return nil
+}
+
+// getSortedKeys returns sorted keys of the map
+func getSortedKeys(m map[string]bool) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatHugePageSizes returns quoted sizes separated by commas and the "or" before the last size
+func formatHugePageSizes(sizes []HugePageSize) string {
+	var quoted []string
+	for _, size := range sizes {
+		quoted = append(quoted, fmt.Sprintf("%q", size))
+	}
+
+	if len(quoted) < 2 {
+		return strings.Join(quoted, "")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " or " + quoted[len(quoted)-1]
+}
+
+// GetHugePagesFootprint returns the memory taken by huge pages on each node, the nil value means
+// that huge pages are not allocated
+func GetHugePagesFootprint(hugePages *HugePages) (*HugePagesStatus, error) {
+	if hugePages == nil || len(hugePages.Pages) == 0 {
+		return nil, nil
+	}
+
+	var total, distributed int64
+	numaNodes := map[int32]int64{}
+	for _, page := range hugePages.Pages {
+		size, err := GetHugePageSizeBytes(page.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		bytes := size * int64(page.Count)
+		total += bytes
+		if page.Node == nil {
+			distributed += bytes
+			continue
+		}
+		numaNodes[*page.Node] += bytes
+	}
+
+	status := &HugePagesStatus{
+		Total: *resource.NewQuantity(total, resource.BinarySI),
+	}
+
+	if distributed != 0 {
+		status.Distributed = resource.NewQuantity(distributed, resource.BinarySI)
+	}
+
+	for node, bytes := range numaNodes {
+		status.NUMANodes = append(status.NUMANodes, NUMAHugePagesStatus{
+			Node:   node,
+			Memory: *resource.NewQuantity(bytes, resource.BinarySI),
+		})
+	}
+	sort.Slice(status.NUMANodes, func(i, j int) bool {
+		return status.NUMANodes[i].Node < status.NUMANodes[j].Node
+	})
+
+	return status, nil
+}
+
+// GetNodesWithoutHugePagesMemory returns names of nodes with the memory capacity less than the memory taken by huge pages
+func GetNodesWithoutHugePagesMemory(footprint *HugePagesStatus, nodes []corev1.Node) []string {
+	return getNodesWithMemoryLessThan(footprint.Total, nodes)
+}
+
+// getNodesWithMemoryLessThan returns sorted names of nodes with the memory capacity less than the specified memory,
+// nodes that do not report the memory capacity are skipped
+func getNodesWithMemoryLessThan(memory resource.Quantity, nodes []corev1.Node) []string {
+	var names []string
+	for _, node := range nodes {
+		capacity, ok := node.Status.Capacity[corev1.ResourceMemory]
+		if !ok || capacity.IsZero() {
+			continue
+		}
+
+		if capacity.Cmp(memory) < 0 {
+			names = append(names, node.Name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// getSelectedNodes returns nodes selected by the profile node selector
+func (r *PerformanceProfile) getSelectedNodes() ([]corev1.Node, error) {
+	nodes := &corev1.NodeList{}
+	selector := labels.SelectorFromSet(r.Spec.NodeSelector)
+	if err := validatorClient.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+	return nodes.Items, nil
+}
+
+// validateHugePagesCapacity rejects huge pages that do not fit into the memory capacity of nodes selected by the profile
+func (r *PerformanceProfile) validateHugePagesCapacity() field.ErrorList {
+	var allErrs field.ErrorList
+
+	// overlays are merged into the base profile, so the base profile is checked instead
+	if validatorClient == nil || r.Spec.Overlay != nil {
+		return allErrs
+	}
+
+	footprint, err := GetHugePagesFootprint(r.Spec.HugePages)
+	if err != nil || footprint == nil {
+		return allErrs
+	}
+
+	nodes, err := r.getSelectedNodes()
+	if err != nil {
+		return append(allErrs, field.InternalError(field.NewPath("spec.hugepages"), err))
+	}
+
+	if names := GetNodesWithoutHugePagesMemory(footprint, nodes); len(names) != 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages,
+			fmt.Sprintf("huge pages take %s, more than the memory capacity of nodes %s", footprint.Total.String(), strings.Join(names, ", "))))
+	}
+
+	return allErrs
+}
+
+// getHugePagesWarnings returns warnings about huge pages that leave too little memory on nodes selected by the profile
+func (r *PerformanceProfile) getHugePagesWarnings() []string {
+	if validatorClient == nil || r.Spec.Overlay != nil {
+		return nil
+	}
+
+	footprint, err := GetHugePagesFootprint(r.Spec.HugePages)
+	if err != nil || footprint == nil {
+		return nil
+	}
+
+	nodes, err := r.getSelectedNodes()
+	if err != nil {
+		klog.Errorf("failed to list nodes of the performance profile %q: %v", r.Name, err)
+		return nil
+	}
+
+	// nodes without the memory for huge pages are rejected by the validation
+	withoutHugePagesMemory := map[string]bool{}
+	for _, name := range GetNodesWithoutHugePagesMemory(footprint, nodes) {
+		withoutHugePagesMemory[name] = true
+	}
+
+	required := footprint.Total.DeepCopy()
+	required.Add(hugePagesMinFreeMemory)
+
+	var names []string
+	for _, name := range getNodesWithMemoryLessThan(required, nodes) {
+		if !withoutHugePagesMemory[name] {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("performance profile %q: huge pages take %s, less than %s of memory remain for the system and workloads on nodes %s",
+			r.Name, footprint.Total.String(), hugePagesMinFreeMemory.String(), strings.Join(names, ", ")),
+	}
+}
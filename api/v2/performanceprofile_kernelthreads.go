@@ -0,0 +1,67 @@
+package v2
+
+const (
+	// KernelThreadsGroupKsoftirqd is the group of threads handling soft interrupts
+	KernelThreadsGroupKsoftirqd = "ksoftirqd"
+	// KernelThreadsGroupRcuc is the group of threads handling RCU callbacks
+	KernelThreadsGroupRcuc = "rcuc"
+	// KernelThreadsGroupKtimersoftd is the group of threads handling timer soft interrupts on real-time kernels
+	KernelThreadsGroupKtimersoftd = "ktimersoftd"
+	// KernelThreadsGroupKworker is the group of kernel workqueue threads
+	KernelThreadsGroupKworker = "kworker"
+)
+
+// knownKernelThreadsGroups contains regular expressions of groups that do not require the regex
+var knownKernelThreadsGroups = map[string]string{
+	KernelThreadsGroupKsoftirqd:   "ksoftirqd.*",
+	KernelThreadsGroupRcuc:        "rcuc.*",
+	KernelThreadsGroupKtimersoftd: "ktimersoftd.*",
+	KernelThreadsGroupKworker:     "kworker.*",
+}
+
+// DefaultKernelThreadsGroups contains groups of kernel threads scheduled when the profile does not specify them
+var DefaultKernelThreadsGroups = []string{KernelThreadsGroupKsoftirqd, KernelThreadsGroupRcuc}
+
+// DefaultKernelThreadsPriority is the priority of kernel threads with real-time policies
+const DefaultKernelThreadsPriority = 11
+
+// GetKernelThreadsGroupRegex returns the regular expression matching kernel threads of the group
+func GetKernelThreadsGroupRegex(group *KernelThreadsGroup) string {
+	if group.Regex != nil {
+		return *group.Regex
+	}
+	return knownKernelThreadsGroups[group.Name]
+}
+
+// GetKernelThreadsGroupPolicy returns the scheduling policy of kernel threads of the group
+func GetKernelThreadsGroupPolicy(group *KernelThreadsGroup) SchedulingPolicy {
+	if group.Policy != nil {
+		return *group.Policy
+	}
+	return SchedulingPolicyFIFO
+}
+
+// GetKernelThreadsGroupPriority returns the scheduling priority of kernel threads of the group
+func GetKernelThreadsGroupPriority(group *KernelThreadsGroup) int32 {
+	if group.Priority != nil {
+		return *group.Priority
+	}
+
+	if IsRealtimeSchedulingPolicy(GetKernelThreadsGroupPolicy(group)) {
+		return DefaultKernelThreadsPriority
+	}
+	return 0
+}
+
+// IsRealtimeSchedulingPolicy returns whether or not the policy is a real-time scheduling policy
+func IsRealtimeSchedulingPolicy(policy SchedulingPolicy) bool {
+	return policy == SchedulingPolicyFIFO || policy == SchedulingPolicyRoundRobin
+}
+
+// GetRCUOffloadMode returns the offload mode of RCU callbacks from isolated CPUs
+func GetRCUOffloadMode(spec *PerformanceProfileSpec) RCUOffloadMode {
+	if spec.KernelThreads != nil && spec.KernelThreads.RCUOffload != nil {
+		return *spec.KernelThreads.RCUOffload
+	}
+	return RCUOffloadNoCBs
+}
@@ -0,0 +1,222 @@
+package v2
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+)
+
+const (
+	// DefaultReservedCPU is the CPU reserved for kubernetes and system daemons each by default
+	DefaultReservedCPU = "1000m"
+	// DefaultReservedMemory is the memory reserved for kubernetes and system daemons each by default
+	DefaultReservedMemory = "500Mi"
+	// EvictionSignalMemoryAvailable is the eviction signal of the available node memory
+	EvictionSignalMemoryAvailable = "memory.available"
+	// defaultEvictionHardMemoryAvailable is the memory.available hard eviction threshold of the kubelet
+	// when the kubelet config does not specify hard eviction thresholds
+	defaultEvictionHardMemoryAvailable = "100Mi"
+)
+
+// kubeletConfigOwnedFields contains kubelet config fields generated by the operator that can not be overridden,
+// the value is the profile field that sets the kubelet config field
+var kubeletConfigOwnedFields = map[string]string{
+	"apiVersion":                "",
+	"kind":                      "",
+	"cpuManagerPolicy":          "",
+	"cpuManagerPolicyOptions":   "spec.cpu.managerPolicyOptions",
+	"cpuManagerReconcilePeriod": "spec.kubelet.cpuManagerReconcilePeriod",
+	"reservedSystemCPUs":        "spec.cpu.reserved",
+	"topologyManagerPolicy":     "spec.numa.topologyPolicy",
+	"topologyManagerScope":      "spec.numa.topologyManagerScope",
+	"memoryManagerPolicy":       "spec.kubelet.memoryManager.policy",
+	"reservedMemory":            "spec.kubelet.memoryManager.reservedMemory",
+	"kubeReserved":              "spec.kubelet.kubeReserved",
+	"systemReserved":            "spec.kubelet.systemReserved",
+	"evictionHard":              "spec.kubelet.evictionHard",
+	// feature gates of the kubelet are rendered by the machine config operator from the cluster FeatureGate resource
+	"featureGates": "",
+}
+
+// kubeletConfigFieldNames contains JSON names of kubelet config fields keyed by lower case names, the kubelet config
+// is decoded with case-insensitive field names, so overrides are normalized to JSON names before they are checked
+// and merged
+var kubeletConfigFieldNames = getKubeletConfigFieldNames()
+
+// getKubeletConfigFieldNames returns JSON names of fields of the vendored kubelet config and of fields owned
+// by the operator that newer kubelets added, like cpuManagerPolicyOptions, keyed by lower case names
+func getKubeletConfigFieldNames() map[string]string {
+	names := getJSONFieldNames(reflect.TypeOf(kubeletconfigv1beta1.KubeletConfiguration{}))
+	for name := range kubeletConfigOwnedFields {
+		names[strings.ToLower(name)] = name
+	}
+	return names
+}
+
+// getJSONFieldNames returns JSON names of struct fields keyed by lower case names, fields of inlined structs included
+func getJSONFieldNames(t reflect.Type) map[string]string {
+	names := map[string]string{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if f.Anonymous && name == "" {
+			for key, value := range getJSONFieldNames(f.Type) {
+				names[key] = value
+			}
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		names[strings.ToLower(name)] = name
+	}
+	return names
+}
+
+// GetKubeletConfigOverrides returns kubelet config fields of the profile overrides keyed by JSON names of fields,
+// the nil value means that the profile does not override the kubelet config
+func GetKubeletConfigOverrides(spec *PerformanceProfileSpec) (map[string]interface{}, error) {
+	if spec.KubeletConfigOverrides == nil || len(spec.KubeletConfigOverrides.Raw) == 0 {
+		return nil, nil
+	}
+
+	raw := map[string]interface{}{}
+	if err := json.Unmarshal(spec.KubeletConfigOverrides.Raw, &raw); err != nil {
+		return nil, fmt.Errorf("the kubelet config overrides should be a JSON object: %v", err)
+	}
+
+	overrides := map[string]interface{}{}
+	specified := map[string]string{}
+	for key, value := range raw {
+		name, ok := kubeletConfigFieldNames[strings.ToLower(key)]
+		if !ok {
+			// unknown fields are rejected by the validation
+			name = key
+		}
+
+		if other, ok := specified[name]; ok {
+			keys := []string{key, other}
+			sort.Strings(keys)
+			return nil, fmt.Errorf("the kubelet config field %q is specified more than once as %q and %q", name, keys[0], keys[1])
+		}
+		specified[name] = key
+		overrides[name] = value
+	}
+	return overrides, nil
+}
+
+// GetKubeletConfigOverridesConflicts returns messages about kubelet config fields owned by the operator
+// that are overridden by the profile
+func GetKubeletConfigOverridesConflicts(spec *PerformanceProfileSpec) ([]string, error) {
+	overrides, err := GetKubeletConfigOverrides(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	var conflicts []string
+	for name := range overrides {
+		profileField, ok := kubeletConfigOwnedFields[name]
+		if !ok {
+			continue
+		}
+
+		if profileField == "" {
+			conflicts = append(conflicts, fmt.Sprintf("the kubelet config field %q is owned by the operator", name))
+		} else {
+			conflicts = append(conflicts, fmt.Sprintf("the kubelet config field %q is owned by the operator, use %s instead", name, profileField))
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts, nil
+}
+
+// GetKubeReserved returns resources reserved for kubernetes daemons
+func GetKubeReserved(spec *PerformanceProfileSpec) corev1.ResourceList {
+	if spec.Kubelet != nil && spec.Kubelet.KubeReserved != nil {
+		return spec.Kubelet.KubeReserved
+	}
+	return getDefaultReserved()
+}
+
+// GetSystemReserved returns resources reserved for system daemons
+func GetSystemReserved(spec *PerformanceProfileSpec) corev1.ResourceList {
+	if spec.Kubelet != nil && spec.Kubelet.SystemReserved != nil {
+		return spec.Kubelet.SystemReserved
+	}
+	return getDefaultReserved()
+}
+
+func getDefaultReserved() corev1.ResourceList {
+	return corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(DefaultReservedCPU),
+		corev1.ResourceMemory: resource.MustParse(DefaultReservedMemory),
+	}
+}
+
+// GetMemoryManagerPolicy returns the policy of the kubelet memory manager
+func GetMemoryManagerPolicy(spec *PerformanceProfileSpec) MemoryManagerPolicy {
+	if spec.Kubelet != nil && spec.Kubelet.MemoryManager != nil && spec.Kubelet.MemoryManager.Policy != nil {
+		return *spec.Kubelet.MemoryManager.Policy
+	}
+	return MemoryManagerPolicyNone
+}
+
+// GetRequiredReservedMemory returns the memory and huge pages that the kubelet requires to be reserved on NUMA nodes
+// with the Static memory manager policy, the sum of memory and huge pages reserved for kubernetes and system daemons
+// and the memory.available hard eviction threshold
+func GetRequiredReservedMemory(spec *PerformanceProfileSpec) (corev1.ResourceList, error) {
+	required := corev1.ResourceList{}
+	for _, reserved := range []corev1.ResourceList{GetKubeReserved(spec), GetSystemReserved(spec)} {
+		for name, quantity := range reserved {
+			if !IsMemoryResource(name) {
+				continue
+			}
+			addQuantity(required, name, quantity)
+		}
+	}
+
+	evictionMemory := defaultEvictionHardMemoryAvailable
+	if spec.Kubelet != nil && spec.Kubelet.EvictionHard != nil {
+		evictionMemory = spec.Kubelet.EvictionHard[EvictionSignalMemoryAvailable]
+	}
+
+	if evictionMemory != "" {
+		// the kubelet requires the reserved memory to match the threshold, a percentage of the node capacity
+		// differs between nodes, so it can not be reserved by the kubelet config shared by nodes
+		if strings.HasSuffix(evictionMemory, "%") {
+			return nil, fmt.Errorf("the %s hard eviction threshold %q can not be a percentage with the %s memory manager policy, it should be a quantity",
+				EvictionSignalMemoryAvailable, evictionMemory, MemoryManagerPolicyStatic)
+		}
+
+		quantity, err := resource.ParseQuantity(evictionMemory)
+		if err != nil {
+			return nil, fmt.Errorf("the %s hard eviction threshold %q should be a quantity", EvictionSignalMemoryAvailable, evictionMemory)
+		}
+		addQuantity(required, corev1.ResourceMemory, quantity)
+	}
+
+	return required, nil
+}
+
+// IsMemoryResource returns whether or not the resource can be reserved on NUMA nodes by the memory manager
+func IsMemoryResource(name corev1.ResourceName) bool {
+	return name == corev1.ResourceMemory || strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix)
+}
+
+func addQuantity(resources corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	total := resources[name]
+	total.Add(quantity)
+	resources[name] = total
+}
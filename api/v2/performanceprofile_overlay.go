@@ -0,0 +1,432 @@
+package v2
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// GetOverlays returns overlays of the base profile that select the same nodes and machine config pool,
+// sorted in the order of merging
+func GetOverlays(base *PerformanceProfile, profiles []PerformanceProfile) []PerformanceProfile {
+	var overlays []PerformanceProfile
+	for i := range profiles {
+		profile := &profiles[i]
+		if profile.Spec.Overlay == nil || profile.Spec.Overlay.BaseProfile != base.Name {
+			continue
+		}
+
+		if !isSameSelectors(profile, base) {
+			continue
+		}
+		overlays = append(overlays, *profile)
+	}
+
+	sort.Slice(overlays, func(i, j int) bool {
+		if overlays[i].Spec.Overlay.Precedence != overlays[j].Spec.Overlay.Precedence {
+			return overlays[i].Spec.Overlay.Precedence < overlays[j].Spec.Overlay.Precedence
+		}
+		return overlays[i].Name < overlays[j].Name
+	})
+	return overlays
+}
+
+// GetIgnoredOverlays returns sorted names of overlays of the base profile that are not merged, because they select
+// other nodes or machine config pool, the webhook rejects such overlays, but they can still exist when the webhook
+// is not deployed or the base profile was changed afterwards
+func GetIgnoredOverlays(base *PerformanceProfile, profiles []PerformanceProfile) []string {
+	var ignored []string
+	for i := range profiles {
+		profile := &profiles[i]
+		if profile.Spec.Overlay == nil || profile.Spec.Overlay.BaseProfile != base.Name {
+			continue
+		}
+
+		if !isSameSelectors(profile, base) {
+			ignored = append(ignored, profile.Name)
+		}
+	}
+
+	sort.Strings(ignored)
+	return ignored
+}
+
+// GetEffectiveProfile returns the copy of the base profile with the merged specs of overlays,
+// overlays should be sorted in the order of merging, see GetOverlays, every profile is valid
+// by itself, but the merged spec can combine fields that are not allowed together, so it is
+// validated again
+func GetEffectiveProfile(base *PerformanceProfile, overlays []PerformanceProfile) (*PerformanceProfile, *OverlaysStatus, error) {
+	effective := base.DeepCopy()
+	if len(overlays) == 0 {
+		return effective, nil, nil
+	}
+
+	m := &overlayMerger{
+		effective: &effective.Spec,
+		setters:   map[string]string{},
+		profiles:  map[string][]string{},
+		conflicts: map[string]bool{},
+	}
+
+	// record fields of the base profile, so overlays that override them will be reported
+	m.merge(base.Name, &base.Spec)
+
+	status := &OverlaysStatus{}
+	for i := range overlays {
+		m.merge(overlays[i].Name, &overlays[i].Spec)
+		status.Applied = append(status.Applied, overlays[i].Name)
+	}
+
+	var fields []string
+	for field := range m.conflicts {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		status.Conflicts = append(status.Conflicts, OverlayConflict{
+			Field:       field,
+			Profiles:    m.profiles[field],
+			AppliedFrom: m.setters[field],
+		})
+	}
+
+	if errs := effective.validateFields(); len(errs) != 0 {
+		return nil, nil, fmt.Errorf("the profile merged with overlays %s is invalid: %v", strings.Join(status.Applied, ", "), errs.ToAggregate())
+	}
+	return effective, status, nil
+}
+
+// getMergedProfile returns the base profile of the profile merged with its overlays the same way the controller
+// merges them, the profile under validation replaces its stored version, the nil value means that the base
+// profile of the overlay does not exist
+func (r *PerformanceProfile) getMergedProfile(stored []PerformanceProfile) (*PerformanceProfile, error) {
+	profiles := []PerformanceProfile{*r}
+	for _, pp := range stored {
+		// overlays that are being deleted are not merged anymore
+		if pp.Name != r.Name && pp.DeletionTimestamp == nil {
+			profiles = append(profiles, pp)
+		}
+	}
+
+	base := &profiles[0]
+	if r.Spec.Overlay != nil {
+		base = nil
+		for i := range profiles {
+			if profiles[i].Name == r.Spec.Overlay.BaseProfile && profiles[i].Spec.Overlay == nil {
+				base = &profiles[i]
+			}
+		}
+	}
+
+	if base == nil {
+		return nil, nil
+	}
+
+	effective, _, err := GetEffectiveProfile(base, GetOverlays(base, profiles))
+	return effective, err
+}
+
+// isSameSelectors returns whether or not the overlay selects the same nodes and machine config pool
+// as the base profile, the overlay changes components of the base profile, so it can not target other nodes
+func isSameSelectors(overlay *PerformanceProfile, base *PerformanceProfile) bool {
+	return reflect.DeepEqual(overlay.Spec.NodeSelector, base.Spec.NodeSelector) &&
+		reflect.DeepEqual(overlay.Spec.MachineConfigPoolSelector, base.Spec.MachineConfigPoolSelector)
+}
+
+// overlayMerger merges specs of profiles and tracks fields set to different values
+type overlayMerger struct {
+	effective *PerformanceProfileSpec
+	// setters contains the name of the profile that set the effective value of the field
+	setters map[string]string
+	// profiles contains names of all profiles that set the field
+	profiles map[string][]string
+	// conflicts contains fields set to different values
+	conflicts map[string]bool
+}
+
+// set records that the profile sets the field, the value is applied via the apply function
+func (m *overlayMerger) set(profileName string, field string, current interface{}, value interface{}, apply func()) {
+	if setter, ok := m.setters[field]; ok && setter != profileName && !reflect.DeepEqual(current, value) {
+		m.conflicts[field] = true
+	}
+
+	m.setters[field] = profileName
+	m.profiles[field] = append(m.profiles[field], profileName)
+	apply()
+}
+
+// merge merges the source spec into the effective one
+func (m *overlayMerger) merge(profileName string, src *PerformanceProfileSpec) {
+	dst := m.effective
+
+	if src.CPU != nil {
+		if dst.CPU == nil {
+			dst.CPU = &CPU{}
+		}
+
+		if src.CPU.Reserved != nil {
+			m.set(profileName, "spec.cpu.reserved", dst.CPU.Reserved, src.CPU.Reserved, func() {
+				reserved := *src.CPU.Reserved
+				dst.CPU.Reserved = &reserved
+			})
+		}
+
+		if src.CPU.Isolated != nil {
+			m.set(profileName, "spec.cpu.isolated", dst.CPU.Isolated, src.CPU.Isolated, func() {
+				isolated := *src.CPU.Isolated
+				dst.CPU.Isolated = &isolated
+			})
+		}
+
+		if src.CPU.BalanceIsolated != nil {
+			m.set(profileName, "spec.cpu.balanceIsolated", dst.CPU.BalanceIsolated, src.CPU.BalanceIsolated, func() {
+				balanceIsolated := *src.CPU.BalanceIsolated
+				dst.CPU.BalanceIsolated = &balanceIsolated
+			})
+		}
+
+		if src.CPU.ReservedCount != nil {
+			m.set(profileName, "spec.cpu.reservedCount", dst.CPU.ReservedCount, src.CPU.ReservedCount, func() {
+				reservedCount := *src.CPU.ReservedCount
+				dst.CPU.ReservedCount = &reservedCount
+			})
+		}
+
+		if src.CPU.PlacementPolicy != nil {
+			m.set(profileName, "spec.cpu.placementPolicy", dst.CPU.PlacementPolicy, src.CPU.PlacementPolicy, func() {
+				placementPolicy := *src.CPU.PlacementPolicy
+				dst.CPU.PlacementPolicy = &placementPolicy
+			})
+		}
+
+		if src.CPU.Vendor != nil {
+			m.set(profileName, "spec.cpu.vendor", dst.CPU.Vendor, src.CPU.Vendor, func() {
+				vendor := *src.CPU.Vendor
+				dst.CPU.Vendor = &vendor
+			})
+		}
+
+		if src.CPU.NodeOverrides != nil {
+			m.set(profileName, "spec.cpu.nodeOverrides", dst.CPU.NodeOverrides, src.CPU.NodeOverrides, func() {
+				dst.CPU.NodeOverrides = nil
+				for i := range src.CPU.NodeOverrides {
+					dst.CPU.NodeOverrides = append(dst.CPU.NodeOverrides, *src.CPU.NodeOverrides[i].DeepCopy())
+				}
+			})
+		}
+
+		if src.CPU.ManagerPolicyOptions != nil {
+			m.set(profileName, "spec.cpu.managerPolicyOptions", dst.CPU.ManagerPolicyOptions, src.CPU.ManagerPolicyOptions, func() {
+				dst.CPU.ManagerPolicyOptions = append([]CPUManagerPolicyOption{}, src.CPU.ManagerPolicyOptions...)
+			})
+		}
+	}
+
+	if src.HugePages != nil {
+		if dst.HugePages == nil {
+			dst.HugePages = &HugePages{}
+		}
+
+		if src.HugePages.DefaultHugePagesSize != nil {
+			m.set(profileName, "spec.hugepages.defaultHugepagesSize", dst.HugePages.DefaultHugePagesSize, src.HugePages.DefaultHugePagesSize, func() {
+				size := *src.HugePages.DefaultHugePagesSize
+				dst.HugePages.DefaultHugePagesSize = &size
+			})
+		}
+
+		if src.HugePages.RuntimeAllocation != nil {
+			m.set(profileName, "spec.hugepages.runtimeAllocation", dst.HugePages.RuntimeAllocation, src.HugePages.RuntimeAllocation, func() {
+				runtimeAllocation := *src.HugePages.RuntimeAllocation
+				dst.HugePages.RuntimeAllocation = &runtimeAllocation
+			})
+		}
+
+		// pages are identified by the size and the NUMA node
+		for _, page := range src.HugePages.Pages {
+			page := page
+			field := fmt.Sprintf("spec.hugepages.pages[size=%s]", page.Size)
+			if page.Node != nil {
+				field = fmt.Sprintf("spec.hugepages.pages[size=%s,node=%d]", page.Size, *page.Node)
+			}
+
+			currentIndex := FindHugePage(dst.HugePages.Pages, page)
+			var current interface{}
+			if currentIndex != -1 {
+				current = dst.HugePages.Pages[currentIndex].Count
+			}
+
+			m.set(profileName, field, current, page.Count, func() {
+				if i := FindHugePage(dst.HugePages.Pages, page); i != -1 {
+					dst.HugePages.Pages[i].Count = page.Count
+					return
+				}
+				dst.HugePages.Pages = append(dst.HugePages.Pages, *page.DeepCopy())
+			})
+		}
+	}
+
+	if src.RealTimeKernel != nil && src.RealTimeKernel.Enabled != nil {
+		if dst.RealTimeKernel == nil {
+			dst.RealTimeKernel = &RealTimeKernel{}
+		}
+
+		m.set(profileName, "spec.realTimeKernel.enabled", dst.RealTimeKernel.Enabled, src.RealTimeKernel.Enabled, func() {
+			enabled := *src.RealTimeKernel.Enabled
+			dst.RealTimeKernel.Enabled = &enabled
+		})
+	}
+
+	// kernel arguments of all profiles are applied
+	for _, arg := range src.AdditionalKernelArgs {
+		if !containsString(dst.AdditionalKernelArgs, arg) {
+			dst.AdditionalKernelArgs = append(dst.AdditionalKernelArgs, arg)
+		}
+	}
+
+	if src.NUMA != nil {
+		if dst.NUMA == nil {
+			dst.NUMA = &NUMA{}
+		}
+
+		if src.NUMA.TopologyPolicy != nil {
+			m.set(profileName, "spec.numa.topologyPolicy", dst.NUMA.TopologyPolicy, src.NUMA.TopologyPolicy, func() {
+				policy := *src.NUMA.TopologyPolicy
+				dst.NUMA.TopologyPolicy = &policy
+			})
+		}
+
+		if src.NUMA.TopologyManagerScope != nil {
+			m.set(profileName, "spec.numa.topologyManagerScope", dst.NUMA.TopologyManagerScope, src.NUMA.TopologyManagerScope, func() {
+				scope := *src.NUMA.TopologyManagerScope
+				dst.NUMA.TopologyManagerScope = &scope
+			})
+		}
+	}
+
+	if src.Net != nil {
+		if dst.Net == nil {
+			dst.Net = &Net{}
+		}
+
+		if src.Net.UserLevelNetworking != nil {
+			m.set(profileName, "spec.net.userLevelNetworking", dst.Net.UserLevelNetworking, src.Net.UserLevelNetworking, func() {
+				userLevelNetworking := *src.Net.UserLevelNetworking
+				dst.Net.UserLevelNetworking = &userLevelNetworking
+			})
+		}
+
+		// devices of all profiles are applied
+		for _, device := range src.Net.Devices {
+			if !containsDevice(dst.Net.Devices, device) {
+				dst.Net.Devices = append(dst.Net.Devices, *device.DeepCopy())
+			}
+		}
+	}
+
+	if src.GloballyDisableIrqLoadBalancing != nil {
+		m.set(profileName, "spec.globallyDisableIrqLoadBalancing", dst.GloballyDisableIrqLoadBalancing, src.GloballyDisableIrqLoadBalancing, func() {
+			disabled := *src.GloballyDisableIrqLoadBalancing
+			dst.GloballyDisableIrqLoadBalancing = &disabled
+		})
+	}
+
+	if src.RuntimeClass != nil {
+		m.set(profileName, "spec.runtimeClass", dst.RuntimeClass, src.RuntimeClass, func() {
+			dst.RuntimeClass = src.RuntimeClass.DeepCopy()
+		})
+	}
+
+	if src.KubeletConfigOverrides != nil {
+		m.set(profileName, "spec.kubeletConfigOverrides", dst.KubeletConfigOverrides, src.KubeletConfigOverrides, func() {
+			dst.KubeletConfigOverrides = src.KubeletConfigOverrides.DeepCopy()
+		})
+	}
+
+	if src.Kubelet != nil {
+		m.set(profileName, "spec.kubelet", dst.Kubelet, src.Kubelet, func() {
+			dst.Kubelet = src.Kubelet.DeepCopy()
+		})
+	}
+
+	if src.TunedProfiles != nil {
+		m.set(profileName, "spec.tunedProfiles", dst.TunedProfiles, src.TunedProfiles, func() {
+			dst.TunedProfiles = nil
+			for i := range src.TunedProfiles {
+				dst.TunedProfiles = append(dst.TunedProfiles, *src.TunedProfiles[i].DeepCopy())
+			}
+		})
+	}
+
+	if src.Tuning != nil {
+		m.set(profileName, "spec.tuning", dst.Tuning, src.Tuning, func() {
+			dst.Tuning = src.Tuning.DeepCopy()
+		})
+	}
+
+	if src.KernelThreads != nil {
+		m.set(profileName, "spec.kernelThreads", dst.KernelThreads, src.KernelThreads, func() {
+			dst.KernelThreads = src.KernelThreads.DeepCopy()
+		})
+	}
+
+	if src.Stalld != nil {
+		m.set(profileName, "spec.stalld", dst.Stalld, src.Stalld, func() {
+			dst.Stalld = src.Stalld.DeepCopy()
+		})
+	}
+
+	if src.PowerPolicy != nil {
+		if dst.PowerPolicy == nil {
+			dst.PowerPolicy = &PowerPolicy{}
+		}
+
+		if src.PowerPolicy.Mode != nil {
+			m.set(profileName, "spec.powerPolicy.mode", dst.PowerPolicy.Mode, src.PowerPolicy.Mode, func() {
+				mode := *src.PowerPolicy.Mode
+				dst.PowerPolicy.Mode = &mode
+			})
+		}
+
+		if src.PowerPolicy.Reserved != nil {
+			m.set(profileName, "spec.powerPolicy.reserved", dst.PowerPolicy.Reserved, src.PowerPolicy.Reserved, func() {
+				dst.PowerPolicy.Reserved = src.PowerPolicy.Reserved.DeepCopy()
+			})
+		}
+
+		if src.PowerPolicy.Isolated != nil {
+			m.set(profileName, "spec.powerPolicy.isolated", dst.PowerPolicy.Isolated, src.PowerPolicy.Isolated, func() {
+				dst.PowerPolicy.Isolated = src.PowerPolicy.Isolated.DeepCopy()
+			})
+		}
+	}
+}
+
+// FindHugePage returns the index of the huge page with the same size and NUMA node, or -1 when pages do not have it
+func FindHugePage(pages []HugePage, page HugePage) int {
+	for i, p := range pages {
+		if p.Size == page.Size && reflect.DeepEqual(p.Node, page.Node) {
+			return i
+		}
+	}
+	return -1
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsDevice(devices []Device, device Device) bool {
+	for _, d := range devices {
+		if reflect.DeepEqual(d, device) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,182 @@
+package v2
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Overlays", func() {
+	var base *PerformanceProfile
+
+	BeforeEach(func() {
+		base = NewPerformanceProfile("base")
+	})
+
+	newOverlay := func(name string, precedence int32) PerformanceProfile {
+		return PerformanceProfile{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: PerformanceProfileSpec{
+				MachineConfigPoolSelector: base.Spec.MachineConfigPoolSelector,
+				NodeSelector:              base.Spec.NodeSelector,
+				Overlay: &Overlay{
+					BaseProfile: base.Name,
+					Precedence:  precedence,
+				},
+			},
+		}
+	}
+
+	It("should return overlays of the base profile sorted by the precedence and the name", func() {
+		another := newOverlay("another", 0)
+		another.Spec.Overlay.BaseProfile = "another-base"
+		otherPool := newOverlay("other-pool", 0)
+		otherPool.Spec.MachineConfigPoolSelector = map[string]string{"other": "pool"}
+		otherNodes := newOverlay("other-nodes", 0)
+		otherNodes.Spec.NodeSelector = map[string]string{"other": "nodes"}
+
+		overlays := GetOverlays(base, []PerformanceProfile{
+			*base,
+			newOverlay("c", 1),
+			newOverlay("b", 0),
+			newOverlay("a", 1),
+			another,
+			otherPool,
+			otherNodes,
+		})
+
+		var names []string
+		for _, overlay := range overlays {
+			names = append(names, overlay.Name)
+		}
+		Expect(names).To(Equal([]string{"b", "a", "c"}))
+	})
+
+	It("should return overlays of the base profile that select other nodes or machine config pool", func() {
+		otherPool := newOverlay("other-pool", 0)
+		otherPool.Spec.MachineConfigPoolSelector = map[string]string{"other": "pool"}
+		otherNodes := newOverlay("other-nodes", 0)
+		otherNodes.Spec.NodeSelector = map[string]string{"other": "nodes"}
+		another := newOverlay("another", 0)
+		another.Spec.Overlay.BaseProfile = "another-base"
+		another.Spec.NodeSelector = map[string]string{"other": "nodes"}
+
+		ignored := GetIgnoredOverlays(base, []PerformanceProfile{
+			*base,
+			newOverlay("a", 0),
+			otherPool,
+			otherNodes,
+			another,
+		})
+		Expect(ignored).To(Equal([]string{"other-nodes", "other-pool"}))
+	})
+
+	It("should return the copy of the base profile without overlays", func() {
+		effective, status, err := GetEffectiveProfile(base, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(effective).To(Equal(base))
+		Expect(effective).ToNot(BeIdenticalTo(base))
+		Expect(status).To(BeNil())
+	})
+
+	It("should merge overlays into the base profile", func() {
+		node := int32(1)
+		overlay := newOverlay("overlay", 0)
+		overlay.Spec.HugePages = &HugePages{
+			Pages: []HugePage{
+				{
+					Size:  "2M",
+					Count: 128,
+					Node:  &node,
+				},
+			},
+			RuntimeAllocation: pointer.BoolPtr(true),
+		}
+		overlay.Spec.AdditionalKernelArgs = []string{"nosmt"}
+		overlay.Spec.Net = &Net{
+			Devices: []Device{
+				{
+					InterfaceName: pointer.StringPtr("eth1"),
+				},
+			},
+		}
+		governor := CPUGovernorPowersave
+		overlay.Spec.PowerPolicy = &PowerPolicy{
+			Reserved: &CPUPowerSettings{
+				Governor: &governor,
+			},
+		}
+
+		effective, status, err := GetEffectiveProfile(base, []PerformanceProfile{overlay})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(effective.Name).To(Equal(base.Name))
+		Expect(effective.Spec.Overlay).To(BeNil())
+		Expect(effective.Spec.CPU).To(Equal(base.Spec.CPU))
+		Expect(effective.Spec.HugePages.Pages).To(HaveLen(2))
+		Expect(effective.Spec.HugePages.Pages[1].Count).To(Equal(int32(128)))
+		Expect(*effective.Spec.HugePages.RuntimeAllocation).To(BeTrue())
+		Expect(effective.Spec.AdditionalKernelArgs).To(ContainElement("nosmt"))
+		Expect(effective.Spec.Net.Devices).To(HaveLen(len(base.Spec.Net.Devices) + 1))
+		Expect(effective.Spec.Net.Devices).To(ContainElement(overlay.Spec.Net.Devices[0]))
+		Expect(effective.Spec.PowerPolicy).To(Equal(overlay.Spec.PowerPolicy))
+
+		Expect(status.Applied).To(Equal([]string{"overlay"}))
+		Expect(status.Conflicts).To(BeEmpty())
+
+		// the base profile should not be modified
+		Expect(base.Spec.HugePages.Pages).To(HaveLen(1))
+	})
+
+	It("should apply the overlay with the highest precedence and report conflicts", func() {
+		low := newOverlay("low", 0)
+		low.Spec.HugePages = &HugePages{
+			Pages: []HugePage{
+				{
+					Size:  HugePageSize1G,
+					Count: 8,
+				},
+			},
+		}
+		low.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+
+		high := newOverlay("high", 10)
+		high.Spec.HugePages = &HugePages{
+			Pages: []HugePage{
+				{
+					Size:  HugePageSize1G,
+					Count: 16,
+				},
+			},
+		}
+		high.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+
+		effective, status, err := GetEffectiveProfile(base, GetOverlays(base, []PerformanceProfile{high, low}))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(effective.Spec.HugePages.Pages).To(HaveLen(1))
+		Expect(effective.Spec.HugePages.Pages[0].Count).To(Equal(int32(16)))
+		Expect(*effective.Spec.GloballyDisableIrqLoadBalancing).To(BeTrue())
+
+		Expect(status.Applied).To(Equal([]string{"low", "high"}))
+		// overlays set the same value of the IRQ load balancing, so it is not a conflict
+		Expect(status.Conflicts).To(Equal([]OverlayConflict{
+			{
+				Field:       "spec.hugepages.pages[size=" + string(HugePageSize1G) + "]",
+				Profiles:    []string{"base", "low", "high"},
+				AppliedFrom: "high",
+			},
+		}))
+	})
+
+	It("should reject the merged spec that is not valid", func() {
+		overlay := newOverlay("overlay", 0)
+		overlay.Spec.CPU = &CPU{
+			ReservedCount: pointer.Int32Ptr(2),
+		}
+
+		_, _, err := GetEffectiveProfile(base, []PerformanceProfile{overlay})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("the profile merged with overlays overlay is invalid"))
+	})
+})
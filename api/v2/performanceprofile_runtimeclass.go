@@ -0,0 +1,24 @@
+package v2
+
+import (
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
+)
+
+// DefaultRuntimeClassHandler is the CRI-O runtime handler of the RuntimeClass when the profile does not specify it
+const DefaultRuntimeClassHandler = "high-performance"
+
+// GetRuntimeClassName returns the name of the RuntimeClass created for the profile
+func GetRuntimeClassName(profile *PerformanceProfile) string {
+	if profile.Spec.RuntimeClass != nil && profile.Spec.RuntimeClass.Name != nil {
+		return *profile.Spec.RuntimeClass.Name
+	}
+	return components.GetComponentName(profile.Name, components.ComponentNamePrefix)
+}
+
+// GetRuntimeClassHandler returns the CRI-O runtime handler of the RuntimeClass created for the profile
+func GetRuntimeClassHandler(spec *PerformanceProfileSpec) string {
+	if spec.RuntimeClass != nil && spec.RuntimeClass.Handler != nil {
+		return *spec.RuntimeClass.Handler
+	}
+	return DefaultRuntimeClassHandler
+}
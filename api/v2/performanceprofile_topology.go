@@ -0,0 +1,193 @@
+package v2
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/topology"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog"
+	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+)
+
+// getCPUTopologyWarnings returns warnings about reserved and isolated CPUs that do not fit the CPU topology
+// of nodes selected by the profile, nodes without the CPU topology annotation are not checked, the controller
+// applies the profile merged with overlays, so CPUs of the merged profile are checked
+func (r *PerformanceProfile) getCPUTopologyWarnings() []string {
+	if validatorClient == nil {
+		return nil
+	}
+
+	ppList := &PerformanceProfileList{}
+	if err := validatorClient.List(context.TODO(), ppList); err != nil {
+		klog.Errorf("failed to list performance profiles: %v", err)
+		return nil
+	}
+
+	// the controller reports the invalid merged profile under the Degraded condition
+	profile, err := r.getMergedProfile(ppList.Items)
+	if err != nil {
+		return []string{fmt.Sprintf("performance profile %q: %v", r.Name, err)}
+	}
+
+	if profile == nil || profile.Spec.CPU == nil {
+		return nil
+	}
+
+	nodes, err := profile.getSelectedNodes()
+	if err != nil {
+		klog.Errorf("failed to list nodes of the performance profile %q: %v", r.Name, err)
+		return nil
+	}
+
+	// nodes usually have the same hardware, so the same warnings are reported once for all nodes
+	var messages []string
+	nodeNames := map[string][]string{}
+	for i := range nodes {
+		node := &nodes[i]
+		topologyInfo, err := GetNodeCPUTopology(node)
+		if err != nil {
+			klog.Errorf("failed to parse the CPU topology of the node %q: %v", node.Name, err)
+			continue
+		}
+
+		if topologyInfo == nil {
+			continue
+		}
+
+		reserved, isolated := profile.getNodeCPUs(node)
+		if reserved == nil || isolated == nil {
+			continue
+		}
+
+		for _, message := range profile.checkCPUTopology(*reserved, *isolated, topologyInfo) {
+			if _, ok := nodeNames[message]; !ok {
+				messages = append(messages, message)
+			}
+			nodeNames[message] = append(nodeNames[message], node.Name)
+		}
+	}
+
+	var warnings []string
+	for _, message := range messages {
+		sort.Strings(nodeNames[message])
+		warnings = append(warnings, fmt.Sprintf("performance profile %q: %s on nodes %s", r.Name, message, strings.Join(nodeNames[message], ", ")))
+	}
+	return warnings
+}
+
+// GetNodeCPUTopology returns the CPU topology from the node annotation, the nil value means that
+// the node did not report the CPU topology yet
+func GetNodeCPUTopology(node *corev1.Node) (*topology.Info, error) {
+	data, ok := node.Annotations[CPUTopologyAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	return cpuallocation.ParseTopology(data)
+}
+
+// getNodeCPUs returns reserved and isolated CPUs of the node, taking into account CPU node overrides
+func (r *PerformanceProfile) getNodeCPUs(node *corev1.Node) (*CPUSet, *CPUSet) {
+	for _, override := range r.Spec.CPU.NodeOverrides {
+		if len(override.NodeSelector) == 0 || !labels.SelectorFromSet(override.NodeSelector).Matches(labels.Set(node.Labels)) {
+			continue
+		}
+
+		return r.Spec.CPU.Reserved, override.Isolated
+	}
+
+	return r.Spec.CPU.Reserved, r.Spec.CPU.Isolated
+}
+
+// checkCPUTopology returns problems of reserved and isolated CPUs on the CPU topology
+func (r *PerformanceProfile) checkCPUTopology(reservedList CPUSet, isolatedList CPUSet, topologyInfo *topology.Info) []string {
+	reserved, err := cpuset.Parse(string(reservedList))
+	if err != nil {
+		return nil
+	}
+
+	isolated, err := cpuset.Parse(string(isolatedList))
+	if err != nil {
+		return nil
+	}
+
+	var messages []string
+
+	// siblings of the same physical core share execution resources, so the load on reserved CPUs
+	// adds latency to the isolated sibling
+	sharedCores := cpuset.NewBuilder()
+	for _, node := range topologyInfo.Nodes {
+		for _, core := range node.Cores {
+			siblings := cpuset.NewCPUSet(core.LogicalProcessors...)
+			if !siblings.Intersection(reserved).IsEmpty() && !siblings.Intersection(isolated).IsEmpty() {
+				sharedCores.Add(core.LogicalProcessors...)
+			}
+		}
+	}
+	if shared := sharedCores.Result(); !shared.IsEmpty() {
+		messages = append(messages, fmt.Sprintf("reserved and isolated CPUs split hyperthreading siblings of the same physical cores %s", shared))
+	}
+
+	if r.hasCPUManagerPolicyOption(CPUManagerPolicyOptionFullPCPUsOnly) {
+		if split := getSplitCores(reserved, topologyInfo.Nodes); !split.IsEmpty() {
+			messages = append(messages, fmt.Sprintf("reserved CPUs do not take whole physical cores %s required by the %s CPU manager policy option", split, CPUManagerPolicyOptionFullPCPUsOnly))
+		}
+	}
+
+	online := cpuallocation.TotalCPUSet(topologyInfo.Nodes)
+	if uncovered := online.Difference(reserved.Union(isolated)); !uncovered.IsEmpty() {
+		messages = append(messages, fmt.Sprintf("online CPUs %s are neither reserved nor isolated", uncovered))
+	}
+
+	if missing := reserved.Union(isolated).Difference(online); !missing.IsEmpty() {
+		messages = append(messages, fmt.Sprintf("reserved or isolated CPUs %s are not online", missing))
+	}
+
+	// with the single NUMA node policy, every NUMA node is expected to have housekeeping CPUs
+	if r.Spec.NUMA != nil && r.Spec.NUMA.TopologyPolicy != nil &&
+		*r.Spec.NUMA.TopologyPolicy == kubeletconfigv1beta1.SingleNumaNodeTopologyManagerPolicy {
+		for _, node := range topologyInfo.Nodes {
+			if cpuallocation.TotalCPUSet([]*topology.Node{node}).Intersection(reserved).IsEmpty() {
+				messages = append(messages, fmt.Sprintf("the NUMA node %d does not have reserved CPUs while the topology policy is %s", node.ID, kubeletconfigv1beta1.SingleNumaNodeTopologyManagerPolicy))
+			}
+		}
+	}
+
+	return messages
+}
+
+// hasCPUManagerPolicyOption returns whether or not the profile enables the CPU manager policy option
+func (r *PerformanceProfile) hasCPUManagerPolicyOption(option CPUManagerPolicyOption) bool {
+	if r.Spec.CPU == nil {
+		return false
+	}
+
+	for _, o := range r.Spec.CPU.ManagerPolicyOptions {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// getSplitCores returns CPUs of physical cores that are only partially included into CPUs
+func getSplitCores(cpus cpuset.CPUSet, nodes []*topology.Node) cpuset.CPUSet {
+	split := cpuset.NewBuilder()
+	for _, node := range nodes {
+		for _, core := range node.Cores {
+			siblings := cpuset.NewCPUSet(core.LogicalProcessors...)
+			if common := siblings.Intersection(cpus); !common.IsEmpty() && !common.Equals(siblings) {
+				split.Add(core.LogicalProcessors...)
+			}
+		}
+	}
+	return split.Result()
+}
@@ -0,0 +1,98 @@
+package v2
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultTunedProfileConfigMapKey is the ConfigMap data key of the tuned profile when the reference does not specify it
+	DefaultTunedProfileConfigMapKey = "tuned"
+	// tunedMainSection is the tuned profile section that contains the include option
+	tunedMainSection = "main"
+	// tunedIncludeOption is the option of the main section that includes parent profiles
+	tunedIncludeOption = "include"
+	// tunedSysctlSection is the tuned profile section that contains kernel parameters
+	tunedSysctlSection = "sysctl"
+	// tunedSysfsSection is the tuned profile section that contains sysfs attributes
+	tunedSysfsSection = "sysfs"
+)
+
+// GetTunedProfileConfigMapKey returns the ConfigMap data key of the tuned profile
+func GetTunedProfileConfigMapKey(ref *TunedProfileConfigMapReference) string {
+	if ref.Key != nil {
+		return *ref.Key
+	}
+	return DefaultTunedProfileConfigMapKey
+}
+
+// tunedForbiddenSections contains sections of user tuned profiles that would override the generated tuned
+// profile, the value is the reason why the section is not allowed
+var tunedForbiddenSections = map[string]string{
+	"bootloader": "kernel arguments are configured by the performance profile, use spec.additionalKernelArgs",
+	"variables":  "variables of the parent profile, like isolated_cores, are configured by spec.cpu",
+}
+
+// ValidateTunedProfileData validates the tuned profile has the INI syntax parsed by tuned, every option belongs
+// to a section, sections and options of a section are not duplicated, and the profile does not include other
+// profiles, because the operator includes the parent profile, the profile should not have sections and
+// settings that override the generated tuned profile as well
+func ValidateTunedProfileData(data string) error {
+	sections := map[string]map[string]string{}
+
+	var section string
+	var options map[string]string
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || strings.TrimSpace(line[1:len(line)-1]) == "" {
+				return fmt.Errorf("line %d: malformed section header %q", i+1, line)
+			}
+
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := sections[section]; ok {
+				return fmt.Errorf("line %d: duplicate section %q", i+1, section)
+			}
+
+			if reason, ok := tunedForbiddenSections[section]; ok {
+				return fmt.Errorf("line %d: the section %q is not allowed, %s", i+1, section, reason)
+			}
+
+			options = map[string]string{}
+			sections[section] = options
+			continue
+		}
+
+		separator := strings.Index(line, "=")
+		if separator <= 0 {
+			return fmt.Errorf("line %d: %q should be a section header, an option or a comment", i+1, line)
+		}
+
+		if options == nil {
+			return fmt.Errorf("line %d: the option %q should belong to a section", i+1, line)
+		}
+
+		option := strings.TrimSpace(line[:separator])
+		if _, ok := options[option]; ok {
+			return fmt.Errorf("line %d: duplicate option %q of the section %q", i+1, option, section)
+		}
+		options[option] = strings.TrimSpace(line[separator+1:])
+
+		if section == tunedMainSection && option == tunedIncludeOption {
+			return fmt.Errorf("line %d: the profile should not include other profiles, the operator includes the parent profile", i+1)
+		}
+	}
+
+	if len(sections) == 0 {
+		return fmt.Errorf("the profile does not have any sections")
+	}
+
+	if conflicts := getTuningConflicts(sections[tunedSysctlSection], sections[tunedSysfsSection]); len(conflicts) != 0 {
+		return fmt.Errorf("%s", strings.Join(conflicts, ", "))
+	}
+	return nil
+}
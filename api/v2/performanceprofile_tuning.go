@@ -0,0 +1,110 @@
+package v2
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// tuningOwnedSysctls contains kernel parameters the operator depends on, the value is the reason
+// why the parameter can not be overridden
+var tuningOwnedSysctls = map[string]string{
+	"kernel.sched_rt_runtime_us": "real-time threads on isolated CPUs should not be throttled",
+	"kernel.timer_migration":     "timers should not migrate to isolated CPUs",
+	"kernel.numa_balancing":      "pinned workloads should not have their memory migrated between NUMA nodes",
+	"vm.nr_hugepages":            "huge pages are configured by spec.hugepages",
+	"vm.nr_overcommit_hugepages": "huge pages are configured by spec.hugepages",
+}
+
+// tuningOwnedSysfs contains patterns of sysfs attributes the operator depends on, the value is the reason
+// why attributes matching the pattern can not be overridden, CPUs and NUMA nodes are reachable via /sys/bus
+// links as well, so the links are owned too
+var tuningOwnedSysfs = map[string]string{
+	"/sys/kernel/mm/hugepages/*/*":                 "huge pages are configured by spec.hugepages",
+	"/sys/devices/system/node/node*/hugepages/*/*": "huge pages are configured by spec.hugepages",
+	"/sys/bus/node/devices/node*/hugepages/*/*":    "huge pages are configured by spec.hugepages",
+	"/sys/devices/system/cpu/cpu*/cpuidle/*/*":     "C-states are configured by spec.powerPolicy",
+	"/sys/bus/cpu/devices/cpu*/cpuidle/*/*":        "C-states are configured by spec.powerPolicy",
+}
+
+// GetTuningConflicts returns messages about sysctl and sysfs settings the operator depends on
+// that are overridden by the profile
+func GetTuningConflicts(spec *PerformanceProfileSpec) []string {
+	if spec.Tuning == nil {
+		return nil
+	}
+	return getTuningConflicts(spec.Tuning.Sysctl, spec.Tuning.Sysfs)
+}
+
+// getTuningConflicts returns messages about sysctl and sysfs settings the operator depends on
+// that are overridden by the given options
+func getTuningConflicts(sysctl map[string]string, sysfs map[string]string) []string {
+	var conflicts []string
+	for name := range sysctl {
+		// sysctl accepts both dots and slashes as separators
+		if reason, ok := tuningOwnedSysctls[strings.ReplaceAll(name, "/", ".")]; ok {
+			conflicts = append(conflicts, fmt.Sprintf("the sysctl %q is owned by the operator, %s", name, reason))
+		}
+	}
+
+	var patterns []string
+	for pattern := range tuningOwnedSysfs {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for attribute := range sysfs {
+		for _, pattern := range patterns {
+			if isSysfsPatternsOverlap(pattern, path.Clean(attribute)) {
+				conflicts = append(conflicts, fmt.Sprintf("the sysfs attribute %q is owned by the operator, %s", attribute, tuningOwnedSysfs[pattern]))
+				break
+			}
+		}
+	}
+
+	sort.Strings(conflicts)
+	return conflicts
+}
+
+// isSysfsPatternsOverlap returns whether or not both sysfs patterns can match the same attribute, tuned expands
+// globs of sysfs attributes, so the attribute can be a pattern as well, the check is conservative, so an element
+// with globs overlaps any element that starts with a compatible literal prefix
+func isSysfsPatternsOverlap(pattern1 string, pattern2 string) bool {
+	elements1 := strings.Split(pattern1, "/")
+	elements2 := strings.Split(pattern2, "/")
+
+	// globs do not match the path separator
+	if len(elements1) != len(elements2) {
+		return false
+	}
+
+	for i := range elements1 {
+		if !isSysfsElementsOverlap(elements1[i], elements2[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isSysfsElementsOverlap returns whether or not both elements of sysfs patterns can match the same name
+func isSysfsElementsOverlap(element1 string, element2 string) bool {
+	glob1 := strings.IndexAny(element1, `*?[\`)
+	glob2 := strings.IndexAny(element2, `*?[\`)
+
+	switch {
+	case glob1 == -1 && glob2 == -1:
+		return element1 == element2
+	case glob2 == -1:
+		matched, _ := path.Match(element1, element2)
+		return matched
+	case glob1 == -1:
+		matched, _ := path.Match(element2, element1)
+		return matched
+	}
+
+	// both elements have globs, so only literal prefixes before the first glob are compared
+	prefix1 := element1[:glob1]
+	prefix2 := element2[:glob2]
+	return strings.HasPrefix(prefix1, prefix2) || strings.HasPrefix(prefix2, prefix1)
+}
@@ -18,7 +18,10 @@ package v2
 
 import (
 	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // PerformanceProfilePauseAnnotation allows an admin to suspend the operator's
@@ -26,10 +29,41 @@ import (
 // objects.
 const PerformanceProfilePauseAnnotation = "performance.openshift.io/pause-reconcile"
 
+// CPUTopologyAnnotation is the node annotation that contains the CPU topology of the node in the JSON format
+// of the ghw topology, it is set by the operator from the report of the discovery pod and used to compute reserved and
+// isolated CPUs from ReservedCount.
+const CPUTopologyAnnotation = "performance.openshift.io/cpu-topology"
+
+// HugePagesAllocationAnnotation is the node annotation that contains huge pages allocated on NUMA nodes in the JSON format,
+// it is reported by the hugepages-report service of the generated machine config and used to check huge pages allocated at runtime.
+const HugePagesAllocationAnnotation = "performance.openshift.io/hugepages-allocation"
+
+// ArchitectureAnnotation is the profile annotation that contains the CPU architecture of nodes selected by the profile,
+// the operator sets it from the architecture label of nodes, it can be set to render components for nodes that do not run yet.
+const ArchitectureAnnotation = "performance.openshift.io/architecture"
+
+// KernelPageSizeAnnotation is the profile annotation that contains the kernel page size of nodes selected by the profile,
+// the operator sets it from the kernel version of arm64 nodes, because huge page sizes of arm64 depend on the page size.
+const KernelPageSizeAnnotation = "performance.openshift.io/kernel-page-size"
+
+// ReferencedConfigMapsNamespace is the default namespace of ConfigMaps referenced by performance profiles, the namespace
+// the operator is deployed to, ConfigMaps should belong to the namespace of the operator set by SetOperatorNamespace,
+// so profile authors can not make the operator read ConfigMaps of other namespaces.
+const ReferencedConfigMapsNamespace = "openshift-performance-addon-operator"
+
+// HardwareSnapshotKey is the key of the ConfigMap binary data that contains the ghw snapshot referenced by HardwareRef.
+const HardwareSnapshotKey = "sysinfo.tgz"
+
+// PerformanceProfileApproveRolloutAnnotation allows an admin to approve the rollout of pending changes,
+// the annotation value should be equal to the ID of the pending change reported under the status, the operator
+// removes the annotation once the change is applied.
+const PerformanceProfileApproveRolloutAnnotation = "performance.openshift.io/approve-rollout"
+
 // PerformanceProfileSpec defines the desired state of PerformanceProfile.
 type PerformanceProfileSpec struct {
 	// CPU defines a set of CPU related parameters.
-	CPU *CPU `json:"cpu"`
+	// The CPU section is required for all profiles except overlays.
+	CPU *CPU `json:"cpu,omitempty"`
 	// HugePages defines a set of huge pages related parameters.
 	// It is possible to set huge pages with multiple size values at the same time.
 	// For example, hugepages can be set with 1G and 2M, both values will be set on the node by the performance-addon-operator.
@@ -58,6 +92,19 @@ type PerformanceProfileSpec struct {
 	// NUMA defines options related to topology aware affinities
 	// +optional
 	NUMA *NUMA `json:"numa,omitempty"`
+	// Kubelet defines resources reserved by the kubelet, eviction thresholds and the memory manager policy.
+	// When not specified, the kubelet reserves 1000m of CPU and 500Mi of memory for kubernetes and system
+	// daemons each, and the memory manager does not pin the memory of guaranteed pods.
+	// +optional
+	Kubelet *Kubelet `json:"kubelet,omitempty"`
+	// KubeletConfigOverrides contains KubeletConfiguration fields merged into the kubelet config generated
+	// by the operator, for example {"maxPods": 500}, because the machine config pool can have only one KubeletConfig.
+	// Objects are merged recursively, other values replace generated values. Fields generated from the profile,
+	// like cpuManagerPolicy, reservedSystemCPUs and topologyManagerPolicy, and featureGates can not be overridden,
+	// field names are matched case-insensitively like the kubelet does.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	KubeletConfigOverrides *runtime.RawExtension `json:"kubeletConfigOverrides,omitempty"`
 	// Net defines a set of network related features
 	// +optional
 	Net *Net `json:"net,omitempty"`
@@ -68,6 +115,73 @@ type PerformanceProfileSpec struct {
 	// Defaults to "false"
 	// +optional
 	GloballyDisableIrqLoadBalancing *bool `json:"globallyDisableIrqLoadBalancing,omitempty"`
+	// PowerPolicy defines the power consumption mode of nodes and the CPU frequency governor and C-states
+	// of reserved and isolated CPUs, so reserved CPUs can save power while isolated CPUs stay in low C-states.
+	// When not specified, all CPUs use the performance governor and are limited to the C1 state.
+	// +optional
+	PowerPolicy *PowerPolicy `json:"powerPolicy,omitempty"`
+	// RuntimeClass defines the RuntimeClass created for the profile, pods with the RuntimeClass run with
+	// the high-performance CRI-O runtime handler only on nodes selected by the profile.
+	// When not specified, the RuntimeClass "performance-<profile name>" uses the "high-performance" handler.
+	// +optional
+	RuntimeClass *RuntimeClass `json:"runtimeClass,omitempty"`
+	// Stalld defines the configuration of the stalld daemon that boosts threads starving on isolated CPUs.
+	// When not specified, stalld runs with its default configuration.
+	// +optional
+	Stalld *Stalld `json:"stalld,omitempty"`
+	// KernelThreads defines the scheduling policy, priority and CPU affinity of kernel threads and
+	// the offload of RCU callbacks from isolated CPUs.
+	// When not specified, ksoftirqd and rcuc threads run with the FIFO policy and the priority 11 and
+	// RCU callbacks are offloaded from isolated CPUs.
+	// +optional
+	KernelThreads *KernelThreads `json:"kernelThreads,omitempty"`
+	// Tuning defines sysctl and sysfs settings that override settings of the generated tuned profile.
+	// Settings the operator depends on, for example "kernel.sched_rt_runtime_us", can not be overridden.
+	// +optional
+	Tuning *Tuning `json:"tuning,omitempty"`
+	// TunedProfiles contains user tuned profiles packaged into the generated Tuned as child profiles
+	// of the generated tuned profile. Each profile includes the previous one, the first profile includes
+	// the generated tuned profile, and nodes are recommended the last profile with the priority of the generated one.
+	// +optional
+	TunedProfiles []TunedProfileFragment `json:"tunedProfiles,omitempty"`
+	// Rollout defines when changes of the components generated by the operator are applied.
+	// When not specified, changes are applied immediately.
+	// +optional
+	Rollout *Rollout `json:"rollout,omitempty"`
+	// Overlay marks the profile as an overlay of another performance profile.
+	// The overlay does not generate components by itself, its spec is merged into the spec of the base profile,
+	// so the base profile generates a single set of components with the effective spec.
+	// +optional
+	Overlay *Overlay `json:"overlay,omitempty"`
+	// HardwareRef references the ConfigMap with the hardware snapshot of nodes selected by the profile.
+	// When specified, the profile is validated against the hardware snapshot, CPUs should exist on the hardware,
+	// huge pages should fit into the memory of NUMA nodes and network devices should be present.
+	// +optional
+	HardwareRef *HardwareReference `json:"hardwareRef,omitempty"`
+}
+
+// HardwareReference references the ConfigMap with the ghw snapshot of the node hardware,
+// the snapshot is the sysinfo.tgz archive gathered by the must-gather, stored under the HardwareSnapshotKey of
+// the ConfigMap binary data.
+type HardwareReference struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Namespace of the ConfigMap, it should be the namespace of the operator, openshift-performance-addon-operator by default.
+	Namespace string `json:"namespace"`
+}
+
+// Overlay defines the base profile the overlay profile is merged into.
+type Overlay struct {
+	// BaseProfile is the name of the performance profile the overlay is merged into.
+	// The overlay must have the same node selector and machine config pool selector as the base profile,
+	// it can not target a subset of nodes of the base profile, overlays with other selectors are not merged.
+	BaseProfile string `json:"baseProfile"`
+	// Precedence defines the order of merging overlays into the base profile.
+	// Fields of an overlay with a higher precedence override fields of overlays with a lower precedence
+	// and fields of the base profile. Overlays with the same precedence are merged in the order of their names.
+	// Defaults to 0.
+	// +optional
+	Precedence int32 `json:"precedence,omitempty"`
 }
 
 // CPUSet defines the set of CPUs(0-3,8-11).
@@ -83,7 +197,9 @@ type CPU struct {
 	// except the reserved CPUs. In order to guarantee that your workload will run on the isolated CPU:
 	//   1. The union of reserved CPUs and isolated CPUs should include all online CPUs
 	//   2. The isolated CPUs field should be the complementary to reserved CPUs field
-	Isolated *CPUSet `json:"isolated"`
+	// Isolated CPUs are required unless ReservedCount is specified.
+	// +optional
+	Isolated *CPUSet `json:"isolated,omitempty"`
 	// BalanceIsolated toggles whether or not the Isolated CPU set is eligible for load balancing work loads.
 	// When this option is set to "false", the Isolated CPU set will be static, meaning workloads have to
 	// explicitly assign each thread to a specific cpu in order to work across multiple CPUs.
@@ -93,9 +209,84 @@ type CPU struct {
 	// Defaults to "true"
 	// +optional
 	BalanceIsolated *bool `json:"balanceIsolated,omitempty"`
+	// NodeOverrides defines isolated CPUs for nodes with a different CPU layout, for example when the machine
+	// config pool has nodes with a different number of cores. The kernel arguments and the kubelet config are
+	// shared by all nodes of the pool, so overrides keep the reserved CPUs of the profile and their isolated CPUs
+	// should be a subset of the isolated CPUs of the profile.
+	// Nodes that do not match any override use the isolated CPUs of the profile.
+	// +optional
+	NodeOverrides []CPUNodeOverride `json:"nodeOverrides,omitempty"`
+	// ReservedCount defines the number of reserved CPUs, the operator computes reserved and isolated CPUs
+	// from the CPU topology of nodes selected by the profile and reports them under the status.
+	// Can not be specified together with reserved or isolated CPUs.
+	// +optional
+	ReservedCount *int32 `json:"reservedCount,omitempty"`
+	// PlacementPolicy defines how the operator places reserved CPUs computed from ReservedCount.
+	// Defaults to "Sequential"
+	// +optional
+	PlacementPolicy *CPUPlacementPolicy `json:"placementPolicy,omitempty"`
+	// Vendor defines the CPU vendor of nodes, it selects vendor specific kernel arguments.
+	// When not specified, the vendor is selected by the feature.node.kubernetes.io/cpu-model.vendor_id
+	// label under the node selector and the kubernetes.io/arch label of nodes, and defaults to "Intel".
+	// +optional
+	Vendor *CPUVendor `json:"vendor,omitempty"`
+	// ManagerPolicyOptions defines options of the static CPU manager policy, the options require
+	// the CPUManagerPolicyOptions feature gate enabled by the cluster FeatureGate resource.
+	// +optional
+	ManagerPolicyOptions []CPUManagerPolicyOption `json:"managerPolicyOptions,omitempty"`
+}
+
+// CPUManagerPolicyOption defines an option of the static CPU manager policy.
+// +kubebuilder:validation:Enum=full-pcpus-only
+type CPUManagerPolicyOption string
+
+const (
+	// CPUManagerPolicyOptionFullPCPUsOnly allocates only whole physical cores to containers,
+	// so containers do not share cores with other containers
+	CPUManagerPolicyOptionFullPCPUsOnly CPUManagerPolicyOption = "full-pcpus-only"
+)
+
+// CPUPlacementPolicy defines how reserved CPUs are placed on the node topology.
+// +kubebuilder:validation:Enum=Sequential;SplitAcrossNUMA;AvoidHTSiblings
+type CPUPlacementPolicy string
+
+const (
+	// CPUPlacementPolicySequential reserves CPUs in the order of NUMA nodes and cores.
+	CPUPlacementPolicySequential CPUPlacementPolicy = "Sequential"
+	// CPUPlacementPolicySplitAcrossNUMA reserves the same number of CPUs on every NUMA node.
+	CPUPlacementPolicySplitAcrossNUMA CPUPlacementPolicy = "SplitAcrossNUMA"
+	// CPUPlacementPolicyAvoidHTSiblings reserves whole cores, so isolated CPUs never share a core with
+	// reserved CPUs, the number of reserved CPUs is rounded up to whole cores.
+	CPUPlacementPolicyAvoidHTSiblings CPUPlacementPolicy = "AvoidHTSiblings"
+)
+
+// CPUVendor defines the CPU vendor of nodes.
+// +kubebuilder:validation:Enum=Intel;AMD;ARM
+type CPUVendor string
+
+const (
+	// CPUVendorIntel is the vendor of Intel x86 CPUs.
+	CPUVendorIntel CPUVendor = "Intel"
+	// CPUVendorAMD is the vendor of AMD x86 CPUs.
+	CPUVendorAMD CPUVendor = "AMD"
+	// CPUVendorARM is the vendor of arm64 CPUs.
+	CPUVendorARM CPUVendor = "ARM"
+)
+
+// CPUNodeOverride defines isolated CPUs for nodes of the profile matching the node selector.
+type CPUNodeOverride struct {
+	// Name of the override, used for names of the generated node specific tuned profiles.
+	Name string `json:"name"`
+	// NodeSelector defines labels of nodes that use the override CPUs, in addition to the node selector of the profile.
+	// The override with the first matching node selector is applied.
+	NodeSelector map[string]string `json:"nodeSelector"`
+	// Isolated defines a set of CPUs that will be used to give to application threads the most execution time possible.
+	// It should be a subset of the isolated CPUs of the profile.
+	Isolated *CPUSet `json:"isolated"`
 }
 
-// HugePageSize defines size of huge pages, can be 2M or 1G.
+// HugePageSize defines size of huge pages, can be 2M or 1G on amd64 nodes, supported sizes of other architectures
+// are selected by the kubernetes.io/arch label of nodes and by the kernel page size of arm64 nodes.
 type HugePageSize string
 
 // HugePages defines a set of huge pages that we want to allocate at boot.
@@ -104,6 +295,11 @@ type HugePages struct {
 	DefaultHugePagesSize *HugePageSize `json:"defaultHugepagesSize,omitempty"`
 	// Pages defines huge pages that we want to allocate at boot time.
 	Pages []HugePage `json:"pages,omitempty"`
+	// RuntimeAllocation defines whether huge pages allocated on specific NUMA nodes with a size other than
+	// the default huge pages size are allocated at runtime by tuned, so changes of their count do not reboot nodes.
+	// Huge pages are allocated on boot again when nodes fail to allocate them at runtime.
+	// +optional
+	RuntimeAllocation *bool `json:"runtimeAllocation,omitempty"`
 }
 
 // HugePage defines the number of allocated huge pages of the specific size.
@@ -124,6 +320,78 @@ type NUMA struct {
 	// Operator defaults to "best-effort"
 	// +optional
 	TopologyPolicy *string `json:"topologyPolicy,omitempty"`
+	// TopologyManagerScope defines whether the topology manager aligns resources of each container separately
+	// or of all containers of the pod together.
+	// Defaults to "container"
+	// +optional
+	TopologyManagerScope *TopologyManagerScope `json:"topologyManagerScope,omitempty"`
+}
+
+// TopologyManagerScope defines the scope of the topology manager alignment.
+// +kubebuilder:validation:Enum=container;pod
+type TopologyManagerScope string
+
+const (
+	// TopologyManagerScopeContainer aligns resources of each container separately
+	TopologyManagerScopeContainer TopologyManagerScope = "container"
+	// TopologyManagerScopePod aligns resources of all containers of the pod to the same NUMA nodes
+	TopologyManagerScopePod TopologyManagerScope = "pod"
+)
+
+// Kubelet defines the resource reservation and the memory manager of the kubelet.
+type Kubelet struct {
+	// KubeReserved defines resources reserved for kubernetes daemons.
+	// Defaults to 1000m of CPU and 500Mi of memory
+	// +optional
+	KubeReserved corev1.ResourceList `json:"kubeReserved,omitempty"`
+	// SystemReserved defines resources reserved for system daemons.
+	// Defaults to 1000m of CPU and 500Mi of memory
+	// +optional
+	SystemReserved corev1.ResourceList `json:"systemReserved,omitempty"`
+	// EvictionHard defines hard eviction thresholds, for example {"memory.available": "100Mi"}.
+	// When not specified, the kubelet uses its default thresholds.
+	// +optional
+	EvictionHard map[string]string `json:"evictionHard,omitempty"`
+	// CPUManagerReconcilePeriod defines the reconcile period of the CPU manager.
+	// Defaults to "5s"
+	// +optional
+	CPUManagerReconcilePeriod *metav1.Duration `json:"cpuManagerReconcilePeriod,omitempty"`
+	// MemoryManager defines the memory manager policy and the memory reserved on NUMA nodes.
+	// +optional
+	MemoryManager *MemoryManager `json:"memoryManager,omitempty"`
+}
+
+// MemoryManagerPolicy defines the policy of the kubelet memory manager.
+// +kubebuilder:validation:Enum=None;Static
+type MemoryManagerPolicy string
+
+const (
+	// MemoryManagerPolicyNone does not pin the memory of containers
+	MemoryManagerPolicyNone MemoryManagerPolicy = "None"
+	// MemoryManagerPolicyStatic pins the memory of containers of guaranteed pods to NUMA nodes
+	MemoryManagerPolicyStatic MemoryManagerPolicy = "Static"
+)
+
+// MemoryManager defines the policy of the kubelet memory manager.
+type MemoryManager struct {
+	// Policy defines the memory manager policy.
+	// Defaults to "None"
+	// +optional
+	Policy *MemoryManagerPolicy `json:"policy,omitempty"`
+	// ReservedMemory defines the memory reserved on each NUMA node with the Static policy, the reserved memory
+	// should be equal to the memory reserved for kubernetes and system daemons plus the memory.available hard
+	// eviction threshold. When not specified, the memory is split between NUMA nodes of reserved CPUs.
+	// +optional
+	ReservedMemory []NUMAReservedMemory `json:"reservedMemory,omitempty"`
+}
+
+// NUMAReservedMemory defines the memory reserved on the NUMA node.
+type NUMAReservedMemory struct {
+	// NUMANode is the NUMA node ID.
+	// +kubebuilder:validation:Minimum=0
+	NUMANode int32 `json:"numaNode"`
+	// Limits contains the reserved memory and huge pages, for example {"memory": "1100Mi"}.
+	Limits corev1.ResourceList `json:"limits"`
 }
 
 // Net defines a set of network related features
@@ -156,6 +424,302 @@ type RealTimeKernel struct {
 	Enabled *bool `json:"enabled,omitempty"`
 }
 
+// RuntimeClass defines the name, the CRI-O handler and the scheduling of the RuntimeClass created for the profile.
+type RuntimeClass struct {
+	// Name of the RuntimeClass.
+	// Defaults to "performance-<profile name>"
+	// +optional
+	Name *string `json:"name,omitempty"`
+	// Handler defines the CRI-O runtime handler configured by the operator on nodes selected by the profile.
+	// Defaults to "high-performance"
+	// +optional
+	Handler *string `json:"handler,omitempty"`
+	// Overhead defines the fixed resource overhead of pods running with the RuntimeClass.
+	// +optional
+	Overhead corev1.ResourceList `json:"overhead,omitempty"`
+	// Tolerations are added to pods running with the RuntimeClass, so pods tolerate taints of nodes selected by the profile.
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// Stalld defines the configuration of the stalld daemon.
+type Stalld struct {
+	// Enabled defines whether the stalld service runs on nodes.
+	// Defaults to "true"
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// CPUs defines CPUs monitored by stalld.
+	// Defaults to all online CPUs
+	// +optional
+	CPUs *CPUSet `json:"cpus,omitempty"`
+	// BoostPeriod defines the SCHED_DEADLINE period of boosted threads, for example "1s".
+	// +optional
+	BoostPeriod *metav1.Duration `json:"boostPeriod,omitempty"`
+	// BoostRuntime defines the SCHED_DEADLINE runtime of boosted threads within the boost period, for example "20us".
+	// +optional
+	BoostRuntime *metav1.Duration `json:"boostRuntime,omitempty"`
+	// StarvingThreshold defines how long a thread starves before stalld boosts it, in whole seconds, for example "10s".
+	// +optional
+	StarvingThreshold *metav1.Duration `json:"starvingThreshold,omitempty"`
+}
+
+// Tuning defines sysctl and sysfs overrides of the generated tuned profile.
+type Tuning struct {
+	// Sysctl maps names of kernel parameters to their values, for example "kernel.hung_task_timeout_secs": "700".
+	// Parameters set by the generated tuned profile are replaced, other parameters are added.
+	// +optional
+	Sysctl map[string]string `json:"sysctl,omitempty"`
+	// Sysfs maps paths of sysfs attributes to their values, for example "/sys/kernel/mm/ksm/run": "0".
+	// Paths can contain the wildcards supported by tuned, attributes set by the generated tuned profile
+	// under the same path are replaced, other attributes are added.
+	// +optional
+	Sysfs map[string]string `json:"sysfs,omitempty"`
+}
+
+// TunedProfileFragment defines the user tuned profile included into the generated Tuned.
+type TunedProfileFragment struct {
+	// Name of the tuned profile, the child tuned profile is named "<generated tuned profile name>-<name>".
+	Name string `json:"name"`
+	// Data contains the tuned profile in the INI format. The operator adds the include option
+	// of the parent profile to the [main] section, so the profile should not include other profiles.
+	// The [bootloader] and [variables] sections and settings owned by the operator are not allowed.
+	// +optional
+	Data *string `json:"data,omitempty"`
+	// ConfigMapRef references the ConfigMap that contains the tuned profile in the INI format,
+	// changes of the ConfigMap are applied to the generated Tuned.
+	// +optional
+	ConfigMapRef *TunedProfileConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+// TunedProfileConfigMapReference references the ConfigMap key that contains the tuned profile.
+type TunedProfileConfigMapReference struct {
+	// Name of the ConfigMap.
+	Name string `json:"name"`
+	// Namespace of the ConfigMap, only the namespace of the operator is supported.
+	Namespace string `json:"namespace"`
+	// Key of the ConfigMap data that contains the tuned profile.
+	// Defaults to "tuned".
+	// +optional
+	Key *string `json:"key,omitempty"`
+}
+
+// KernelThreads defines the scheduling of kernel threads and the offload of RCU callbacks.
+type KernelThreads struct {
+	// Groups defines the scheduling of groups of kernel threads. Groups named ksoftirqd, rcuc, ktimersoftd
+	// and kworker match threads with the same name prefix by default, other groups require the regex.
+	// +optional
+	Groups []KernelThreadsGroup `json:"groups,omitempty"`
+	// RCUOffload defines the offload of RCU callbacks from isolated CPUs to kernel threads.
+	// Defaults to "nocbs"
+	// +optional
+	RCUOffload *RCUOffloadMode `json:"rcuOffload,omitempty"`
+}
+
+// KernelThreadsGroup defines the scheduling of kernel threads matching the regex.
+type KernelThreadsGroup struct {
+	// Name defines the name of the group.
+	Name string `json:"name"`
+	// Regex defines the regular expression matching names of kernel threads of the group.
+	// +optional
+	Regex *string `json:"regex,omitempty"`
+	// Policy defines the scheduling policy of kernel threads.
+	// Defaults to "fifo"
+	// +optional
+	Policy *SchedulingPolicy `json:"policy,omitempty"`
+	// Priority defines the scheduling priority of kernel threads, from 1 to 99 for the fifo and round-robin
+	// policies and 0 for other policies.
+	// Defaults to 11 for the fifo and round-robin policies and to 0 for other policies
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=99
+	// +optional
+	Priority *int32 `json:"priority,omitempty"`
+	// Affinity defines CPUs kernel threads can run on.
+	// Defaults to "all"
+	// +optional
+	Affinity *KernelThreadsAffinity `json:"affinity,omitempty"`
+}
+
+// SchedulingPolicy defines the scheduling policy of kernel threads.
+// +kubebuilder:validation:Enum=fifo;round-robin;other;batch;idle
+type SchedulingPolicy string
+
+const (
+	// SchedulingPolicyFIFO is the SCHED_FIFO real-time policy
+	SchedulingPolicyFIFO SchedulingPolicy = "fifo"
+	// SchedulingPolicyRoundRobin is the SCHED_RR real-time policy
+	SchedulingPolicyRoundRobin SchedulingPolicy = "round-robin"
+	// SchedulingPolicyOther is the default SCHED_OTHER policy
+	SchedulingPolicyOther SchedulingPolicy = "other"
+	// SchedulingPolicyBatch is the SCHED_BATCH policy
+	SchedulingPolicyBatch SchedulingPolicy = "batch"
+	// SchedulingPolicyIdle is the SCHED_IDLE policy
+	SchedulingPolicyIdle SchedulingPolicy = "idle"
+)
+
+// KernelThreadsAffinity defines CPUs kernel threads can run on.
+// +kubebuilder:validation:Enum=all;reserved;isolated
+type KernelThreadsAffinity string
+
+const (
+	// KernelThreadsAffinityAll keeps the CPU affinity of kernel threads
+	KernelThreadsAffinityAll KernelThreadsAffinity = "all"
+	// KernelThreadsAffinityReserved moves kernel threads to reserved CPUs
+	KernelThreadsAffinityReserved KernelThreadsAffinity = "reserved"
+	// KernelThreadsAffinityIsolated moves kernel threads to isolated CPUs
+	KernelThreadsAffinityIsolated KernelThreadsAffinity = "isolated"
+)
+
+// RCUOffloadMode defines the offload of RCU callbacks from isolated CPUs.
+// +kubebuilder:validation:Enum=none;nocbs;nocbs-poll
+type RCUOffloadMode string
+
+const (
+	// RCUOffloadNone keeps RCU callbacks on isolated CPUs
+	RCUOffloadNone RCUOffloadMode = "none"
+	// RCUOffloadNoCBs offloads RCU callbacks of isolated CPUs to rcuo kernel threads by the rcu_nocbs kernel argument
+	RCUOffloadNoCBs RCUOffloadMode = "nocbs"
+	// RCUOffloadNoCBsPoll additionally makes rcuo kernel threads poll for callbacks by the rcu_nocb_poll kernel argument,
+	// so isolated CPUs do not wake them up
+	RCUOffloadNoCBsPoll RCUOffloadMode = "nocbs-poll"
+)
+
+// PowerMode defines the power consumption mode of nodes.
+// +kubebuilder:validation:Enum=default;low-latency;ultra-low-latency
+type PowerMode string
+
+const (
+	// PowerModeDefault does not add power related kernel arguments
+	PowerModeDefault PowerMode = "default"
+	// PowerModeLowLatency disables the watchdog, the audit and the machine check exceptions
+	PowerModeLowLatency PowerMode = "low-latency"
+	// PowerModeUltraLowLatency additionally keeps all CPUs in the C0 state by the idle polling
+	PowerModeUltraLowLatency PowerMode = "ultra-low-latency"
+)
+
+// CPUGovernor defines the CPU frequency scaling governor.
+// +kubebuilder:validation:Enum=performance;powersave;schedutil;ondemand;conservative
+type CPUGovernor string
+
+const (
+	// CPUGovernorPerformance runs CPUs at the maximum frequency
+	CPUGovernorPerformance CPUGovernor = "performance"
+	// CPUGovernorPowersave runs CPUs at the minimum frequency
+	CPUGovernorPowersave CPUGovernor = "powersave"
+	// CPUGovernorSchedutil scales the frequency by the scheduler utilization of CPUs
+	CPUGovernorSchedutil CPUGovernor = "schedutil"
+	// CPUGovernorOndemand scales the frequency by the load of CPUs
+	CPUGovernorOndemand CPUGovernor = "ondemand"
+	// CPUGovernorConservative scales the frequency by the load of CPUs in small steps
+	CPUGovernorConservative CPUGovernor = "conservative"
+)
+
+// PowerPolicy defines the power management of nodes.
+type PowerPolicy struct {
+	// Mode defines the power consumption mode, low latency modes add kernel arguments that disable
+	// power saving features on all CPUs of nodes.
+	// Defaults to "default"
+	// +optional
+	Mode *PowerMode `json:"mode,omitempty"`
+	// Reserved defines the power settings of reserved CPUs.
+	// +optional
+	Reserved *CPUPowerSettings `json:"reserved,omitempty"`
+	// Isolated defines the power settings of isolated CPUs.
+	// +optional
+	Isolated *CPUPowerSettings `json:"isolated,omitempty"`
+}
+
+// CPUPowerSettings defines the frequency governor and the deepest C-state of a set of CPUs.
+type CPUPowerSettings struct {
+	// Governor defines the CPU frequency scaling governor.
+	// Defaults to "performance"
+	// +optional
+	Governor *CPUGovernor `json:"governor,omitempty"`
+	// MaxCState defines the index of the deepest cpuidle state CPUs can enter, 0 keeps CPUs in the polling or C0 state.
+	// The index matches the C-state with the acpi_idle driver, the intel_idle driver lists additional states like C1E,
+	// so deeper C-states have higher indexes.
+	// When not specified, reserved CPUs can enter any state and isolated CPUs are limited to the state 1, the C1 state.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=8
+	// +optional
+	MaxCState *int32 `json:"maxCState,omitempty"`
+}
+
+// RolloutStrategy defines the strategy of applying changes of the generated components.
+type RolloutStrategy string
+
+const (
+	// RolloutStrategyImmediate applies changes immediately
+	RolloutStrategyImmediate RolloutStrategy = "Immediate"
+	// RolloutStrategyMaintenanceWindow holds changes until one of the maintenance windows starts
+	// or until the change is approved
+	RolloutStrategyMaintenanceWindow RolloutStrategy = "MaintenanceWindow"
+	// RolloutStrategyApproval holds changes until the change is approved
+	RolloutStrategyApproval RolloutStrategy = "Approval"
+)
+
+// Rollout defines when changes of the generated components are applied.
+type Rollout struct {
+	// Strategy defines when changes are applied. Held changes are applied once approved with the
+	// performance.openshift.io/approve-rollout annotation set to the ID of the pending change,
+	// the MaintenanceWindow strategy additionally applies them during maintenance windows.
+	// Defaults to "Immediate".
+	// +kubebuilder:validation:Enum=Immediate;MaintenanceWindow;Approval
+	// +optional
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+	// MaintenanceWindows defines time windows when held changes are applied.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+	// HoldAllChanges toggles whether changes that do not require a node reboot are held as well.
+	// Defaults to "false", meaning only changes that reboot nodes are held.
+	// +optional
+	HoldAllChanges *bool `json:"holdAllChanges,omitempty"`
+}
+
+// MaintenanceWindow defines a recurring time window in UTC.
+type MaintenanceWindow struct {
+	// Days defines the days of the week when the window starts, when empty the window starts every day.
+	// +optional
+	Days []Weekday `json:"days,omitempty"`
+	// StartTime defines the start time of the window in the "HH:MM" format (UTC).
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	StartTime string `json:"startTime"`
+	// Duration defines the duration of the window, for example "4h".
+	Duration metav1.Duration `json:"duration"`
+}
+
+// Weekday defines a day of the week.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type Weekday string
+
+// PendingChange describes changes of the generated components held by the rollout policy.
+type PendingChange struct {
+	// ID identifies the pending change, set it as the value of the performance.openshift.io/approve-rollout
+	// annotation to approve the change.
+	ID string `json:"id"`
+	// Impact describes the impact of the pending change on nodes.
+	Impact ChangeImpact `json:"impact"`
+	// Changes contains the changed fields of the generated components.
+	// +optional
+	Changes []ComponentChanges `json:"changes,omitempty"`
+	// Reason describes why the change is pending.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+	// NextMaintenanceWindow is the start time of the next maintenance window.
+	// +optional
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+}
+
+// ComponentChanges contains the changed fields of a generated component.
+type ComponentChanges struct {
+	// Kind is the kind of the component.
+	Kind string `json:"kind"`
+	// Name is the name of the component.
+	Name string `json:"name"`
+	// Fields contains the paths of the changed fields.
+	// +optional
+	Fields []string `json:"fields,omitempty"`
+}
+
 // PerformanceProfileStatus defines the observed state of PerformanceProfile.
 type PerformanceProfileStatus struct {
 	// Conditions represents the latest available observations of current state.
@@ -166,6 +730,256 @@ type PerformanceProfileStatus struct {
 	Tuned *string `json:"tuned,omitempty"`
 	// RuntimeClass contains the name of the RuntimeClass resource created by the operator.
 	RuntimeClass *string `json:"runtimeClass,omitempty"`
+	// LastChangeImpact describes the impact of the last applied change of the components on nodes.
+	// +optional
+	LastChangeImpact *ChangeImpact `json:"lastChangeImpact,omitempty"`
+	// PendingChange describes changes of the generated components held by the rollout policy.
+	// +optional
+	PendingChange *PendingChange `json:"pendingChange,omitempty"`
+	// ObservedGeneration is the most recent generation of the profile processed by the operator.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Components contains the sync state of the components generated by the operator.
+	// +optional
+	Components []ComponentStatus `json:"components,omitempty"`
+	// Nodes describes the rollout state of nodes selected by the profile node selector.
+	// +optional
+	Nodes *NodesStatus `json:"nodes,omitempty"`
+	// Overlays describes overlay profiles merged into the profile.
+	// +optional
+	Overlays *OverlaysStatus `json:"overlays,omitempty"`
+	// CPU contains reserved and isolated CPUs computed by the operator from the node topology,
+	// when the profile specifies the number of reserved CPUs.
+	// +optional
+	CPU *CPUStatus `json:"cpu,omitempty"`
+	// HugePages describes the memory taken by huge pages of the profile on each node.
+	// +optional
+	HugePages *HugePagesStatus `json:"hugepages,omitempty"`
+	// Tuning contains sysctl and sysfs settings of the generated tuned profile with overrides of the profile and
+	// user tuned profiles applied, it is reported only when the profile specifies tuning overrides or user tuned profiles.
+	// +optional
+	Tuning *TuningStatus `json:"tuning,omitempty"`
+}
+
+// TuningStatus contains effective sysctl and sysfs settings of the generated tuned profile.
+type TuningStatus struct {
+	// Sysctl maps names of kernel parameters to their effective values.
+	// +optional
+	Sysctl map[string]string `json:"sysctl,omitempty"`
+	// Sysfs maps paths of sysfs attributes to their effective values.
+	// +optional
+	Sysfs map[string]string `json:"sysfs,omitempty"`
+}
+
+// HugePagesStatus describes the memory taken by huge pages of the profile on each node.
+type HugePagesStatus struct {
+	// Total is the memory taken by all huge pages.
+	Total resource.Quantity `json:"total"`
+	// Distributed is the memory taken by huge pages without the NUMA node,
+	// the memory is allocated equally between NUMA nodes.
+	// +optional
+	Distributed *resource.Quantity `json:"distributed,omitempty"`
+	// NUMANodes contains the memory taken by huge pages allocated on specific NUMA nodes.
+	// +optional
+	NUMANodes []NUMAHugePagesStatus `json:"numaNodes,omitempty"`
+	// RuntimeAllocation describes huge pages allocated at runtime on each node.
+	// +optional
+	RuntimeAllocation *HugePagesRuntimeAllocationStatus `json:"runtimeAllocation,omitempty"`
+}
+
+// HugePagesRuntimeAllocationStatus describes huge pages allocated at runtime.
+type HugePagesRuntimeAllocationStatus struct {
+	// Pages contains huge pages allocated at runtime.
+	Pages []HugePage `json:"pages,omitempty"`
+	// LastUpdateTime is the time when huge pages allocated at runtime were changed.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// FallbackToBoot is set when some node failed to allocate huge pages at runtime,
+	// the huge pages are allocated on boot until they are changed.
+	// +optional
+	FallbackToBoot bool `json:"fallbackToBoot,omitempty"`
+	// Nodes contains the allocation state of each node.
+	// +optional
+	Nodes []NodeHugePagesAllocation `json:"nodes,omitempty"`
+}
+
+// HugePagesAllocationState describes the state of huge pages allocated at runtime on the node.
+type HugePagesAllocationState string
+
+const (
+	// HugePagesAllocationStatePending means that the node did not report huge pages allocated
+	// with the latest tuned profile yet.
+	HugePagesAllocationStatePending HugePagesAllocationState = "Pending"
+	// HugePagesAllocationStateAllocated means that the node allocated all requested huge pages.
+	HugePagesAllocationStateAllocated HugePagesAllocationState = "Allocated"
+	// HugePagesAllocationStateFailed means that the kernel could not allocate requested huge pages on the node.
+	HugePagesAllocationStateFailed HugePagesAllocationState = "Failed"
+)
+
+// NodeHugePagesAllocation describes huge pages allocated at runtime on the node.
+type NodeHugePagesAllocation struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+	// State is the allocation state of huge pages on the node.
+	State HugePagesAllocationState `json:"state"`
+	// Message describes huge pages that the node failed to allocate.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// NUMAHugePagesStatus describes the memory taken by huge pages allocated on the NUMA node.
+type NUMAHugePagesStatus struct {
+	// Node is the NUMA node ID.
+	Node int32 `json:"node"`
+	// Memory is the memory taken by huge pages allocated on the NUMA node.
+	Memory resource.Quantity `json:"memory"`
+}
+
+// CPUStatus describes reserved and isolated CPUs computed by the operator.
+type CPUStatus struct {
+	// Reserved contains the computed reserved CPUs.
+	Reserved CPUSet `json:"reserved"`
+	// Isolated contains the computed isolated CPUs.
+	Isolated CPUSet `json:"isolated"`
+}
+
+// OverlaysStatus describes overlay profiles merged into the base profile.
+type OverlaysStatus struct {
+	// Applied contains names of overlays merged into the profile in the order of merging.
+	// +optional
+	Applied []string `json:"applied,omitempty"`
+	// Conflicts contains fields set to different values by the base profile and its overlays.
+	// +optional
+	Conflicts []OverlayConflict `json:"conflicts,omitempty"`
+	// Ignored contains names of overlays of the profile that are not merged, because they do not have
+	// the same node selector and machine config pool selector as the profile.
+	// +optional
+	Ignored []string `json:"ignored,omitempty"`
+}
+
+// OverlayConflict describes a field set to different values by the base profile and its overlays.
+type OverlayConflict struct {
+	// Field is the path of the conflicting field.
+	Field string `json:"field"`
+	// Profiles contains names of profiles that set the field.
+	Profiles []string `json:"profiles"`
+	// AppliedFrom is the name of the profile the effective value was taken from.
+	AppliedFrom string `json:"appliedFrom"`
+}
+
+// NodesStatus describes the rollout state of nodes selected by the profile.
+type NodesStatus struct {
+	// Total is the number of nodes selected by the profile.
+	Total int32 `json:"total"`
+	// Updated is the number of nodes that run the configuration generated from the profile.
+	Updated int32 `json:"updated"`
+	// Updating is the number of nodes that are still applying the configuration generated from the profile.
+	Updating int32 `json:"updating"`
+	// Degraded is the number of nodes that failed to apply the configuration generated from the profile.
+	Degraded int32 `json:"degraded"`
+	// Nodes contains the rollout state of each node.
+	// +optional
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+}
+
+// NodeRolloutState defines the rollout state of a node.
+// +kubebuilder:validation:Enum=Updated;Updating;Degraded
+type NodeRolloutState string
+
+const (
+	// NodeRolloutStateUpdated means that the node runs the configuration generated from the profile.
+	NodeRolloutStateUpdated NodeRolloutState = "Updated"
+	// NodeRolloutStateUpdating means that the node is still applying the configuration generated from the profile.
+	NodeRolloutStateUpdating NodeRolloutState = "Updating"
+	// NodeRolloutStateDegraded means that the node failed to apply the configuration generated from the profile.
+	NodeRolloutStateDegraded NodeRolloutState = "Degraded"
+)
+
+// KubeletConfigState defines the state of the kubelet config on a node.
+// +kubebuilder:validation:Enum=Applied;Pending;Failed
+type KubeletConfigState string
+
+const (
+	// KubeletConfigStateApplied means that the node runs the machine config that contains the kubelet config.
+	KubeletConfigStateApplied KubeletConfigState = "Applied"
+	// KubeletConfigStatePending means that the kubelet config is not yet applied on the node.
+	KubeletConfigStatePending KubeletConfigState = "Pending"
+	// KubeletConfigStateFailed means that the kubelet config controller failed to render the kubelet config.
+	KubeletConfigStateFailed KubeletConfigState = "Failed"
+)
+
+// NodeStatus describes the rollout state of a node selected by the profile.
+type NodeStatus struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+	// State is the rollout state of the node.
+	State NodeRolloutState `json:"state"`
+	// CurrentMachineConfig is the name of the rendered machine config that the node runs.
+	// +optional
+	CurrentMachineConfig string `json:"currentMachineConfig,omitempty"`
+	// DesiredMachineConfig is the name of the rendered machine config targeted by the machine config pool.
+	// +optional
+	DesiredMachineConfig string `json:"desiredMachineConfig,omitempty"`
+	// MachineConfigState is the state reported by the machine config daemon of the node.
+	// +optional
+	MachineConfigState string `json:"machineConfigState,omitempty"`
+	// TunedProfileApplied indicates if the tuned profile was applied on the node.
+	TunedProfileApplied bool `json:"tunedProfileApplied"`
+	// TunedProfileDegraded indicates if the tuned daemon reported errors when applying the tuned profile on the node.
+	TunedProfileDegraded bool `json:"tunedProfileDegraded"`
+	// KubeletConfigState is the state of the kubelet config on the node.
+	// +optional
+	KubeletConfigState KubeletConfigState `json:"kubeletConfigState,omitempty"`
+}
+
+// ComponentStatus describes the sync state of a component generated by the operator.
+type ComponentStatus struct {
+	// Kind is the kind of the component.
+	Kind string `json:"kind"`
+	// Name is the name of the component.
+	Name string `json:"name"`
+	// Namespace is the namespace of the component, empty for cluster scoped components.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	// Hash is the hash of the last applied rendered content of the component.
+	// +optional
+	Hash string `json:"hash,omitempty"`
+	// InSync indicates if the component matches the content rendered from the observed generation of the profile.
+	InSync bool `json:"inSync"`
+}
+
+// ChangeImpactType defines the most disruptive impact of a performance profile change on nodes.
+type ChangeImpactType string
+
+const (
+	// ChangeImpactNone means that the change does not affect nodes
+	ChangeImpactNone ChangeImpactType = "None"
+	// ChangeImpactTunedReload means that only the tuned profile changes and it will be reloaded without a reboot
+	ChangeImpactTunedReload ChangeImpactType = "TunedReload"
+	// ChangeImpactTunedReboot means that the tuned bootloader section or variables it uses change and nodes will be rebooted
+	// to apply the new kernel arguments
+	ChangeImpactTunedReboot ChangeImpactType = "TunedReboot"
+	// ChangeImpactKubeletConfigUpdate means that the kubelet config changes, the machine config operator
+	// will render it into the machine config and will reboot every node in the pool
+	ChangeImpactKubeletConfigUpdate ChangeImpactType = "KubeletConfigUpdate"
+	// ChangeImpactMachineConfigUpdate means that the machine config changes and every node in the pool will be rebooted
+	ChangeImpactMachineConfigUpdate ChangeImpactType = "MachineConfigUpdate"
+)
+
+// ChangeImpact describes the impact of a performance profile change on nodes.
+type ChangeImpact struct {
+	// Type is the most disruptive impact of the change.
+	Type ChangeImpactType `json:"type"`
+	// RebootRequired indicates if the change will trigger the reboot of nodes.
+	RebootRequired bool `json:"rebootRequired"`
+	// Components contains the kinds of the changed components.
+	// +optional
+	Components []string `json:"components,omitempty"`
+	// Message is a human readable description of the impact.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// LastTransitionTime is the time when the change was applied.
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -17,26 +17,68 @@ limitations under the License.
 package v2
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"path"
 	"reflect"
 	"regexp"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/klog"
 	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 )
 
 const (
 	hugepagesSize2M = "2M"
 	hugepagesSize1G = "1G"
+
+	maintenanceWindowTimeFormat  = "15:04"
+	maxMaintenanceWindowDuration = 7 * 24 * time.Hour
+
+	// node specific tuned profiles take priorities 10-19, so they precede the profile tuned with the priority 20
+	maxCPUNodeOverrides = 10
+
+	defaultCrioRuntimeHandler = "runc"
+
+	// stalld boosts threads with the 20us runtime within the 1s period by default
+	defaultStalldBoostPeriod  = time.Second
+	defaultStalldBoostRuntime = 20 * time.Microsecond
 )
 
+// kubeletReservedResources contains resources other than memory and huge pages reserved by the kubelet
+var kubeletReservedResources = map[corev1.ResourceName]bool{
+	corev1.ResourceCPU:              true,
+	corev1.ResourceEphemeralStorage: true,
+	"pid":                           true,
+}
+
+// evictionSignals contains eviction signals supported by the kubelet
+var evictionSignals = map[string]bool{
+	EvictionSignalMemoryAvailable: true,
+	"nodefs.available":            true,
+	"nodefs.inodesFree":           true,
+	"imagefs.available":           true,
+	"imagefs.inodesFree":          true,
+	"pid.available":               true,
+}
+
+// sysctlNameRegex matches names of kernel parameters separated by dots or slashes
+var sysctlNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+([./][a-zA-Z0-9_-]+)+$`)
+
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
 func (r *PerformanceProfile) ValidateCreate() error {
 	klog.Infof("Create validation for the performance profile %q", r.Name)
@@ -61,9 +103,30 @@ func (r *PerformanceProfile) validateCreateOrUpdate() error {
 	}
 
 	allErrs = append(allErrs, r.validateNodeSelectorDuplication(ppList)...)
+	allErrs = append(allErrs, r.validateRuntimeClassDuplication(ppList)...)
+
+	// validate the overlay and its base profile
+	allErrs = append(allErrs, r.validateOverlay(ppList)...)
+
+	// huge page sizes and the CPU vendor depend on the architecture of nodes selected by the profile
+	nodes, err := r.getSelectedNodes()
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	profile := r.DeepCopy()
+	if err := SetNodesArchitecture(profile, nodes); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.nodeSelector"), r.Spec.NodeSelector, err.Error()))
+	}
 
 	// validate basic fields
-	allErrs = append(allErrs, r.validateFields()...)
+	allErrs = append(allErrs, profile.validateFields()...)
+
+	// the hardware snapshot and nodes are checked only for profiles without other errors
+	if len(allErrs) == 0 {
+		allErrs = append(allErrs, r.validateHardware()...)
+		allErrs = append(allErrs, r.validateHugePagesCapacity()...)
+	}
 
 	if len(allErrs) == 0 {
 		return nil
@@ -92,6 +155,11 @@ func (r *PerformanceProfile) validateNodeSelectorDuplication(ppList *Performance
 			continue
 		}
 
+		// overlays are merged into the base profile, so they can have the same node selector
+		if r.Spec.Overlay != nil || pp.Spec.Overlay != nil {
+			continue
+		}
+
 		if reflect.DeepEqual(pp.Spec.NodeSelector, r.Spec.NodeSelector) {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.nodeSelector"), r.Spec.NodeSelector, fmt.Sprintf("the profile has the same node selector as the performance profile %q", pp.Name)))
 		}
@@ -100,14 +168,150 @@ func (r *PerformanceProfile) validateNodeSelectorDuplication(ppList *Performance
 	return allErrs
 }
 
+func (r *PerformanceProfile) validateRuntimeClassDuplication(ppList *PerformanceProfileList) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// overlays do not create components
+	if r.Spec.Overlay != nil {
+		return allErrs
+	}
+
+	name := GetRuntimeClassName(r)
+	for _, pp := range ppList.Items {
+		if pp.Name == r.Name || pp.Spec.Overlay != nil {
+			continue
+		}
+
+		if GetRuntimeClassName(&pp) == name {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.runtimeClass.name"), name, fmt.Sprintf("the performance profile %q creates the RuntimeClass with the same name", pp.Name)))
+		}
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateOverlay(ppList *PerformanceProfileList) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.Overlay == nil {
+		for _, pp := range ppList.Items {
+			if pp.Spec.Overlay != nil && pp.Spec.Overlay.BaseProfile == r.Name {
+				allErrs = append(allErrs, r.validateSameSelectors(&pp, fmt.Sprintf("the profile should have the same %%s as its overlay %q", pp.Name))...)
+			}
+		}
+		return allErrs
+	}
+
+	basePath := field.NewPath("spec.overlay.baseProfile")
+	if r.Spec.Overlay.BaseProfile == "" {
+		return append(allErrs, field.Required(basePath, "the base profile name required"))
+	}
+
+	if r.Spec.Overlay.BaseProfile == r.Name {
+		return append(allErrs, field.Invalid(basePath, r.Spec.Overlay.BaseProfile, "the overlay can not be merged into itself"))
+	}
+
+	for _, pp := range ppList.Items {
+		if pp.Name == r.Name {
+			continue
+		}
+
+		if pp.Spec.Overlay != nil && pp.Spec.Overlay.BaseProfile == r.Name {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.overlay"), r.Spec.Overlay, fmt.Sprintf("the profile is the base profile of the overlay %q, so it can not be an overlay", pp.Name)))
+		}
+
+		// the base profile can be created after the overlay
+		if pp.Name != r.Spec.Overlay.BaseProfile {
+			continue
+		}
+
+		if pp.Spec.Overlay != nil {
+			allErrs = append(allErrs, field.Invalid(basePath, r.Spec.Overlay.BaseProfile, "the base profile can not be an overlay"))
+		}
+
+		allErrs = append(allErrs, r.validateSameSelectors(&pp, fmt.Sprintf("the overlay should have the same %%s as the base profile %q", pp.Name))...)
+	}
+
+	return allErrs
+}
+
+// validateSameSelectors rejects the overlay or the base profile that selects other nodes or machine config pool
+// than its counterpart, the message format gets the name of the field
+func (r *PerformanceProfile) validateSameSelectors(other *PerformanceProfile, messageFormat string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if !reflect.DeepEqual(r.Spec.NodeSelector, other.Spec.NodeSelector) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.nodeSelector"), r.Spec.NodeSelector, fmt.Sprintf(messageFormat, "node selector")))
+	}
+
+	if !reflect.DeepEqual(r.Spec.MachineConfigPoolSelector, other.Spec.MachineConfigPoolSelector) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.machineConfigPoolSelector"), r.Spec.MachineConfigPoolSelector, fmt.Sprintf(messageFormat, "machine config pool selector")))
+	}
+
+	return allErrs
+}
+
 func (r *PerformanceProfile) validateFields() field.ErrorList {
 	var allErrs field.ErrorList
 
 	allErrs = append(allErrs, r.validateCPUs()...)
+	allErrs = append(allErrs, r.validateCPUVendor()...)
+	allErrs = append(allErrs, r.validateCPUManagerPolicyOptions()...)
 	allErrs = append(allErrs, r.validateSelectors()...)
 	allErrs = append(allErrs, r.validateHugePages()...)
 	allErrs = append(allErrs, r.validateNUMA()...)
 	allErrs = append(allErrs, r.validateNet()...)
+	allErrs = append(allErrs, r.validateRollout()...)
+	allErrs = append(allErrs, r.validatePowerPolicy()...)
+	allErrs = append(allErrs, r.validateRuntimeClass()...)
+	allErrs = append(allErrs, r.validateStalld()...)
+	allErrs = append(allErrs, r.validateKernelThreads()...)
+	allErrs = append(allErrs, r.validateKubelet()...)
+	allErrs = append(allErrs, r.validateKubeletConfigOverrides()...)
+	allErrs = append(allErrs, r.validateTuning()...)
+	allErrs = append(allErrs, r.validateTunedProfiles()...)
+
+	return allErrs
+}
+
+// validateCPUVendor rejects CPU vendors that do not match the architecture of nodes
+func (r *PerformanceProfile) validateCPUVendor() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.CPU == nil || r.Spec.CPU.Vendor == nil {
+		return allErrs
+	}
+
+	vendor := *r.Spec.CPU.Vendor
+	arch := GetArchitecture(r)
+	if (vendor == CPUVendorARM) != (arch == ArchitectureARM64) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.vendor"), vendor, fmt.Sprintf("the CPU vendor %q does not match the %s architecture of nodes", vendor, arch)))
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateCPUManagerPolicyOptions() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.CPU == nil {
+		return allErrs
+	}
+
+	options := map[CPUManagerPolicyOption]bool{}
+	for i, option := range r.Spec.CPU.ManagerPolicyOptions {
+		if options[option] {
+			allErrs = append(allErrs, field.Duplicate(field.NewPath("spec.cpu.managerPolicyOptions").Index(i), option))
+		}
+		options[option] = true
+	}
+
+	// the sequential placement can split hyperthreading siblings between reserved and isolated CPUs
+	if options[CPUManagerPolicyOptionFullPCPUsOnly] && r.Spec.CPU.ReservedCount != nil &&
+		(r.Spec.CPU.PlacementPolicy == nil || *r.Spec.CPU.PlacementPolicy != CPUPlacementPolicyAvoidHTSiblings) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.placementPolicy"), r.Spec.CPU.PlacementPolicy,
+			fmt.Sprintf("the %s CPU manager policy option requires reserved CPUs of whole physical cores, use the %s placement policy", CPUManagerPolicyOptionFullPCPUsOnly, CPUPlacementPolicyAvoidHTSiblings)))
+	}
 
 	return allErrs
 }
@@ -115,8 +319,15 @@ func (r *PerformanceProfile) validateFields() field.ErrorList {
 func (r *PerformanceProfile) validateCPUs() field.ErrorList {
 	var allErrs field.ErrorList
 
+	// overlays can take the CPU section from the base profile
+	if r.Spec.CPU == nil && r.Spec.Overlay != nil {
+		return allErrs
+	}
+
 	if r.Spec.CPU == nil {
 		allErrs = append(allErrs, field.Required(field.NewPath("spec.cpu"), "cpu section required"))
+	} else if r.Spec.CPU.ReservedCount != nil || r.Spec.CPU.PlacementPolicy != nil {
+		allErrs = append(allErrs, r.validateCPUReservedCount()...)
 	} else {
 		if r.Spec.CPU.Isolated == nil {
 			allErrs = append(allErrs, field.Required(field.NewPath("spec.cpu.isolated"), "isolated CPUs required"))
@@ -142,6 +353,107 @@ func (r *PerformanceProfile) validateCPUs() field.ErrorList {
 				}
 			}
 		}
+
+		allErrs = append(allErrs, r.validateCPUNodeOverrides()...)
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateCPUReservedCount() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.CPU.ReservedCount == nil {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec.cpu.reservedCount"), "the placement policy can be specified only with the reserved CPUs count"))
+		return allErrs
+	}
+
+	if *r.Spec.CPU.ReservedCount <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.reservedCount"), *r.Spec.CPU.ReservedCount, "the reserved CPUs count should be greater than 0"))
+	}
+
+	// CPUs are computed by the operator from the node topology
+	if r.Spec.CPU.Reserved != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.reserved"), r.Spec.CPU.Reserved, "reserved CPUs can not be specified together with the reserved CPUs count"))
+	}
+
+	if r.Spec.CPU.Isolated != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.isolated"), r.Spec.CPU.Isolated, "isolated CPUs can not be specified together with the reserved CPUs count"))
+	}
+
+	if len(r.Spec.CPU.NodeOverrides) != 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.cpu.nodeOverrides"), r.Spec.CPU.NodeOverrides, "node overrides can not be specified together with the reserved CPUs count"))
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateCPUNodeOverrides() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(r.Spec.CPU.NodeOverrides) > maxCPUNodeOverrides {
+		allErrs = append(allErrs, field.TooMany(field.NewPath("spec.cpu.nodeOverrides"), len(r.Spec.CPU.NodeOverrides), maxCPUNodeOverrides))
+	}
+
+	// the kernel arguments, the kubelet config, CRI-O and RPS settings are shared by all nodes of the pool,
+	// so overrides can only isolate a subset of isolated CPUs of the profile on nodes with less CPUs
+	var profileIsolated *cpuset.CPUSet
+	if r.Spec.CPU.Isolated != nil {
+		isolated, err := cpuset.Parse(string(*r.Spec.CPU.Isolated))
+		if err == nil {
+			profileIsolated = &isolated
+		}
+	}
+
+	names := map[string]bool{}
+	var nodeSelectors []map[string]string
+	for i, override := range r.Spec.CPU.NodeOverrides {
+		path := field.NewPath("spec.cpu.nodeOverrides").Index(i)
+
+		if override.Name == "" {
+			allErrs = append(allErrs, field.Required(path.Child("name"), "the override name required"))
+		} else if names[override.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), override.Name))
+		}
+		names[override.Name] = true
+
+		if len(override.NodeSelector) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("nodeSelector"), "the override nodeSelector required"))
+		}
+
+		for _, nodeSelector := range nodeSelectors {
+			if reflect.DeepEqual(nodeSelector, override.NodeSelector) {
+				allErrs = append(allErrs, field.Duplicate(path.Child("nodeSelector"), override.NodeSelector))
+				break
+			}
+		}
+		nodeSelectors = append(nodeSelectors, override.NodeSelector)
+
+		if override.Isolated == nil {
+			allErrs = append(allErrs, field.Required(path.Child("isolated"), "isolated CPUs required"))
+			continue
+		}
+
+		overrideIsolated, err := cpuset.Parse(string(*override.Isolated))
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("isolated"), override.Isolated, err.Error()))
+			continue
+		}
+
+		if overrideIsolated.IsEmpty() {
+			allErrs = append(allErrs, field.Invalid(path.Child("isolated"), override.Isolated, "isolated CPUs can not be empty"))
+			continue
+		}
+
+		// overlays can take isolated CPUs from the base profile
+		if profileIsolated == nil {
+			continue
+		}
+
+		if extra := overrideIsolated.Difference(*profileIsolated); !extra.IsEmpty() {
+			allErrs = append(allErrs, field.Invalid(path.Child("isolated"), override.Isolated,
+				fmt.Sprintf("isolated CPUs should be a subset of the isolated CPUs of the profile, CPUs %s are not isolated by the profile", extra)))
+		}
 	}
 
 	return allErrs
@@ -188,23 +500,27 @@ func (r *PerformanceProfile) validateHugePages() field.ErrorList {
 		return allErrs
 	}
 
-	// validate that default hugepages size has correct value, currently we support only 2M and 1G(x86_64 architecture)
+	hugePagesArch, err := GetHugePagesArchitecture(r)
+	if err != nil {
+		return append(allErrs, field.Invalid(field.NewPath("spec.nodeSelector"), r.Spec.NodeSelector, err.Error()))
+	}
+
+	// validate that default hugepages size has correct value for the architecture of nodes
 	if r.Spec.HugePages.DefaultHugePagesSize != nil {
-		defaultSize := *r.Spec.HugePages.DefaultHugePagesSize
-		if defaultSize != hugepagesSize1G && defaultSize != hugepagesSize2M {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.defaultHugepagesSize"), r.Spec.HugePages.DefaultHugePagesSize, fmt.Sprintf("hugepages default size should be equal to %q or %q", hugepagesSize1G, hugepagesSize2M)))
+		if !hugePagesArch.IsSupportedSize(*r.Spec.HugePages.DefaultHugePagesSize) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.defaultHugepagesSize"), r.Spec.HugePages.DefaultHugePagesSize, fmt.Sprintf("hugepages default size should be equal to %s on the %s", formatHugePageSizes(hugePagesArch.Sizes), hugePagesArch)))
 		}
 	}
 
 	for i, page := range r.Spec.HugePages.Pages {
-		if page.Size != hugepagesSize1G && page.Size != hugepagesSize2M {
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages, fmt.Sprintf("the page size should be equal to %q or %q", hugepagesSize1G, hugepagesSize2M)))
+		if !hugePagesArch.IsSupportedSize(page.Size) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages, fmt.Sprintf("the page size should be equal to %s on the %s", formatHugePageSizes(hugePagesArch.Sizes), hugePagesArch)))
 		}
 
 		allErrs = append(allErrs, r.validatePageDuplication(&page, r.Spec.HugePages.Pages[i+1:])...)
 	}
 
-	return nil
+	return allErrs
 }
 
 func (r *PerformanceProfile) validatePageDuplication(page *HugePage, pages []HugePage) field.ErrorList {
@@ -225,6 +541,7 @@ func (r *PerformanceProfile) validatePageDuplication(page *HugePage, pages []Hug
 
 		if page.Node == nil && p.Node == nil {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.hugepages.pages"), r.Spec.HugePages.Pages, fmt.Sprintf("the page with the size %q and without the specified NUMA node, has duplication", page.Size)))
+			continue
 		}
 
 		if *page.Node == *p.Node {
@@ -253,6 +570,12 @@ func (r *PerformanceProfile) validateNUMA() field.ErrorList {
 		}
 	}
 
+	// the topology manager does not align resources with the none policy
+	if r.Spec.NUMA.TopologyManagerScope != nil && *r.Spec.NUMA.TopologyManagerScope == TopologyManagerScopePod &&
+		r.Spec.NUMA.TopologyPolicy != nil && *r.Spec.NUMA.TopologyPolicy == kubeletconfigv1beta1.NoneTopologyManagerPolicy {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.numa.topologyManagerScope"), *r.Spec.NUMA.TopologyManagerScope, fmt.Sprintf("the %s scope requires the topology policy other than %s", TopologyManagerScopePod, kubeletconfigv1beta1.NoneTopologyManagerPolicy)))
+	}
+
 	return allErrs
 }
 
@@ -263,7 +586,9 @@ func (r *PerformanceProfile) validateNet() field.ErrorList {
 		return allErrs
 	}
 
-	if r.Spec.Net.UserLevelNetworking != nil && *r.Spec.Net.UserLevelNetworking && r.Spec.CPU.Reserved == nil {
+	// overlays can take reserved CPUs from the base profile
+	reservedMissing := r.Spec.Overlay == nil && (r.Spec.CPU == nil || (r.Spec.CPU.Reserved == nil && r.Spec.CPU.ReservedCount == nil))
+	if r.Spec.Net.UserLevelNetworking != nil && *r.Spec.Net.UserLevelNetworking && reservedMissing {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.net"), r.Spec.Net, "can not set network devices queues count without specifiying spec.cpu.reserved"))
 	}
 
@@ -288,3 +613,439 @@ func isValid16bitsHexID(v string) bool {
 	re := regexp.MustCompile("^0x[0-9a-fA-F]+$")
 	return re.MatchString(v) && len(v) < 7
 }
+
+func (r *PerformanceProfile) validateRollout() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.Rollout == nil {
+		return allErrs
+	}
+
+	if r.Spec.Rollout.Strategy != nil &&
+		*r.Spec.Rollout.Strategy == RolloutStrategyMaintenanceWindow &&
+		len(r.Spec.Rollout.MaintenanceWindows) == 0 {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec.rollout.maintenanceWindows"), "maintenance windows required for the MaintenanceWindow strategy"))
+	}
+
+	for i, window := range r.Spec.Rollout.MaintenanceWindows {
+		windowPath := field.NewPath("spec.rollout.maintenanceWindows").Index(i)
+		if _, err := time.Parse(maintenanceWindowTimeFormat, window.StartTime); err != nil {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("startTime"), window.StartTime, "the start time should have the HH:MM format"))
+		}
+
+		if window.Duration.Duration <= 0 || window.Duration.Duration > maxMaintenanceWindowDuration {
+			allErrs = append(allErrs, field.Invalid(windowPath.Child("duration"), window.Duration, fmt.Sprintf("the duration should be positive and not longer than %v", maxMaintenanceWindowDuration)))
+		}
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validatePowerPolicy() field.ErrorList {
+	var allErrs field.ErrorList
+
+	policy := r.Spec.PowerPolicy
+	if policy == nil || policy.Mode == nil || *policy.Mode != PowerModeUltraLowLatency {
+		return allErrs
+	}
+
+	// the idle polling keeps all CPUs in the C0 state, so C-states of CPUs can not be limited separately
+	if policy.Reserved != nil && policy.Reserved.MaxCState != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.powerPolicy.reserved.maxCState"), *policy.Reserved.MaxCState, "the maximum C-state can not be specified with the ultra-low-latency mode"))
+	}
+	if policy.Isolated != nil && policy.Isolated.MaxCState != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.powerPolicy.isolated.maxCState"), *policy.Isolated.MaxCState, "the maximum C-state can not be specified with the ultra-low-latency mode"))
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateRuntimeClass() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.RuntimeClass == nil {
+		return allErrs
+	}
+
+	if r.Spec.RuntimeClass.Name != nil {
+		for _, msg := range validation.IsDNS1123Subdomain(*r.Spec.RuntimeClass.Name) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.runtimeClass.name"), *r.Spec.RuntimeClass.Name, msg))
+		}
+	}
+
+	if r.Spec.RuntimeClass.Handler != nil {
+		handler := *r.Spec.RuntimeClass.Handler
+		for _, msg := range validation.IsDNS1123Label(handler) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.runtimeClass.handler"), handler, msg))
+		}
+
+		// the CRI-O configuration snippet redefines the default runtime handler
+		if handler == defaultCrioRuntimeHandler {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.runtimeClass.handler"), handler, "the handler can not override the default CRI-O runtime handler"))
+		}
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateStalld() field.ErrorList {
+	var allErrs field.ErrorList
+
+	stalld := r.Spec.Stalld
+	if stalld == nil {
+		return allErrs
+	}
+
+	if stalld.CPUs != nil {
+		if _, err := cpuset.Parse(string(*stalld.CPUs)); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.stalld.cpus"), *stalld.CPUs, err.Error()))
+		}
+	}
+
+	period := defaultStalldBoostPeriod
+	if stalld.BoostPeriod != nil {
+		period = stalld.BoostPeriod.Duration
+		if period <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.stalld.boostPeriod"), *stalld.BoostPeriod, "the boost period should be positive"))
+		}
+	}
+
+	boostRuntime := defaultStalldBoostRuntime
+	if stalld.BoostRuntime != nil {
+		boostRuntime = stalld.BoostRuntime.Duration
+		if boostRuntime <= 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.stalld.boostRuntime"), *stalld.BoostRuntime, "the boost runtime should be positive"))
+		}
+	}
+
+	if boostRuntime > period {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.stalld.boostRuntime"), boostRuntime.String(), fmt.Sprintf("the boost runtime should not be longer than the boost period %v", period)))
+	}
+
+	if stalld.StarvingThreshold != nil {
+		threshold := stalld.StarvingThreshold.Duration
+		if threshold < time.Second || threshold%time.Second != 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.stalld.starvingThreshold"), *stalld.StarvingThreshold, "the starving threshold should be a positive number of whole seconds"))
+		}
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateKernelThreads() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if r.Spec.KernelThreads == nil {
+		return allErrs
+	}
+
+	names := map[string]bool{}
+	for i := range r.Spec.KernelThreads.Groups {
+		group := &r.Spec.KernelThreads.Groups[i]
+		groupPath := field.NewPath("spec.kernelThreads.groups").Index(i)
+
+		// the group name is a part of the tuned scheduler option name
+		for _, msg := range validation.IsDNS1123Label(group.Name) {
+			allErrs = append(allErrs, field.Invalid(groupPath.Child("name"), group.Name, msg))
+		}
+		if names[group.Name] {
+			allErrs = append(allErrs, field.Duplicate(groupPath.Child("name"), group.Name))
+		}
+		names[group.Name] = true
+
+		if group.Regex != nil {
+			if _, err := regexp.Compile(*group.Regex); err != nil {
+				allErrs = append(allErrs, field.Invalid(groupPath.Child("regex"), *group.Regex, err.Error()))
+			}
+		} else if GetKernelThreadsGroupRegex(group) == "" {
+			allErrs = append(allErrs, field.Required(groupPath.Child("regex"), fmt.Sprintf("the regex should be specified for the group %q", group.Name)))
+		}
+
+		priority := GetKernelThreadsGroupPriority(group)
+		if IsRealtimeSchedulingPolicy(GetKernelThreadsGroupPolicy(group)) {
+			if priority < 1 || priority > 99 {
+				allErrs = append(allErrs, field.Invalid(groupPath.Child("priority"), priority, "the priority of real-time policies should be between 1 and 99"))
+			}
+		} else if priority != 0 {
+			allErrs = append(allErrs, field.Invalid(groupPath.Child("priority"), priority, "the priority of non real-time policies should be 0"))
+		}
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateKubelet() field.ErrorList {
+	var allErrs field.ErrorList
+
+	kubelet := r.Spec.Kubelet
+	if kubelet == nil {
+		return allErrs
+	}
+
+	for _, reserved := range []struct {
+		path      string
+		resources corev1.ResourceList
+	}{
+		{path: "spec.kubelet.kubeReserved", resources: kubelet.KubeReserved},
+		{path: "spec.kubelet.systemReserved", resources: kubelet.SystemReserved},
+	} {
+		for name, quantity := range reserved.resources {
+			if !kubeletReservedResources[name] && !IsMemoryResource(name) {
+				allErrs = append(allErrs, field.NotSupported(field.NewPath(reserved.path).Key(string(name)), name, []string{"cpu", "memory", "ephemeral-storage", "pid", "hugepages-<size>"}))
+			}
+			if quantity.Sign() < 0 {
+				allErrs = append(allErrs, field.Invalid(field.NewPath(reserved.path).Key(string(name)), quantity.String(), "the reserved resource should not be negative"))
+			}
+		}
+	}
+
+	for signal, threshold := range kubelet.EvictionHard {
+		if !evictionSignals[signal] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.kubelet.evictionHard").Key(signal), threshold, "unknown eviction signal"))
+			continue
+		}
+
+		// thresholds are quantities or percentages of the node capacity
+		if strings.HasSuffix(threshold, "%") {
+			continue
+		}
+		if _, err := resource.ParseQuantity(threshold); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.kubelet.evictionHard").Key(signal), threshold, "the threshold should be a quantity or a percentage"))
+		}
+	}
+
+	if kubelet.CPUManagerReconcilePeriod != nil && kubelet.CPUManagerReconcilePeriod.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.kubelet.cpuManagerReconcilePeriod"), kubelet.CPUManagerReconcilePeriod.Duration.String(), "the reconcile period should be positive"))
+	}
+
+	allErrs = append(allErrs, r.validateMemoryManager()...)
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateMemoryManager() field.ErrorList {
+	var allErrs field.ErrorList
+
+	memoryManager := r.Spec.Kubelet.MemoryManager
+	if memoryManager == nil {
+		return allErrs
+	}
+
+	if GetMemoryManagerPolicy(&r.Spec) != MemoryManagerPolicyStatic {
+		if len(memoryManager.ReservedMemory) != 0 {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec.kubelet.memoryManager.reservedMemory"), "the reserved memory can be specified only with the Static policy"))
+		}
+		return allErrs
+	}
+
+	required, err := GetRequiredReservedMemory(&r.Spec)
+	if err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec.kubelet.evictionHard").Key(EvictionSignalMemoryAvailable), r.Spec.Kubelet.EvictionHard[EvictionSignalMemoryAvailable], err.Error()))
+		return allErrs
+	}
+
+	// the reserved memory is derived from the kubelet reservation when not specified
+	if len(memoryManager.ReservedMemory) == 0 {
+		return allErrs
+	}
+
+	numaNodes := map[int32]bool{}
+	reserved := corev1.ResourceList{}
+	for i, numaReserved := range memoryManager.ReservedMemory {
+		numaPath := field.NewPath("spec.kubelet.memoryManager.reservedMemory").Index(i)
+		if numaNodes[numaReserved.NUMANode] {
+			allErrs = append(allErrs, field.Duplicate(numaPath.Child("numaNode"), numaReserved.NUMANode))
+		}
+		numaNodes[numaReserved.NUMANode] = true
+
+		for name, quantity := range numaReserved.Limits {
+			if !IsMemoryResource(name) {
+				allErrs = append(allErrs, field.NotSupported(numaPath.Child("limits").Key(string(name)), name, []string{"memory", "hugepages-<size>"}))
+				continue
+			}
+			addQuantity(reserved, name, quantity)
+		}
+	}
+
+	// the kubelet fails to start when the memory reserved on NUMA nodes differs from the memory reserved by the kubelet
+	for _, name := range getResourceNames(required, reserved) {
+		requiredQuantity := required[name]
+		reservedQuantity := reserved[name]
+		if requiredQuantity.Cmp(reservedQuantity) != 0 {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.kubelet.memoryManager.reservedMemory"), reservedQuantity.String(),
+				fmt.Sprintf("the %s reserved on NUMA nodes should be equal to %s reserved for kubernetes and system daemons and the hard eviction threshold", name, requiredQuantity.String())))
+		}
+	}
+
+	return allErrs
+}
+
+// getResourceNames returns sorted names of resources of all lists
+func getResourceNames(lists ...corev1.ResourceList) []corev1.ResourceName {
+	var names []corev1.ResourceName
+	seen := map[corev1.ResourceName]bool{}
+	for _, list := range lists {
+		for name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		return names[i] < names[j]
+	})
+	return names
+}
+
+func (r *PerformanceProfile) validateKubeletConfigOverrides() field.ErrorList {
+	var allErrs field.ErrorList
+
+	path := field.NewPath("spec.kubeletConfigOverrides")
+	conflicts, err := GetKubeletConfigOverridesConflicts(&r.Spec)
+	if err != nil {
+		return append(allErrs, field.Invalid(path, string(r.Spec.KubeletConfigOverrides.Raw), err.Error()))
+	}
+
+	for _, conflict := range conflicts {
+		allErrs = append(allErrs, field.Forbidden(path, conflict))
+	}
+
+	if len(allErrs) != 0 || r.Spec.KubeletConfigOverrides == nil {
+		return allErrs
+	}
+
+	// unknown fields and values of wrong types would make the machine config operator fail to render the kubelet config,
+	// fields are known by the vendored kubelet config of Kubernetes 1.21, so fields added by newer kubelets are rejected
+	decoder := json.NewDecoder(bytes.NewReader(r.Spec.KubeletConfigOverrides.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&kubeletconfigv1beta1.KubeletConfiguration{}); err != nil {
+		allErrs = append(allErrs, field.Invalid(path, string(r.Spec.KubeletConfigOverrides.Raw), fmt.Sprintf("invalid kubelet config: %v", err)))
+	}
+
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateTuning() field.ErrorList {
+	var allErrs field.ErrorList
+
+	tuning := r.Spec.Tuning
+	if tuning == nil {
+		return allErrs
+	}
+
+	for name, value := range tuning.Sysctl {
+		namePath := field.NewPath("spec.tuning.sysctl").Key(name)
+		if !sysctlNameRegex.MatchString(name) {
+			allErrs = append(allErrs, field.Invalid(namePath, name, "the sysctl name should consist of alphanumeric characters, '-' or '_' separated by '.' or '/'"))
+		}
+		allErrs = append(allErrs, validateTuningValue(namePath, value)...)
+	}
+
+	for attribute, value := range tuning.Sysfs {
+		attributePath := field.NewPath("spec.tuning.sysfs").Key(attribute)
+		if !strings.HasPrefix(attribute, "/sys/") || path.Clean(attribute) != attribute || strings.ContainsAny(attribute, " \t\n=#") {
+			allErrs = append(allErrs, field.Invalid(attributePath, attribute, "the sysfs attribute should be a clean absolute path under /sys without whitespaces, '=' or '#'"))
+		}
+		allErrs = append(allErrs, validateTuningValue(attributePath, value)...)
+	}
+
+	for _, conflict := range GetTuningConflicts(&r.Spec) {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec.tuning"), conflict))
+	}
+
+	return allErrs
+}
+
+// validateTuningValue validates the value is rendered as a single tuned option
+func validateTuningValue(valuePath *field.Path, value string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	// tuned treats the rest of the line after '#' as a comment
+	if strings.ContainsAny(value, "\n\r#") {
+		allErrs = append(allErrs, field.Invalid(valuePath, value, "the value should not contain line breaks or '#'"))
+	}
+	return allErrs
+}
+
+func (r *PerformanceProfile) validateTunedProfiles() field.ErrorList {
+	var allErrs field.ErrorList
+
+	names := map[string]bool{}
+	for i := range r.Spec.TunedProfiles {
+		fragment := &r.Spec.TunedProfiles[i]
+		fragmentPath := field.NewPath("spec.tunedProfiles").Index(i)
+
+		// the name is a part of the child tuned profile name
+		for _, msg := range validation.IsDNS1123Label(fragment.Name) {
+			allErrs = append(allErrs, field.Invalid(fragmentPath.Child("name"), fragment.Name, msg))
+		}
+		if names[fragment.Name] {
+			allErrs = append(allErrs, field.Duplicate(fragmentPath.Child("name"), fragment.Name))
+		}
+		names[fragment.Name] = true
+
+		if (fragment.Data == nil) == (fragment.ConfigMapRef == nil) {
+			allErrs = append(allErrs, field.Invalid(fragmentPath, fragment.Name, "exactly one of data or configMapRef should be specified"))
+			continue
+		}
+
+		if fragment.Data != nil {
+			if err := ValidateTunedProfileData(*fragment.Data); err != nil {
+				allErrs = append(allErrs, field.Invalid(fragmentPath.Child("data"), *fragment.Data, err.Error()))
+			}
+			continue
+		}
+
+		// the ConfigMap can change after the admission, so its data is validated by the controller
+		refPath := fragmentPath.Child("configMapRef")
+		if fragment.ConfigMapRef.Name == "" {
+			allErrs = append(allErrs, field.Required(refPath.Child("name"), "the ConfigMap name required"))
+		}
+		if fragment.ConfigMapRef.Namespace == "" {
+			allErrs = append(allErrs, field.Required(refPath.Child("namespace"), "the ConfigMap namespace required"))
+		} else if fragment.ConfigMapRef.Namespace != operatorNamespace {
+			allErrs = append(allErrs, field.NotSupported(refPath.Child("namespace"), fragment.ConfigMapRef.Namespace, []string{operatorNamespace}))
+		}
+		if fragment.ConfigMapRef.Key != nil && *fragment.ConfigMapRef.Key == "" {
+			allErrs = append(allErrs, field.Invalid(refPath.Child("key"), *fragment.ConfigMapRef.Key, "the ConfigMap key should not be empty"))
+		}
+	}
+
+	allErrs = append(allErrs, r.validateTunedProfileNames()...)
+	return allErrs
+}
+
+// validateTunedProfileNames validates names of child tuned profiles do not collide with names of node specific
+// tuned profiles, both are suffixes of the generated tuned profile name
+func (r *PerformanceProfile) validateTunedProfileNames() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if len(r.Spec.TunedProfiles) == 0 || r.Spec.CPU == nil || len(r.Spec.CPU.NodeOverrides) == 0 {
+		return allErrs
+	}
+
+	suffixes := map[string]bool{}
+	for _, override := range r.Spec.CPU.NodeOverrides {
+		suffixes[override.Name] = true
+	}
+
+	for i, fragment := range r.Spec.TunedProfiles {
+		if suffixes[fragment.Name] {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec.tunedProfiles").Index(i).Child("name"), fragment.Name, "the name collides with the name of the tuned profile of a node override"))
+		}
+		suffixes[fragment.Name] = true
+	}
+
+	for _, override := range r.Spec.CPU.NodeOverrides {
+		for i, fragment := range r.Spec.TunedProfiles {
+			name := fmt.Sprintf("%s-%s", override.Name, fragment.Name)
+			if suffixes[name] {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec.tunedProfiles").Index(i).Child("name"), fragment.Name, fmt.Sprintf("the name of the tuned profile of the node override %q collides with the name of another tuned profile", override.Name)))
+			}
+			suffixes[name] = true
+		}
+	}
+
+	return allErrs
+}
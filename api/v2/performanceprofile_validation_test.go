@@ -2,12 +2,18 @@ package v2
 
 import (
 	"fmt"
+	"io/ioutil"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 const (
@@ -41,6 +47,10 @@ const (
 	NetDeviceVendorID = "0x1af4"
 	//NetDeviceModelID defines a net device model ID for the test profile
 	NetDeviceModelID = "0x1000"
+
+	// HardwareSnapshotPath defines the hardware snapshot with 12 CPUs, one NUMA node with 31Gi of memory
+	// and the 0x1af4:0x1000 network device
+	HardwareSnapshotPath = "../../testdata/hardware/sysinfo.tgz"
 )
 
 // NewPerformanceProfile returns new performance profile object that used for tests
@@ -166,6 +176,109 @@ var _ = Describe("PerformanceProfile", func() {
 			Expect(errors).NotTo(BeEmpty(), "should have validation error when reserved and isolation CPUs have overlap")
 			Expect(errors[0].Error()).To(ContainSubstring("reserved and isolated cpus overlap"))
 		})
+
+		Context("with the reserved CPUs count", func() {
+			BeforeEach(func() {
+				profile.Spec.CPU = &CPU{
+					ReservedCount: pointer.Int32Ptr(4),
+				}
+			})
+
+			It("should allow the reserved CPUs count without CPU sets", func() {
+				errors := profile.validateCPUs()
+				Expect(errors).To(BeEmpty())
+			})
+
+			It("should reject the reserved CPUs count together with CPU sets", func() {
+				reservedCPUs := CPUSet("0-3")
+				profile.Spec.CPU.Reserved = &reservedCPUs
+				errors := profile.validateCPUs()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring("reserved CPUs can not be specified together with the reserved CPUs count"))
+			})
+
+			It("should reject the placement policy without the reserved CPUs count", func() {
+				policy := CPUPlacementPolicySplitAcrossNUMA
+				profile.Spec.CPU.ReservedCount = nil
+				profile.Spec.CPU.PlacementPolicy = &policy
+				errors := profile.validateCPUs()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring("spec.cpu.reservedCount"))
+			})
+		})
+
+		Context("with node overrides", func() {
+			var override CPUNodeOverride
+
+			BeforeEach(func() {
+				isolatedCPUs := CPUSet("4-5")
+				override = CPUNodeOverride{
+					Name:         "small",
+					NodeSelector: map[string]string{"feature.node.kubernetes.io/cpu-cores": "6"},
+					Isolated:     &isolatedCPUs,
+				}
+			})
+
+			It("should allow overrides with a subset of isolated CPUs", func() {
+				profile.Spec.CPU.NodeOverrides = []CPUNodeOverride{override}
+				errors := profile.validateCPUs()
+				Expect(errors).To(BeEmpty())
+			})
+
+			It("should reject overrides with CPUs that are not isolated by the profile", func() {
+				isolatedCPUs := CPUSet("2-15")
+				override.Isolated = &isolatedCPUs
+				profile.Spec.CPU.NodeOverrides = []CPUNodeOverride{override}
+				errors := profile.validateCPUs()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring("isolated CPUs should be a subset of the isolated CPUs of the profile, CPUs 2-3,8-15 are not isolated by the profile"))
+			})
+
+			It("should reject overrides with duplicated names and node selectors", func() {
+				profile.Spec.CPU.NodeOverrides = []CPUNodeOverride{override, override}
+				errors := profile.validateCPUs()
+				Expect(errors).To(HaveLen(2))
+				Expect(errors[0].Error()).To(ContainSubstring("spec.cpu.nodeOverrides[1].name"))
+				Expect(errors[1].Error()).To(ContainSubstring("spec.cpu.nodeOverrides[1].nodeSelector"))
+			})
+		})
+
+		Context("with the CPU vendor", func() {
+			It("should accept the ARM vendor on arm64 nodes", func() {
+				vendor := CPUVendorARM
+				profile.Spec.CPU.Vendor = &vendor
+				Expect(profile.validateCPUVendor()).To(BeEmpty())
+				Expect(GetArchitecture(profile)).To(Equal(ArchitectureARM64))
+
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = ArchitectureARM64
+				Expect(profile.validateCPUVendor()).To(BeEmpty())
+			})
+
+			It("should reject the ARM vendor on amd64 nodes", func() {
+				vendor := CPUVendorARM
+				profile.Spec.CPU.Vendor = &vendor
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = ArchitectureAMD64
+				errors := profile.validateCPUVendor()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring(`the CPU vendor "ARM" does not match the amd64 architecture of nodes`))
+			})
+
+			It("should reject the AMD vendor on arm64 nodes", func() {
+				vendor := CPUVendorAMD
+				profile.Spec.CPU.Vendor = &vendor
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = ArchitectureARM64
+				errors := profile.validateCPUVendor()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring(`the CPU vendor "AMD" does not match the arm64 architecture of nodes`))
+			})
+
+			It("should select the vendor from the node selector", func() {
+				Expect(GetCPUVendor(profile)).To(Equal(CPUVendorIntel))
+
+				profile.Spec.NodeSelector[CPUVendorLabel] = string(CPUVendorAMD)
+				Expect(GetCPUVendor(profile)).To(Equal(CPUVendorAMD))
+			})
+		})
 	})
 
 	Describe("Label selectors validation", func() {
@@ -214,6 +327,10 @@ var _ = Describe("PerformanceProfile", func() {
 	})
 
 	Describe("Hugepages validation", func() {
+		It("should accept valid huge pages", func() {
+			Expect(profile.validateHugePages()).To(BeEmpty())
+		})
+
 		It("should reject on incorrect default hugepages size", func() {
 			incorrectDefaultSize := HugePageSize("!#@")
 			profile.Spec.HugePages.DefaultHugePagesSize = &incorrectDefaultSize
@@ -234,6 +351,90 @@ var _ = Describe("PerformanceProfile", func() {
 			Expect(errors[0].Error()).To(ContainSubstring(fmt.Sprintf("the page size should be equal to %q or %q", hugepagesSize1G, hugepagesSize2M)))
 		})
 
+		It("should report the default hugepages size and the page size separately", func() {
+			incorrectDefaultSize := HugePageSize("14M")
+			profile.Spec.HugePages.DefaultHugePagesSize = &incorrectDefaultSize
+			profile.Spec.HugePages.Pages[0].Size = "14M"
+
+			errors := profile.validateHugePages()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("hugepages default size should be equal"))
+			Expect(errors[1].Error()).To(ContainSubstring("the page size should be equal"))
+		})
+
+		Context("with the architecture of nodes", func() {
+			setArchitecture := func(arch string) {
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = arch
+			}
+
+			It("should accept huge page sizes supported by the arm64 architecture", func() {
+				setArchitecture(ArchitectureARM64)
+				defaultSize := HugePageSize("32M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []HugePage{
+					{Size: "64K", Count: 1024},
+					{Size: "2M", Count: 2, Node: pointer.Int32Ptr(0)},
+					{Size: "1G", Count: 1},
+				}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+
+			It("should select arm64 huge page sizes by the kernel page size", func() {
+				setArchitecture(ArchitectureARM64)
+				defaultSize := HugePageSize("512M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []HugePage{
+					{Size: "512M", Count: 2, Node: pointer.Int32Ptr(0)},
+					{Size: "16G", Count: 1},
+				}
+				errors := profile.validateHugePages()
+				Expect(errors).To(HaveLen(3))
+				Expect(errors[0].Error()).To(ContainSubstring(`hugepages default size should be equal to "64K", "2M", "32M" or "1G" on the arm64 architecture with 4K kernel pages`))
+
+				profile.Annotations = map[string]string{KernelPageSizeAnnotation: KernelPageSize64K}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+
+				profile.Annotations[KernelPageSizeAnnotation] = KernelPageSize16K
+				errors = profile.validateHugePages()
+				Expect(errors).To(HaveLen(3))
+				Expect(errors[1].Error()).To(ContainSubstring(`the page size should be equal to "2M", "32M" or "1G" on the arm64 architecture with 16K kernel pages`))
+			})
+
+			It("should accept huge page sizes supported by the ppc64le architecture", func() {
+				setArchitecture(ArchitecturePPC64LE)
+				profile.Spec.HugePages.Pages = []HugePage{{Size: "16M", Count: 64}}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+
+			It("should reject huge page sizes not supported by the s390x architecture", func() {
+				setArchitecture(ArchitectureS390X)
+				errors := profile.validateHugePages()
+				Expect(errors).To(HaveLen(2))
+				Expect(errors[0].Error()).To(ContainSubstring(`hugepages default size should be equal to "1M" or "2G" on the s390x architecture`))
+				Expect(errors[1].Error()).To(ContainSubstring(`the page size should be equal to "1M" or "2G" on the s390x architecture`))
+
+				defaultSize := HugePageSize("2G")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []HugePage{{Size: "2G", Count: 2}, {Size: "1M", Count: 512, Node: pointer.Int32Ptr(0)}}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+
+			It("should reject 64K huge pages on the amd64 architecture", func() {
+				setArchitecture(ArchitectureAMD64)
+				profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages, HugePage{Size: "64K", Count: 16})
+				errors := profile.validateHugePages()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring(`the page size should be equal to "1G" or "2M" on the amd64 architecture`))
+			})
+
+			It("should reject huge pages on unsupported architectures", func() {
+				setArchitecture("riscv64")
+				errors := profile.validateHugePages()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring(`unsupported architecture "riscv64"`))
+			})
+		})
+
 		When("pages have duplication", func() {
 			Context("with specified NUMA node", func() {
 				It("should raise the validation error", func() {
@@ -263,6 +464,18 @@ var _ = Describe("PerformanceProfile", func() {
 					Expect(errors).NotTo(BeEmpty())
 					Expect(errors[0].Error()).To(ContainSubstring(fmt.Sprintf("the page with the size %q and without the specified NUMA node, has duplication", hugepagesSize1G)))
 				})
+
+				It("should raise the validation error once for every duplicated pair", func() {
+					profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+						HugePage{Count: 128, Size: hugepagesSize1G},
+						HugePage{Count: 64, Size: hugepagesSize1G},
+					)
+					errors := profile.validateHugePages()
+					Expect(errors).To(HaveLen(3))
+					for _, err := range errors {
+						Expect(err.Error()).To(ContainSubstring(fmt.Sprintf("the page with the size %q and without the specified NUMA node, has duplication", hugepagesSize1G)))
+					}
+				})
 			})
 
 			Context("with not sequentially duplication blocks", func() {
@@ -281,6 +494,38 @@ var _ = Describe("PerformanceProfile", func() {
 				})
 			})
 		})
+
+		When("pages do not have duplication", func() {
+			It("should accept pages of the same size on different NUMA nodes", func() {
+				profile.Spec.HugePages.Pages = []HugePage{
+					{Count: 2, Size: hugepagesSize1G, Node: pointer.Int32Ptr(0)},
+					{Count: 2, Size: hugepagesSize1G, Node: pointer.Int32Ptr(1)},
+				}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+
+			It("should compare pages without the NUMA node only with each other", func() {
+				profile.Spec.HugePages.Pages = []HugePage{
+					{Count: 2, Size: hugepagesSize1G},
+					{Count: 2, Size: hugepagesSize1G, Node: pointer.Int32Ptr(0)},
+					{Count: 2, Size: hugepagesSize1G},
+				}
+				errors := profile.validateHugePages()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring("without the specified NUMA node, has duplication"))
+
+				profile.Spec.HugePages.Pages = profile.Spec.HugePages.Pages[:2]
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+
+			It("should accept pages of different sizes on the same NUMA node", func() {
+				profile.Spec.HugePages.Pages = []HugePage{
+					{Count: 2, Size: hugepagesSize1G, Node: pointer.Int32Ptr(0)},
+					{Count: 128, Size: hugepagesSize2M, Node: pointer.Int32Ptr(0)},
+				}
+				Expect(profile.validateHugePages()).To(BeEmpty())
+			})
+		})
 	})
 
 	Describe("Net validation", func() {
@@ -313,6 +558,626 @@ var _ = Describe("PerformanceProfile", func() {
 			})
 		})
 	})
+
+	Describe("Rollout validation", func() {
+		BeforeEach(func() {
+			strategy := RolloutStrategyMaintenanceWindow
+			profile.Spec.Rollout = &Rollout{
+				Strategy: &strategy,
+				MaintenanceWindows: []MaintenanceWindow{
+					{
+						Days:      []Weekday{"Saturday"},
+						StartTime: "22:00",
+						Duration:  metav1.Duration{Duration: 4 * time.Hour},
+					},
+				},
+			}
+		})
+
+		It("should not have validation errors with valid maintenance windows", func() {
+			Expect(profile.validateRollout()).To(BeEmpty())
+		})
+
+		It("should require maintenance windows for the MaintenanceWindow strategy", func() {
+			profile.Spec.Rollout.MaintenanceWindows = nil
+			errors := profile.validateRollout()
+			Expect(errors).NotTo(BeEmpty())
+			Expect(errors[0].Error()).To(ContainSubstring("maintenance windows required for the MaintenanceWindow strategy"))
+		})
+
+		It("should reject invalid maintenance windows", func() {
+			profile.Spec.Rollout.MaintenanceWindows[0].StartTime = "25:00"
+			profile.Spec.Rollout.MaintenanceWindows[0].Duration = metav1.Duration{Duration: 8 * 24 * time.Hour}
+			errors := profile.validateRollout()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("the start time should have the HH:MM format"))
+			Expect(errors[1].Error()).To(ContainSubstring("the duration should be positive"))
+		})
+	})
+
+	Describe("RuntimeClass validation", func() {
+		It("should reject invalid names and the default CRI-O handler", func() {
+			profile.Spec.RuntimeClass = &RuntimeClass{
+				Name:    pointer.StringPtr("Low_Latency"),
+				Handler: pointer.StringPtr("runc"),
+			}
+			errors := profile.validateRuntimeClass()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.runtimeClass.name"))
+			Expect(errors[1].Error()).To(ContainSubstring("the handler can not override the default CRI-O runtime handler"))
+		})
+
+		It("should reject runtime classes with the same name", func() {
+			other := profile.DeepCopy()
+			other.Name = "other"
+			other.Spec.RuntimeClass = &RuntimeClass{Name: pointer.StringPtr("low-latency")}
+			ppList := &PerformanceProfileList{Items: []PerformanceProfile{*other}}
+			Expect(profile.validateRuntimeClassDuplication(ppList)).To(BeEmpty())
+
+			profile.Spec.RuntimeClass = &RuntimeClass{Name: pointer.StringPtr("low-latency")}
+			errors := profile.validateRuntimeClassDuplication(ppList)
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`the performance profile "other" creates the RuntimeClass with the same name`))
+		})
+	})
+
+	Describe("Power policy validation", func() {
+		It("should allow the maximum C-state of isolated CPUs with the low-latency mode", func() {
+			mode := PowerModeLowLatency
+			profile.Spec.PowerPolicy = &PowerPolicy{
+				Mode:     &mode,
+				Isolated: &CPUPowerSettings{MaxCState: pointer.Int32Ptr(0)},
+			}
+			Expect(profile.validatePowerPolicy()).To(BeEmpty())
+		})
+
+		It("should reject the maximum C-state with the ultra-low-latency mode", func() {
+			mode := PowerModeUltraLowLatency
+			profile.Spec.PowerPolicy = &PowerPolicy{
+				Mode:     &mode,
+				Reserved: &CPUPowerSettings{MaxCState: pointer.Int32Ptr(6)},
+			}
+			errors := profile.validatePowerPolicy()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.powerPolicy.reserved.maxCState"))
+			Expect(errors[0].Error()).To(ContainSubstring("the maximum C-state can not be specified with the ultra-low-latency mode"))
+		})
+	})
+
+	Describe("Stalld validation", func() {
+		It("should allow the stalld configuration with a boost runtime shorter than the boost period", func() {
+			cpus := CPUSet("0-1")
+			profile.Spec.Stalld = &Stalld{
+				CPUs:              &cpus,
+				BoostPeriod:       &metav1.Duration{Duration: time.Second},
+				BoostRuntime:      &metav1.Duration{Duration: 50 * time.Microsecond},
+				StarvingThreshold: &metav1.Duration{Duration: 10 * time.Second},
+			}
+			Expect(profile.validateStalld()).To(BeEmpty())
+		})
+
+		It("should reject the boost runtime longer than the boost period", func() {
+			profile.Spec.Stalld = &Stalld{
+				BoostRuntime: &metav1.Duration{Duration: 2 * time.Second},
+			}
+			errors := profile.validateStalld()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the boost runtime should not be longer than the boost period 1s"))
+		})
+
+		It("should reject invalid CPUs and starving thresholds", func() {
+			cpus := CPUSet("0-a")
+			profile.Spec.Stalld = &Stalld{
+				CPUs:              &cpus,
+				StarvingThreshold: &metav1.Duration{Duration: 1500 * time.Millisecond},
+			}
+			errors := profile.validateStalld()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.stalld.cpus"))
+			Expect(errors[1].Error()).To(ContainSubstring("the starving threshold should be a positive number of whole seconds"))
+		})
+	})
+
+	Describe("Kernel threads validation", func() {
+		It("should allow known groups without the regex", func() {
+			rr := SchedulingPolicyRoundRobin
+			profile.Spec.KernelThreads = &KernelThreads{
+				Groups: []KernelThreadsGroup{
+					{Name: KernelThreadsGroupKworker, Policy: &rr, Priority: pointer.Int32Ptr(1)},
+					{Name: "irq", Regex: pointer.StringPtr("irq/[0-9]+-.*")},
+				},
+			}
+			Expect(profile.validateKernelThreads()).To(BeEmpty())
+		})
+
+		It("should reject custom groups without the regex and invalid regexes", func() {
+			profile.Spec.KernelThreads = &KernelThreads{
+				Groups: []KernelThreadsGroup{
+					{Name: "irq"},
+					{Name: "custom", Regex: pointer.StringPtr("irq/[0-9+")},
+				},
+			}
+			errors := profile.validateKernelThreads()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring(`the regex should be specified for the group "irq"`))
+			Expect(errors[1].Error()).To(ContainSubstring("spec.kernelThreads.groups[1].regex"))
+		})
+
+		It("should reject priorities out of the range of the policy and duplicated groups", func() {
+			batch := SchedulingPolicyBatch
+			profile.Spec.KernelThreads = &KernelThreads{
+				Groups: []KernelThreadsGroup{
+					{Name: KernelThreadsGroupRcuc, Priority: pointer.Int32Ptr(0)},
+					{Name: KernelThreadsGroupRcuc, Policy: &batch, Priority: pointer.Int32Ptr(5)},
+				},
+			}
+			errors := profile.validateKernelThreads()
+			Expect(errors).To(HaveLen(3))
+			Expect(errors[0].Error()).To(ContainSubstring("the priority of real-time policies should be between 1 and 99"))
+			Expect(errors[1].Error()).To(ContainSubstring("spec.kernelThreads.groups[1].name"))
+			Expect(errors[2].Error()).To(ContainSubstring("the priority of non real-time policies should be 0"))
+		})
+	})
+
+	Describe("CPU manager policy options validation", func() {
+		It("should reject duplicated options", func() {
+			profile.Spec.CPU.ManagerPolicyOptions = []CPUManagerPolicyOption{
+				CPUManagerPolicyOptionFullPCPUsOnly,
+				CPUManagerPolicyOptionFullPCPUsOnly,
+			}
+			errors := profile.validateCPUManagerPolicyOptions()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.cpu.managerPolicyOptions[1]"))
+		})
+
+		It("should require the AvoidHTSiblings placement policy for the full-pcpus-only option", func() {
+			profile.Spec.CPU = &CPU{
+				ReservedCount:        pointer.Int32Ptr(3),
+				ManagerPolicyOptions: []CPUManagerPolicyOption{CPUManagerPolicyOptionFullPCPUsOnly},
+			}
+			errors := profile.validateCPUManagerPolicyOptions()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("use the AvoidHTSiblings placement policy"))
+
+			policy := CPUPlacementPolicyAvoidHTSiblings
+			profile.Spec.CPU.PlacementPolicy = &policy
+			Expect(profile.validateCPUManagerPolicyOptions()).To(BeEmpty())
+		})
+
+		It("should reject the pod topology manager scope with the none topology policy", func() {
+			scope := TopologyManagerScopePod
+			profile.Spec.NUMA.TopologyManagerScope = &scope
+			Expect(profile.validateNUMA()).To(BeEmpty())
+
+			profile.Spec.NUMA.TopologyPolicy = pointer.StringPtr("none")
+			errors := profile.validateNUMA()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the pod scope requires the topology policy other than none"))
+		})
+	})
+
+	Describe("Kubelet validation", func() {
+		var policy MemoryManagerPolicy
+
+		BeforeEach(func() {
+			policy = MemoryManagerPolicyStatic
+			profile.Spec.Kubelet = &Kubelet{
+				MemoryManager: &MemoryManager{Policy: &policy},
+			}
+		})
+
+		It("should allow the reserved memory equal to the kubelet reservation", func() {
+			profile.Spec.Kubelet.MemoryManager.ReservedMemory = []NUMAReservedMemory{
+				{NUMANode: 0, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("600Mi")}},
+				{NUMANode: 1, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("500Mi")}},
+			}
+			Expect(profile.validateKubelet()).To(BeEmpty())
+		})
+
+		It("should reject the reserved memory that differs from the kubelet reservation", func() {
+			profile.Spec.Kubelet.EvictionHard = map[string]string{EvictionSignalMemoryAvailable: "200Mi"}
+			profile.Spec.Kubelet.MemoryManager.ReservedMemory = []NUMAReservedMemory{
+				{NUMANode: 0, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1100Mi")}},
+			}
+			errors := profile.validateKubelet()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the memory reserved on NUMA nodes should be equal to 1200Mi"))
+		})
+
+		It("should reject the memory eviction threshold in percents with the Static policy", func() {
+			profile.Spec.Kubelet.EvictionHard = map[string]string{EvictionSignalMemoryAvailable: "10%"}
+			errors := profile.validateKubelet()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`the memory.available hard eviction threshold "10%" can not be a percentage with the Static memory manager policy`))
+
+			policy = MemoryManagerPolicyNone
+			Expect(profile.validateKubelet()).To(BeEmpty())
+		})
+
+		It("should reject the reserved memory without the Static policy and unknown eviction signals", func() {
+			policy = MemoryManagerPolicyNone
+			profile.Spec.Kubelet.EvictionHard = map[string]string{"memory.free": "100Mi"}
+			profile.Spec.Kubelet.MemoryManager.ReservedMemory = []NUMAReservedMemory{
+				{NUMANode: 0, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1100Mi")}},
+			}
+			errors := profile.validateKubelet()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("unknown eviction signal"))
+			Expect(errors[1].Error()).To(ContainSubstring("the reserved memory can be specified only with the Static policy"))
+		})
+	})
+
+	Describe("Kubelet config overrides validation", func() {
+		It("should allow kubelet config fields not owned by the operator", func() {
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+				Raw: []byte(`{"maxPods":500,"imageGCHighThresholdPercent":90}`),
+			}
+			Expect(profile.validateKubeletConfigOverrides()).To(BeEmpty())
+		})
+
+		It("should reject kubelet config fields owned by the operator", func() {
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+				Raw: []byte(`{"topologyManagerPolicy":"none","cpuManagerPolicy":"none"}`),
+			}
+			errors := profile.validateKubeletConfigOverrides()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring(`the kubelet config field "cpuManagerPolicy" is owned by the operator`))
+			Expect(errors[1].Error()).To(ContainSubstring(`the kubelet config field "topologyManagerPolicy" is owned by the operator, use spec.numa.topologyPolicy instead`))
+		})
+
+		It("should match kubelet config fields case-insensitively", func() {
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+				Raw: []byte(`{"CPUManagerPolicy":"none","featuregates":{"RotateKubeletServerCertificate":true}}`),
+			}
+			errors := profile.validateKubeletConfigOverrides()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring(`the kubelet config field "cpuManagerPolicy" is owned by the operator`))
+			Expect(errors[1].Error()).To(ContainSubstring(`the kubelet config field "featureGates" is owned by the operator`))
+
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"maxPods":500,"MaxPods":250}`)}
+			errors = profile.validateKubeletConfigOverrides()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`the kubelet config field "maxPods" is specified more than once as "MaxPods" and "maxPods"`))
+
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"MaxPods":500}`)}
+			Expect(profile.validateKubeletConfigOverrides()).To(BeEmpty())
+			overrides, err := GetKubeletConfigOverrides(&profile.Spec)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(overrides).To(HaveKeyWithValue("maxPods", BeNumerically("==", 500)))
+		})
+
+		It("should reject unknown kubelet config fields and values of wrong types", func() {
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"maxPod":500}`)}
+			errors := profile.validateKubeletConfigOverrides()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`unknown field "maxPod"`))
+
+			profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{Raw: []byte(`{"maxPods":"many"}`)}
+			errors = profile.validateKubeletConfigOverrides()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("invalid kubelet config"))
+		})
+	})
+
+	Describe("Tuning validation", func() {
+		It("should allow sysctl and sysfs overrides not owned by the operator", func() {
+			profile.Spec.Tuning = &Tuning{
+				Sysctl: map[string]string{
+					"kernel.hung_task_timeout_secs":    "700",
+					"net.ipv4.conf.eth0/100.rp_filter": "0",
+					"kernel.nmi_watchdog":              "",
+				},
+				Sysfs: map[string]string{
+					"/sys/kernel/mm/ksm/run":                                   "0",
+					"/sys/devices/system/machinecheck/machinecheck*/ignore_ce": "1",
+				},
+			}
+			Expect(profile.validateTuning()).To(BeEmpty())
+		})
+
+		It("should reject malformed sysctl names, sysfs paths and values", func() {
+			profile.Spec.Tuning = &Tuning{
+				Sysctl: map[string]string{"kernel hung_task_timeout_secs": "700"},
+				Sysfs:  map[string]string{"/sys/../etc/passwd": "0", "/proc/sys/vm/swappiness": "0"},
+			}
+			Expect(profile.validateTuning()).To(HaveLen(3))
+
+			profile.Spec.Tuning = &Tuning{
+				Sysctl: map[string]string{"vm.swappiness": "0\n[bootloader]"},
+				Sysfs:  map[string]string{"/sys/kernel/mm/ksm/run": "0 # disabled"},
+			}
+			errors := profile.validateTuning()
+			Expect(errors).To(HaveLen(2))
+			Expect(errors[0].Error()).To(ContainSubstring("the value should not contain line breaks or '#'"))
+		})
+
+		It("should reject sysctl and sysfs settings owned by the operator", func() {
+			profile.Spec.Tuning = &Tuning{
+				Sysctl: map[string]string{"kernel/sched_rt_runtime_us": "950000", "vm.nr_hugepages": "128"},
+				Sysfs:  map[string]string{"/sys/devices/system/cpu/cpu4/cpuidle/state2/disable": "0"},
+			}
+			errors := profile.validateTuning()
+			Expect(errors).To(HaveLen(3))
+			Expect(errors[0].Error()).To(ContainSubstring(`the sysctl "kernel/sched_rt_runtime_us" is owned by the operator`))
+			Expect(errors[1].Error()).To(ContainSubstring(`the sysctl "vm.nr_hugepages" is owned by the operator, huge pages are configured by spec.hugepages`))
+			Expect(errors[2].Error()).To(ContainSubstring(`the sysfs attribute "/sys/devices/system/cpu/cpu4/cpuidle/state2/disable" is owned by the operator, C-states are configured by spec.powerPolicy`))
+		})
+
+		It("should reject sysfs globs and links that reach attributes owned by the operator", func() {
+			for _, attribute := range []string{
+				"/sys/devices/system/cpu/*/cpuidle/*/disable",
+				"/sys/devices/system/cpu/cpu[0-9]/cpuidle/state?/disable",
+				"/sys/devices/system/node/*/hugepages/*/nr_hugepages",
+				"/sys/devices/system/*/node1/hugepages/hugepages-2048kB/nr_hugepages",
+				"/sys/bus/cpu/devices/cpu4/cpuidle/state2/disable",
+				"/sys/bus/*/devices/*/cpuidle/state2/disable",
+				"/sys/bus/node/devices/node0/hugepages/hugepages-1048576kB/nr_hugepages",
+				"/sys/kernel/mm/hugepages//hugepages-2048kB/nr_hugepages",
+			} {
+				Expect(getTuningConflicts(nil, map[string]string{attribute: "0"})).To(HaveLen(1), attribute)
+			}
+
+			for _, attribute := range []string{
+				"/sys/devices/system/cpu/*/cpufreq/scaling_governor",
+				"/sys/devices/system/node/*/compact",
+				"/sys/bus/*/devices/*/power/control",
+				"/sys/kernel/mm/transparent_hugepage/enabled",
+			} {
+				Expect(getTuningConflicts(nil, map[string]string{attribute: "0"})).To(BeEmpty(), attribute)
+			}
+		})
+	})
+
+	Describe("Tuned profiles validation", func() {
+		It("should allow inline and referenced tuned profiles", func() {
+			profile.Spec.TunedProfiles = []TunedProfileFragment{
+				{Name: "sysctl", Data: pointer.StringPtr("[main]\nsummary=custom\n\n# comment\n[sysctl]\nkernel.panic = 10\n")},
+				{Name: "ksm", ConfigMapRef: &TunedProfileConfigMapReference{Name: "tuned", Namespace: ReferencedConfigMapsNamespace}},
+			}
+			Expect(profile.validateTunedProfiles()).To(BeEmpty())
+		})
+
+		It("should reject tuned profiles referenced from other namespaces", func() {
+			profile.Spec.TunedProfiles = []TunedProfileFragment{
+				{Name: "ksm", ConfigMapRef: &TunedProfileConfigMapReference{Name: "tuned", Namespace: "default"}},
+			}
+			errors := profile.validateTunedProfiles()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`spec.tunedProfiles[0].configMapRef.namespace: Unsupported value: "default"`))
+		})
+
+		It("should reject tuned profiles with invalid names or sources", func() {
+			profile.Spec.TunedProfiles = []TunedProfileFragment{
+				{Name: "Sysctl", Data: pointer.StringPtr("[sysctl]\nkernel.panic=10\n")},
+				{Name: "ksm"},
+				{Name: "ksm", ConfigMapRef: &TunedProfileConfigMapReference{Key: pointer.StringPtr("")}},
+			}
+			errors := profile.validateTunedProfiles()
+			Expect(errors).To(HaveLen(6))
+			Expect(errors[1].Error()).To(ContainSubstring("exactly one of data or configMapRef should be specified"))
+			Expect(errors[2].Error()).To(ContainSubstring("Duplicate value"))
+			Expect(errors[3].Error()).To(ContainSubstring("the ConfigMap name required"))
+			Expect(errors[4].Error()).To(ContainSubstring("the ConfigMap namespace required"))
+			Expect(errors[5].Error()).To(ContainSubstring("the ConfigMap key should not be empty"))
+		})
+
+		It("should reject tuned profiles with invalid syntax", func() {
+			for data, msg := range map[string]string{
+				"kernel.panic=10\n":                                                   `line 1: the option "kernel.panic=10" should belong to a section`,
+				"[sysctl\nkernel.panic=10\n":                                          `line 1: malformed section header "[sysctl"`,
+				"[sysctl]\nkernel.panic\n":                                            `line 2: "kernel.panic" should be a section header, an option or a comment`,
+				"[sysctl]\n[sysctl]\n":                                                `line 2: duplicate section "sysctl"`,
+				"[sysctl]\nkernel.panic=10\nkernel.panic=5\n":                         `line 3: duplicate option "kernel.panic" of the section "sysctl"`,
+				"[main]\ninclude=openshift-node\n":                                    "line 2: the profile should not include other profiles",
+				"# empty\n":                                                           "the profile does not have any sections",
+				"[bootloader]\ncmdline_custom=+nosmt\n":                               `line 1: the section "bootloader" is not allowed, kernel arguments are configured by the performance profile`,
+				"[variables]\nisolated_cores=1-3\n":                                   `line 1: the section "variables" is not allowed, variables of the parent profile`,
+				"[sysctl]\nkernel.sched_rt_runtime_us=-1\n":                           `the sysctl "kernel.sched_rt_runtime_us" is owned by the operator`,
+				"[sysfs]\n/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages=8\n": `the sysfs attribute "/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages" is owned by the operator`,
+			} {
+				profile.Spec.TunedProfiles = []TunedProfileFragment{{Name: "custom", Data: pointer.StringPtr(data)}}
+				errors := profile.validateTunedProfiles()
+				Expect(errors).To(HaveLen(1))
+				Expect(errors[0].Error()).To(ContainSubstring(msg))
+			}
+		})
+
+		It("should reject tuned profiles with names colliding with node specific tuned profiles", func() {
+			isolated := CPUSet("4-15")
+			profile.Spec.CPU.NodeOverrides = []CPUNodeOverride{
+				{Name: "large", NodeSelector: map[string]string{"cores": "16"}, Isolated: &isolated},
+				{Name: "large-ksm", NodeSelector: map[string]string{"cores": "32"}, Isolated: &isolated},
+			}
+			profile.Spec.TunedProfiles = []TunedProfileFragment{
+				{Name: "large", Data: pointer.StringPtr("[sysctl]\nkernel.panic=10\n")},
+			}
+			errors := profile.validateTunedProfileNames()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the name collides with the name of the tuned profile of a node override"))
+
+			profile.Spec.TunedProfiles[0].Name = "ksm"
+			errors = profile.validateTunedProfileNames()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`the name of the tuned profile of the node override "large" collides with the name of another tuned profile`))
+		})
+	})
+
+	Describe("Overlay validation", func() {
+		var overlay *PerformanceProfile
+
+		BeforeEach(func() {
+			overlay = &PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+				Spec: PerformanceProfileSpec{
+					HugePages: &HugePages{
+						Pages: []HugePage{
+							{
+								Count: 8,
+								Size:  HugePageSize1G,
+							},
+						},
+					},
+					MachineConfigLabel:        profile.Spec.MachineConfigLabel,
+					MachineConfigPoolSelector: profile.Spec.MachineConfigPoolSelector,
+					NodeSelector:              profile.Spec.NodeSelector,
+					Overlay: &Overlay{
+						BaseProfile: profile.Name,
+					},
+				},
+			}
+		})
+
+		It("should allow overlays without the CPU section and with the base profile node selector", func() {
+			ppList := &PerformanceProfileList{Items: []PerformanceProfile{*profile}}
+			Expect(overlay.validateFields()).To(BeEmpty())
+			Expect(overlay.validateNodeSelectorDuplication(ppList)).To(BeEmpty())
+			Expect(overlay.validateOverlay(ppList)).To(BeEmpty())
+		})
+
+		It("should reject the overlay of itself", func() {
+			overlay.Spec.Overlay.BaseProfile = overlay.Name
+			errors := overlay.validateOverlay(&PerformanceProfileList{})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the overlay can not be merged into itself"))
+		})
+
+		It("should reject the overlay of another overlay", func() {
+			profile.Spec.Overlay = &Overlay{BaseProfile: "another"}
+			errors := overlay.validateOverlay(&PerformanceProfileList{Items: []PerformanceProfile{*profile}})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the base profile can not be an overlay"))
+		})
+
+		It("should reject the overlay that targets another machine config pool", func() {
+			overlay.Spec.MachineConfigPoolSelector = map[string]string{"another": "pool"}
+			errors := overlay.validateOverlay(&PerformanceProfileList{Items: []PerformanceProfile{*profile}})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the overlay should have the same machine config pool selector as the base profile"))
+
+			errors = profile.validateOverlay(&PerformanceProfileList{Items: []PerformanceProfile{*overlay}})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the profile should have the same machine config pool selector as its overlay"))
+		})
+
+		It("should reject the overlay that selects other nodes", func() {
+			overlay.Spec.NodeSelector = map[string]string{"node-role.kubernetes.io/worker-cnf": "", "zone": "a"}
+			errors := overlay.validateOverlay(&PerformanceProfileList{Items: []PerformanceProfile{*profile}})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Field).To(Equal("spec.nodeSelector"))
+			Expect(errors[0].Error()).To(ContainSubstring("the overlay should have the same node selector as the base profile"))
+
+			errors = profile.validateOverlay(&PerformanceProfileList{Items: []PerformanceProfile{*overlay}})
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the profile should have the same node selector as its overlay"))
+		})
+
+		It("should still reject profiles with duplicated node selectors", func() {
+			another := NewPerformanceProfile("another")
+			errors := profile.validateNodeSelectorDuplication(&PerformanceProfileList{Items: []PerformanceProfile{*another}})
+			Expect(errors).To(HaveLen(1))
+		})
+	})
+
+	Describe("Hardware validation", func() {
+		BeforeEach(func() {
+			data, err := ioutil.ReadFile(HardwareSnapshotPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			hardwareReader = fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "worker-hardware",
+					Namespace: ReferencedConfigMapsNamespace,
+				},
+				BinaryData: map[string][]byte{
+					HardwareSnapshotKey: data,
+				},
+			}).Build()
+
+			profile.Spec.HardwareRef = &HardwareReference{
+				Name:      "worker-hardware",
+				Namespace: ReferencedConfigMapsNamespace,
+			}
+		})
+
+		AfterEach(func() {
+			hardwareReader = nil
+		})
+
+		It("should accept the profile that fits the hardware", func() {
+			Expect(profile.validateHardware()).To(BeEmpty())
+		})
+
+		It("should reject the missing hardware snapshot", func() {
+			profile.Spec.HardwareRef.Name = "missing"
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.hardwareRef: Not found"))
+		})
+
+		It("should reject hardware snapshots outside of the operator namespace", func() {
+			profile.Spec.HardwareRef.Namespace = "default"
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(`spec.hardwareRef.namespace: Unsupported value: "default"`))
+		})
+
+		It("should read hardware snapshots from the namespace the operator runs in", func() {
+			SetOperatorNamespace("performance-operator")
+			defer SetOperatorNamespace(ReferencedConfigMapsNamespace)
+
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring(fmt.Sprintf(`spec.hardwareRef.namespace: Unsupported value: %q`, ReferencedConfigMapsNamespace)))
+
+			profile.Spec.HardwareRef.Namespace = "performance-operator"
+			errors = profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.hardwareRef: Not found"))
+		})
+
+		It("should reject CPUs that do not exist on the hardware", func() {
+			isolated := CPUSet("4-15")
+			profile.Spec.CPU.Isolated = &isolated
+
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("spec.cpu.isolated"))
+			Expect(errors[0].Error()).To(ContainSubstring("CPUs 12-15 do not exist on the hardware, the hardware has CPUs 0-11"))
+		})
+
+		It("should reject reserved CPUs that split physical cores with the full-pcpus-only option", func() {
+			reserved := CPUSet("0-2")
+			isolated := CPUSet("3-11")
+			profile.Spec.CPU.Reserved = &reserved
+			profile.Spec.CPU.Isolated = &isolated
+			profile.Spec.CPU.ManagerPolicyOptions = []CPUManagerPolicyOption{CPUManagerPolicyOptionFullPCPUsOnly}
+
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("reserved CPUs should take whole physical cores with the full-pcpus-only CPU manager policy option"))
+		})
+
+		It("should reject huge pages that do not fit into the memory", func() {
+			profile.Spec.HugePages.Pages[0].Count = 40
+			node := int32(1)
+			profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages, HugePage{Size: HugePageSize1G, Count: 1, Node: &node})
+
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(3))
+			Expect(errors[0].Error()).To(ContainSubstring("the NUMA node 1 does not exist on the hardware"))
+			Expect(errors[1].Error()).To(ContainSubstring("more than 32924120Ki of the usable memory"))
+			Expect(errors[2].Error()).To(ContainSubstring("on the NUMA node 0, more than 32924120Ki of the NUMA node memory"))
+		})
+
+		It("should reject network devices that do not exist on the hardware", func() {
+			deviceID := "0x1001"
+			profile.Spec.Net.Devices[0].DeviceID = &deviceID
+
+			errors := profile.validateHardware()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("the network device with the vendor ID 0x1af4 and the device ID 0x1001 does not exist on the hardware"))
+		})
+	})
 })
 
 func setValidNodeSelector(profile *PerformanceProfile) {
@@ -1,9 +1,17 @@
 package v2
 
 import (
+	"context"
+	"fmt"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/klog"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 var _ webhook.Validator = &PerformanceProfile{}
@@ -11,13 +19,117 @@ var _ webhook.Validator = &PerformanceProfile{}
 // we need this variable only because our validate methods should have access to the client
 var validatorClient client.Client
 
+// ChangeImpactEstimator returns the impact on nodes of the change between the old and the new profiles,
+// the nil old profile means that the profile is created
+// +kubebuilder:object:generate=false
+type ChangeImpactEstimator func(old, new *PerformanceProfile) (*ChangeImpact, error)
+
+// the estimator renders profile components, so it should be provided by the caller to avoid an import cycle
+var changeImpactEstimator ChangeImpactEstimator
+
+// SetChangeImpactEstimator sets the estimator used by the webhook to warn about the impact of the profile change
+func SetChangeImpactEstimator(estimator ChangeImpactEstimator) {
+	changeImpactEstimator = estimator
+}
+
+// operatorNamespace is the namespace of ConfigMaps referenced by profiles, the namespace the operator runs in
+var operatorNamespace = ReferencedConfigMapsNamespace
+
+// SetOperatorNamespace sets the namespace the operator runs in, the webhook accepts only references to ConfigMaps
+// of this namespace
+func SetOperatorNamespace(namespace string) {
+	operatorNamespace = namespace
+}
+
 // SetupWebhookWithManager enables Webhooks - needed for version conversion
 func (r *PerformanceProfile) SetupWebhookWithManager(mgr ctrl.Manager) error {
 	if validatorClient == nil {
 		validatorClient = mgr.GetClient()
 	}
 
+	if hardwareReader == nil {
+		hardwareReader = mgr.GetAPIReader()
+	}
+
+	// register the validating webhook with admission warnings before the builder,
+	// the builder skips the registration of already handled paths
+	gvk := GroupVersion.WithKind("PerformanceProfile")
+	mgr.GetWebhookServer().Register(getValidatePath(gvk), &admission.Webhook{
+		Handler: &validatingHandler{
+			validator: admission.ValidatingWebhookFor(r).Handler,
+		},
+	})
+
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
 		Complete()
 }
+
+// getValidatePath returns the same path as the one generated by the webhook builder
+func getValidatePath(gvk schema.GroupVersionKind) string {
+	return fmt.Sprintf("/validate-%s-%s-%s", strings.ReplaceAll(gvk.Group, ".", "-"), gvk.Version, strings.ToLower(gvk.Kind))
+}
+
+// validatingHandler wraps the default validating handler and adds admission warnings to allowed requests
+type validatingHandler struct {
+	validator admission.Handler
+	decoder   *admission.Decoder
+}
+
+var _ admission.DecoderInjector = &validatingHandler{}
+
+// InjectDecoder injects the decoder into the handler and into the wrapped validator
+func (h *validatingHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	_, err := admission.InjectDecoderInto(d, h.validator)
+	return err
+}
+
+// Handle validates the request and adds admission warnings to the allowed response
+func (h *validatingHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	resp := h.validator.Handle(ctx, req)
+	if !resp.Allowed {
+		return resp
+	}
+
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return resp
+	}
+
+	profile := &PerformanceProfile{}
+	if err := h.decoder.DecodeRaw(req.Object, profile); err != nil {
+		klog.Errorf("failed to decode the performance profile: %v", err)
+		return resp
+	}
+
+	var oldProfile *PerformanceProfile
+	if req.Operation == admissionv1.Update {
+		oldProfile = &PerformanceProfile{}
+		if err := h.decoder.DecodeRaw(req.OldObject, oldProfile); err != nil {
+			klog.Errorf("failed to decode the old performance profile: %v", err)
+			return resp
+		}
+	}
+
+	return resp.WithWarnings(profile.getWarnings(oldProfile)...)
+}
+
+// getWarnings returns admission warnings for the profile change
+func (r *PerformanceProfile) getWarnings(old *PerformanceProfile) []string {
+	var warnings []string
+
+	// the change of the overlay is estimated for its base profile by the estimator
+	if changeImpactEstimator != nil {
+		impact, err := changeImpactEstimator(old, r)
+		if err != nil {
+			klog.Errorf("failed to estimate the impact of the performance profile %q change: %v", r.Name, err)
+			warnings = append(warnings, fmt.Sprintf("performance profile %q: failed to estimate the impact of the change on nodes: %v", r.Name, err))
+		} else if impact.Type != ChangeImpactNone {
+			warnings = append(warnings, fmt.Sprintf("performance profile %q: %s", r.Name, impact.Message))
+		}
+	}
+
+	warnings = append(warnings, r.getCPUTopologyWarnings()...)
+	warnings = append(warnings, r.getHugePagesWarnings()...)
+	return warnings
+}
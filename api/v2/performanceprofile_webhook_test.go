@@ -0,0 +1,317 @@
+package v2
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// splitSiblingsTopology has hyperthreading siblings 0/4, 1/5, 2/6 and 3/7
+const splitSiblingsTopology = `{"nodes":[` +
+	`{"id":0,"cores":[{"id":0,"logical_processors":[0,4]},{"id":1,"logical_processors":[1,5]}]},` +
+	`{"id":1,"cores":[{"id":0,"logical_processors":[2,6]},{"id":1,"logical_processors":[3,7]}]}]}`
+
+// wholeCoresTopology has hyperthreading siblings 0/1, 2/3, 4/5, 6/7 and 8/9, NUMA node 1 has CPUs 4-9
+const wholeCoresTopology = `{"nodes":[` +
+	`{"id":0,"cores":[{"id":0,"logical_processors":[0,1]},{"id":1,"logical_processors":[2,3]}]},` +
+	`{"id":1,"cores":[{"id":0,"logical_processors":[4,5]},{"id":1,"logical_processors":[6,7]},{"id":2,"logical_processors":[8,9]}]}]}`
+
+// newValidatorClient returns the fake client with nodes and performance profiles
+func newValidatorClient(objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(s)).To(Succeed())
+	Expect(AddToScheme(s)).To(Succeed())
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+var _ = Describe("PerformanceProfile webhook", func() {
+	var profile *PerformanceProfile
+
+	BeforeEach(func() {
+		profile = NewPerformanceProfile("test")
+	})
+
+	AfterEach(func() {
+		SetChangeImpactEstimator(nil)
+		validatorClient = nil
+	})
+
+	It("should use the same validating path as the webhook builder", func() {
+		Expect(getValidatePath(GroupVersion.WithKind("PerformanceProfile"))).To(Equal("/validate-performance-openshift-io-v2-performanceprofile"))
+	})
+
+	Context("with the change impact estimator", func() {
+		It("should warn about the change impact", func() {
+			var estimatedOld *PerformanceProfile
+			SetChangeImpactEstimator(func(old, new *PerformanceProfile) (*ChangeImpact, error) {
+				estimatedOld = old
+				return &ChangeImpact{
+					Type:           ChangeImpactMachineConfigUpdate,
+					RebootRequired: true,
+					Message:        "nodes will be rebooted",
+				}, nil
+			})
+
+			oldProfile := profile.DeepCopy()
+			warnings := profile.getWarnings(oldProfile)
+			Expect(estimatedOld).To(Equal(oldProfile))
+			Expect(warnings).To(ConsistOf(`performance profile "test": nodes will be rebooted`))
+		})
+
+		It("should not warn when the change does not affect nodes", func() {
+			SetChangeImpactEstimator(func(old, new *PerformanceProfile) (*ChangeImpact, error) {
+				return &ChangeImpact{Type: ChangeImpactNone}, nil
+			})
+			Expect(profile.getWarnings(profile.DeepCopy())).To(BeEmpty())
+		})
+
+		It("should warn about the change impact of overlays", func() {
+			var estimatedNew *PerformanceProfile
+			SetChangeImpactEstimator(func(old, new *PerformanceProfile) (*ChangeImpact, error) {
+				estimatedNew = new
+				return &ChangeImpact{Type: ChangeImpactTunedReload, Message: "tuned will be reloaded"}, nil
+			})
+
+			overlay := &PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+				Spec: PerformanceProfileSpec{
+					NodeSelector: profile.Spec.NodeSelector,
+					Overlay:      &Overlay{BaseProfile: profile.Name},
+				},
+			}
+			Expect(overlay.getWarnings(nil)).To(ConsistOf(`performance profile "overlay": tuned will be reloaded`))
+			Expect(estimatedNew).To(Equal(overlay))
+		})
+
+		It("should warn when the estimation fails", func() {
+			SetChangeImpactEstimator(func(old, new *PerformanceProfile) (*ChangeImpact, error) {
+				return nil, fmt.Errorf("failed")
+			})
+			Expect(profile.getWarnings(nil)).To(ConsistOf(`performance profile "test": failed to estimate the impact of the change on nodes: failed`))
+		})
+	})
+	Context("with the CPU topology of nodes", func() {
+		newNode := func(name string, topology string) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: profile.Spec.NodeSelector,
+					Annotations: map[string]string{
+						CPUTopologyAnnotation: topology,
+					},
+				},
+			}
+		}
+
+		It("should return nil topology when the node did not report it", func() {
+			node := newNode("node1", "")
+			node.Annotations = nil
+			topologyInfo, err := GetNodeCPUTopology(node)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(topologyInfo).To(BeNil())
+		})
+
+		It("should reject the malformed topology", func() {
+			_, err := GetNodeCPUTopology(newNode("node1", `{"nodes":[]}`))
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should warn once about split hyperthreading siblings on all nodes", func() {
+			validatorClient = newValidatorClient(
+				newNode("node1", splitSiblingsTopology),
+				newNode("node2", splitSiblingsTopology),
+			)
+
+			Expect(profile.getWarnings(nil)).To(ConsistOf(
+				`performance profile "test": reserved and isolated CPUs split hyperthreading siblings of the same physical cores 0-7 on nodes node1, node2`,
+			))
+		})
+
+		It("should warn about uncovered CPUs and NUMA nodes without reserved CPUs", func() {
+			validatorClient = newValidatorClient(newNode("node1", wholeCoresTopology))
+
+			Expect(profile.getWarnings(nil)).To(ConsistOf(
+				`performance profile "test": online CPUs 8-9 are neither reserved nor isolated on nodes node1`,
+				`performance profile "test": the NUMA node 1 does not have reserved CPUs while the topology policy is single-numa-node on nodes node1`,
+			))
+		})
+
+		It("should check CPUs of the matching node override", func() {
+			isolatedCPUs := CPUSet("4-5")
+			profile.Spec.CPU.NodeOverrides = []CPUNodeOverride{
+				{
+					Name:         "small",
+					NodeSelector: profile.Spec.NodeSelector,
+					Isolated:     &isolatedCPUs,
+				},
+			}
+			validatorClient = newValidatorClient(newNode("node1", wholeCoresTopology))
+
+			Expect(profile.getWarnings(nil)).To(ContainElement(
+				`performance profile "test": online CPUs 6-9 are neither reserved nor isolated on nodes node1`,
+			))
+		})
+
+		It("should check CPUs of the base profile merged with the overlay", func() {
+			isolatedCPUs := CPUSet("4-7")
+			overlay := &PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+				Spec: PerformanceProfileSpec{
+					CPU: &CPU{
+						Isolated: &isolatedCPUs,
+					},
+					MachineConfigPoolSelector: profile.Spec.MachineConfigPoolSelector,
+					NodeSelector:              profile.Spec.NodeSelector,
+					Overlay: &Overlay{
+						BaseProfile: profile.Name,
+					},
+				},
+			}
+			validatorClient = newValidatorClient(newNode("node1", wholeCoresTopology), profile)
+
+			Expect(overlay.getWarnings(nil)).To(ContainElement(
+				`performance profile "overlay": online CPUs 8-9 are neither reserved nor isolated on nodes node1`,
+			))
+
+			// the base profile is checked with its stored overlays
+			validatorClient = newValidatorClient(newNode("node1", wholeCoresTopology), overlay)
+			Expect(profile.getWarnings(nil)).To(ContainElement(
+				`performance profile "test": online CPUs 8-9 are neither reserved nor isolated on nodes node1`,
+			))
+		})
+
+		It("should warn about the invalid profile merged with the overlay", func() {
+			overlay := &PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+				Spec: PerformanceProfileSpec{
+					CPU: &CPU{
+						ReservedCount: pointer.Int32Ptr(2),
+					},
+					MachineConfigPoolSelector: profile.Spec.MachineConfigPoolSelector,
+					NodeSelector:              profile.Spec.NodeSelector,
+					Overlay: &Overlay{
+						BaseProfile: profile.Name,
+					},
+				},
+			}
+			validatorClient = newValidatorClient(newNode("node1", wholeCoresTopology), profile)
+
+			warnings := overlay.getWarnings(nil)
+			Expect(warnings).To(HaveLen(1))
+			Expect(warnings[0]).To(ContainSubstring(`performance profile "overlay": the profile merged with overlays overlay is invalid`))
+		})
+
+		It("should not check nodes without the CPU topology", func() {
+			node := newNode("node1", "")
+			node.Annotations = nil
+			validatorClient = newValidatorClient(node)
+
+			Expect(profile.getWarnings(nil)).To(BeEmpty())
+		})
+	})
+	Context("with the architecture of nodes", func() {
+		newNode := func(name string, arch string, kernelVersion string) *corev1.Node {
+			labels := map[string]string{corev1.LabelArchStable: arch}
+			for key, value := range profile.Spec.NodeSelector {
+				labels[key] = value
+			}
+
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: labels,
+				},
+				Status: corev1.NodeStatus{
+					NodeInfo: corev1.NodeSystemInfo{KernelVersion: kernelVersion},
+				},
+			}
+		}
+
+		BeforeEach(func() {
+			defaultSize := HugePageSize("512M")
+			profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+			profile.Spec.HugePages.Pages = []HugePage{{Size: "512M", Count: 4}}
+		})
+
+		It("should validate huge page sizes against the architecture and the kernel page size of nodes", func() {
+			validatorClient = newValidatorClient(
+				newNode("node1", ArchitectureARM64, "5.14.0-284.el9.aarch64+64k"),
+				newNode("node2", ArchitectureARM64, "5.14.0-284.el9.aarch64+64k"),
+			)
+			Expect(profile.ValidateCreate()).To(Succeed())
+
+			validatorClient = newValidatorClient(newNode("node1", ArchitectureARM64, "5.14.0-284.el9.aarch64"))
+			err := profile.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`on the arm64 architecture with 4K kernel pages`))
+		})
+
+		It("should reject nodes with different architectures and kernel page sizes", func() {
+			validatorClient = newValidatorClient(
+				newNode("node1", ArchitectureARM64, "5.14.0-284.el9.aarch64+64k"),
+				newNode("node2", ArchitectureAMD64, "5.14.0-284.el9.x86_64"),
+			)
+			err := profile.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nodes selected by the profile have different architectures amd64, arm64"))
+
+			validatorClient = newValidatorClient(
+				newNode("node1", ArchitectureARM64, "5.14.0-284.el9.aarch64+64k"),
+				newNode("node2", ArchitectureARM64, "5.14.0-284.el9.aarch64"),
+			)
+			err = profile.ValidateCreate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nodes selected by the profile run kernels with different page sizes 4K, 64K"))
+		})
+
+		It("should not change the profile when nodes do not report the architecture", func() {
+			node := newNode("node1", ArchitectureARM64, "")
+			node.Labels = profile.Spec.NodeSelector
+			Expect(SetNodesArchitecture(profile, []corev1.Node{*node})).To(Succeed())
+			Expect(profile.Annotations).To(BeEmpty())
+		})
+	})
+
+	Context("with the memory of nodes", func() {
+		newNode := func(name string, memory string) *corev1.Node {
+			return &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name,
+					Labels: profile.Spec.NodeSelector,
+				},
+				Status: corev1.NodeStatus{
+					Capacity: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse(memory),
+					},
+				},
+			}
+		}
+
+		It("should reject huge pages that do not fit into the memory of nodes", func() {
+			validatorClient = newValidatorClient(newNode("node1", "3Gi"), newNode("node2", "64Gi"))
+
+			errors := profile.validateHugePagesCapacity()
+			Expect(errors).To(HaveLen(1))
+			Expect(errors[0].Error()).To(ContainSubstring("huge pages take 4Gi, more than the memory capacity of nodes node1"))
+		})
+
+		It("should warn about huge pages that leave too little memory on nodes", func() {
+			validatorClient = newValidatorClient(newNode("node1", "4608Mi"), newNode("node2", "64Gi"))
+
+			Expect(profile.validateHugePagesCapacity()).To(BeEmpty())
+			Expect(profile.getWarnings(nil)).To(ConsistOf(
+				`performance profile "test": huge pages take 4Gi, less than 1Gi of memory remain for the system and workloads on nodes node1`,
+			))
+		})
+	})
+})
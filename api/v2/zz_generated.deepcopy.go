@@ -21,7 +21,9 @@ limitations under the License.
 package v2
 
 import (
-	"github.com/openshift/custom-resource-status/conditions/v1"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -43,6 +45,33 @@ func (in *CPU) DeepCopyInto(out *CPU) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.NodeOverrides != nil {
+		in, out := &in.NodeOverrides, &out.NodeOverrides
+		*out = make([]CPUNodeOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReservedCount != nil {
+		in, out := &in.ReservedCount, &out.ReservedCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PlacementPolicy != nil {
+		in, out := &in.PlacementPolicy, &out.PlacementPolicy
+		*out = new(CPUPlacementPolicy)
+		**out = **in
+	}
+	if in.Vendor != nil {
+		in, out := &in.Vendor, &out.Vendor
+		*out = new(CPUVendor)
+		**out = **in
+	}
+	if in.ManagerPolicyOptions != nil {
+		in, out := &in.ManagerPolicyOptions, &out.ManagerPolicyOptions
+		*out = make([]CPUManagerPolicyOption, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPU.
@@ -55,6 +84,129 @@ func (in *CPU) DeepCopy() *CPU {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUNodeOverride) DeepCopyInto(out *CPUNodeOverride) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Isolated != nil {
+		in, out := &in.Isolated, &out.Isolated
+		*out = new(CPUSet)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUNodeOverride.
+func (in *CPUNodeOverride) DeepCopy() *CPUNodeOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUNodeOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUPowerSettings) DeepCopyInto(out *CPUPowerSettings) {
+	*out = *in
+	if in.Governor != nil {
+		in, out := &in.Governor, &out.Governor
+		*out = new(CPUGovernor)
+		**out = **in
+	}
+	if in.MaxCState != nil {
+		in, out := &in.MaxCState, &out.MaxCState
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUPowerSettings.
+func (in *CPUPowerSettings) DeepCopy() *CPUPowerSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUPowerSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CPUStatus) DeepCopyInto(out *CPUStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CPUStatus.
+func (in *CPUStatus) DeepCopy() *CPUStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CPUStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeImpact) DeepCopyInto(out *ChangeImpact) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeImpact.
+func (in *ChangeImpact) DeepCopy() *ChangeImpact {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeImpact)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentChanges) DeepCopyInto(out *ComponentChanges) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentChanges.
+func (in *ComponentChanges) DeepCopy() *ComponentChanges {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentChanges)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentStatus) DeepCopyInto(out *ComponentStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentStatus.
+func (in *ComponentStatus) DeepCopy() *ComponentStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Device) DeepCopyInto(out *Device) {
 	*out = *in
@@ -85,6 +237,21 @@ func (in *Device) DeepCopy() *Device {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HardwareReference) DeepCopyInto(out *HardwareReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HardwareReference.
+func (in *HardwareReference) DeepCopy() *HardwareReference {
+	if in == nil {
+		return nil
+	}
+	out := new(HardwareReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HugePage) DeepCopyInto(out *HugePage) {
 	*out = *in
@@ -100,81 +267,515 @@ func (in *HugePage) DeepCopy() *HugePage {
 	if in == nil {
 		return nil
 	}
-	out := new(HugePage)
+	out := new(HugePage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePages) DeepCopyInto(out *HugePages) {
+	*out = *in
+	if in.DefaultHugePagesSize != nil {
+		in, out := &in.DefaultHugePagesSize, &out.DefaultHugePagesSize
+		*out = new(HugePageSize)
+		**out = **in
+	}
+	if in.Pages != nil {
+		in, out := &in.Pages, &out.Pages
+		*out = make([]HugePage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeAllocation != nil {
+		in, out := &in.RuntimeAllocation, &out.RuntimeAllocation
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePages.
+func (in *HugePages) DeepCopy() *HugePages {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePagesArchitecture) DeepCopyInto(out *HugePagesArchitecture) {
+	*out = *in
+	if in.Sizes != nil {
+		in, out := &in.Sizes, &out.Sizes
+		*out = make([]HugePageSize, len(*in))
+		copy(*out, *in)
+	}
+	if in.RegisteredSizes != nil {
+		in, out := &in.RegisteredSizes, &out.RegisteredSizes
+		*out = make([]HugePageSize, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePagesArchitecture.
+func (in *HugePagesArchitecture) DeepCopy() *HugePagesArchitecture {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePagesArchitecture)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePagesRuntimeAllocationStatus) DeepCopyInto(out *HugePagesRuntimeAllocationStatus) {
+	*out = *in
+	if in.Pages != nil {
+		in, out := &in.Pages, &out.Pages
+		*out = make([]HugePage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeHugePagesAllocation, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePagesRuntimeAllocationStatus.
+func (in *HugePagesRuntimeAllocationStatus) DeepCopy() *HugePagesRuntimeAllocationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePagesRuntimeAllocationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HugePagesStatus) DeepCopyInto(out *HugePagesStatus) {
+	*out = *in
+	out.Total = in.Total.DeepCopy()
+	if in.Distributed != nil {
+		in, out := &in.Distributed, &out.Distributed
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.NUMANodes != nil {
+		in, out := &in.NUMANodes, &out.NUMANodes
+		*out = make([]NUMAHugePagesStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RuntimeAllocation != nil {
+		in, out := &in.RuntimeAllocation, &out.RuntimeAllocation
+		*out = new(HugePagesRuntimeAllocationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePagesStatus.
+func (in *HugePagesStatus) DeepCopy() *HugePagesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HugePagesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelThreads) DeepCopyInto(out *KernelThreads) {
+	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]KernelThreadsGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RCUOffload != nil {
+		in, out := &in.RCUOffload, &out.RCUOffload
+		*out = new(RCUOffloadMode)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelThreads.
+func (in *KernelThreads) DeepCopy() *KernelThreads {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelThreads)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KernelThreadsGroup) DeepCopyInto(out *KernelThreadsGroup) {
+	*out = *in
+	if in.Regex != nil {
+		in, out := &in.Regex, &out.Regex
+		*out = new(string)
+		**out = **in
+	}
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(SchedulingPolicy)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(KernelThreadsAffinity)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelThreadsGroup.
+func (in *KernelThreadsGroup) DeepCopy() *KernelThreadsGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelThreadsGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kubelet) DeepCopyInto(out *Kubelet) {
+	*out = *in
+	if in.KubeReserved != nil {
+		in, out := &in.KubeReserved, &out.KubeReserved
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.SystemReserved != nil {
+		in, out := &in.SystemReserved, &out.SystemReserved
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.EvictionHard != nil {
+		in, out := &in.EvictionHard, &out.EvictionHard
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.CPUManagerReconcilePeriod != nil {
+		in, out := &in.CPUManagerReconcilePeriod, &out.CPUManagerReconcilePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MemoryManager != nil {
+		in, out := &in.MemoryManager, &out.MemoryManager
+		*out = new(MemoryManager)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kubelet.
+func (in *Kubelet) DeepCopy() *Kubelet {
+	if in == nil {
+		return nil
+	}
+	out := new(Kubelet)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]Weekday, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MemoryManager) DeepCopyInto(out *MemoryManager) {
+	*out = *in
+	if in.Policy != nil {
+		in, out := &in.Policy, &out.Policy
+		*out = new(MemoryManagerPolicy)
+		**out = **in
+	}
+	if in.ReservedMemory != nil {
+		in, out := &in.ReservedMemory, &out.ReservedMemory
+		*out = make([]NUMAReservedMemory, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MemoryManager.
+func (in *MemoryManager) DeepCopy() *MemoryManager {
+	if in == nil {
+		return nil
+	}
+	out := new(MemoryManager)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMA) DeepCopyInto(out *NUMA) {
+	*out = *in
+	if in.TopologyPolicy != nil {
+		in, out := &in.TopologyPolicy, &out.TopologyPolicy
+		*out = new(string)
+		**out = **in
+	}
+	if in.TopologyManagerScope != nil {
+		in, out := &in.TopologyManagerScope, &out.TopologyManagerScope
+		*out = new(TopologyManagerScope)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMA.
+func (in *NUMA) DeepCopy() *NUMA {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMAHugePagesStatus) DeepCopyInto(out *NUMAHugePagesStatus) {
+	*out = *in
+	out.Memory = in.Memory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMAHugePagesStatus.
+func (in *NUMAHugePagesStatus) DeepCopy() *NUMAHugePagesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMAHugePagesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NUMAReservedMemory) DeepCopyInto(out *NUMAReservedMemory) {
+	*out = *in
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMAReservedMemory.
+func (in *NUMAReservedMemory) DeepCopy() *NUMAReservedMemory {
+	if in == nil {
+		return nil
+	}
+	out := new(NUMAReservedMemory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Net) DeepCopyInto(out *Net) {
+	*out = *in
+	if in.UserLevelNetworking != nil {
+		in, out := &in.UserLevelNetworking, &out.UserLevelNetworking
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]Device, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Net.
+func (in *Net) DeepCopy() *Net {
+	if in == nil {
+		return nil
+	}
+	out := new(Net)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeHugePagesAllocation) DeepCopyInto(out *NodeHugePagesAllocation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeHugePagesAllocation.
+func (in *NodeHugePagesAllocation) DeepCopy() *NodeHugePagesAllocation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeHugePagesAllocation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodesStatus) DeepCopyInto(out *NodesStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodesStatus.
+func (in *NodesStatus) DeepCopy() *NodesStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodesStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Overlay) DeepCopyInto(out *Overlay) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Overlay.
+func (in *Overlay) DeepCopy() *Overlay {
+	if in == nil {
+		return nil
+	}
+	out := new(Overlay)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HugePages) DeepCopyInto(out *HugePages) {
+func (in *OverlayConflict) DeepCopyInto(out *OverlayConflict) {
 	*out = *in
-	if in.DefaultHugePagesSize != nil {
-		in, out := &in.DefaultHugePagesSize, &out.DefaultHugePagesSize
-		*out = new(HugePageSize)
-		**out = **in
-	}
-	if in.Pages != nil {
-		in, out := &in.Pages, &out.Pages
-		*out = make([]HugePage, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Profiles != nil {
+		in, out := &in.Profiles, &out.Profiles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HugePages.
-func (in *HugePages) DeepCopy() *HugePages {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverlayConflict.
+func (in *OverlayConflict) DeepCopy() *OverlayConflict {
 	if in == nil {
 		return nil
 	}
-	out := new(HugePages)
+	out := new(OverlayConflict)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NUMA) DeepCopyInto(out *NUMA) {
+func (in *OverlaysStatus) DeepCopyInto(out *OverlaysStatus) {
 	*out = *in
-	if in.TopologyPolicy != nil {
-		in, out := &in.TopologyPolicy, &out.TopologyPolicy
-		*out = new(string)
-		**out = **in
+	if in.Applied != nil {
+		in, out := &in.Applied, &out.Applied
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conflicts != nil {
+		in, out := &in.Conflicts, &out.Conflicts
+		*out = make([]OverlayConflict, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Ignored != nil {
+		in, out := &in.Ignored, &out.Ignored
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NUMA.
-func (in *NUMA) DeepCopy() *NUMA {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverlaysStatus.
+func (in *OverlaysStatus) DeepCopy() *OverlaysStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NUMA)
+	out := new(OverlaysStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Net) DeepCopyInto(out *Net) {
+func (in *PendingChange) DeepCopyInto(out *PendingChange) {
 	*out = *in
-	if in.UserLevelNetworking != nil {
-		in, out := &in.UserLevelNetworking, &out.UserLevelNetworking
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Devices != nil {
-		in, out := &in.Devices, &out.Devices
-		*out = make([]Device, len(*in))
+	in.Impact.DeepCopyInto(&out.Impact)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]ComponentChanges, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Net.
-func (in *Net) DeepCopy() *Net {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingChange.
+func (in *PendingChange) DeepCopy() *PendingChange {
 	if in == nil {
 		return nil
 	}
-	out := new(Net)
+	out := new(PendingChange)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -287,6 +888,16 @@ func (in *PerformanceProfileSpec) DeepCopyInto(out *PerformanceProfileSpec) {
 		*out = new(NUMA)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Kubelet != nil {
+		in, out := &in.Kubelet, &out.Kubelet
+		*out = new(Kubelet)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KubeletConfigOverrides != nil {
+		in, out := &in.KubeletConfigOverrides, &out.KubeletConfigOverrides
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Net != nil {
 		in, out := &in.Net, &out.Net
 		*out = new(Net)
@@ -297,6 +908,53 @@ func (in *PerformanceProfileSpec) DeepCopyInto(out *PerformanceProfileSpec) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.PowerPolicy != nil {
+		in, out := &in.PowerPolicy, &out.PowerPolicy
+		*out = new(PowerPolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RuntimeClass != nil {
+		in, out := &in.RuntimeClass, &out.RuntimeClass
+		*out = new(RuntimeClass)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Stalld != nil {
+		in, out := &in.Stalld, &out.Stalld
+		*out = new(Stalld)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KernelThreads != nil {
+		in, out := &in.KernelThreads, &out.KernelThreads
+		*out = new(KernelThreads)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tuning != nil {
+		in, out := &in.Tuning, &out.Tuning
+		*out = new(Tuning)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TunedProfiles != nil {
+		in, out := &in.TunedProfiles, &out.TunedProfiles
+		*out = make([]TunedProfileFragment, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(Rollout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overlay != nil {
+		in, out := &in.Overlay, &out.Overlay
+		*out = new(Overlay)
+		**out = **in
+	}
+	if in.HardwareRef != nil {
+		in, out := &in.HardwareRef, &out.HardwareRef
+		*out = new(HardwareReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceProfileSpec.
@@ -314,7 +972,7 @@ func (in *PerformanceProfileStatus) DeepCopyInto(out *PerformanceProfileStatus)
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+		*out = make([]conditionsv1.Condition, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -329,6 +987,46 @@ func (in *PerformanceProfileStatus) DeepCopyInto(out *PerformanceProfileStatus)
 		*out = new(string)
 		**out = **in
 	}
+	if in.LastChangeImpact != nil {
+		in, out := &in.LastChangeImpact, &out.LastChangeImpact
+		*out = new(ChangeImpact)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PendingChange != nil {
+		in, out := &in.PendingChange, &out.PendingChange
+		*out = new(PendingChange)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]ComponentStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(NodesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Overlays != nil {
+		in, out := &in.Overlays, &out.Overlays
+		*out = new(OverlaysStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CPU != nil {
+		in, out := &in.CPU, &out.CPU
+		*out = new(CPUStatus)
+		**out = **in
+	}
+	if in.HugePages != nil {
+		in, out := &in.HugePages, &out.HugePages
+		*out = new(HugePagesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tuning != nil {
+		in, out := &in.Tuning, &out.Tuning
+		*out = new(TuningStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceProfileStatus.
@@ -341,6 +1039,36 @@ func (in *PerformanceProfileStatus) DeepCopy() *PerformanceProfileStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PowerPolicy) DeepCopyInto(out *PowerPolicy) {
+	*out = *in
+	if in.Mode != nil {
+		in, out := &in.Mode, &out.Mode
+		*out = new(PowerMode)
+		**out = **in
+	}
+	if in.Reserved != nil {
+		in, out := &in.Reserved, &out.Reserved
+		*out = new(CPUPowerSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Isolated != nil {
+		in, out := &in.Isolated, &out.Isolated
+		*out = new(CPUPowerSettings)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PowerPolicy.
+func (in *PowerPolicy) DeepCopy() *PowerPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PowerPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RealTimeKernel) DeepCopyInto(out *RealTimeKernel) {
 	*out = *in
@@ -360,3 +1088,217 @@ func (in *RealTimeKernel) DeepCopy() *RealTimeKernel {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Rollout) DeepCopyInto(out *Rollout) {
+	*out = *in
+	if in.Strategy != nil {
+		in, out := &in.Strategy, &out.Strategy
+		*out = new(RolloutStrategy)
+		**out = **in
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HoldAllChanges != nil {
+		in, out := &in.HoldAllChanges, &out.HoldAllChanges
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Rollout.
+func (in *Rollout) DeepCopy() *Rollout {
+	if in == nil {
+		return nil
+	}
+	out := new(Rollout)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RuntimeClass) DeepCopyInto(out *RuntimeClass) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Handler != nil {
+		in, out := &in.Handler, &out.Handler
+		*out = new(string)
+		**out = **in
+	}
+	if in.Overhead != nil {
+		in, out := &in.Overhead, &out.Overhead
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RuntimeClass.
+func (in *RuntimeClass) DeepCopy() *RuntimeClass {
+	if in == nil {
+		return nil
+	}
+	out := new(RuntimeClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Stalld) DeepCopyInto(out *Stalld) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CPUs != nil {
+		in, out := &in.CPUs, &out.CPUs
+		*out = new(CPUSet)
+		**out = **in
+	}
+	if in.BoostPeriod != nil {
+		in, out := &in.BoostPeriod, &out.BoostPeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.BoostRuntime != nil {
+		in, out := &in.BoostRuntime, &out.BoostRuntime
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StarvingThreshold != nil {
+		in, out := &in.StarvingThreshold, &out.StarvingThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Stalld.
+func (in *Stalld) DeepCopy() *Stalld {
+	if in == nil {
+		return nil
+	}
+	out := new(Stalld)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunedProfileConfigMapReference) DeepCopyInto(out *TunedProfileConfigMapReference) {
+	*out = *in
+	if in.Key != nil {
+		in, out := &in.Key, &out.Key
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunedProfileConfigMapReference.
+func (in *TunedProfileConfigMapReference) DeepCopy() *TunedProfileConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TunedProfileConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunedProfileFragment) DeepCopyInto(out *TunedProfileFragment) {
+	*out = *in
+	if in.Data != nil {
+		in, out := &in.Data, &out.Data
+		*out = new(string)
+		**out = **in
+	}
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(TunedProfileConfigMapReference)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunedProfileFragment.
+func (in *TunedProfileFragment) DeepCopy() *TunedProfileFragment {
+	if in == nil {
+		return nil
+	}
+	out := new(TunedProfileFragment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Tuning) DeepCopyInto(out *Tuning) {
+	*out = *in
+	if in.Sysctl != nil {
+		in, out := &in.Sysctl, &out.Sysctl
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysfs != nil {
+		in, out := &in.Sysfs, &out.Sysfs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Tuning.
+func (in *Tuning) DeepCopy() *Tuning {
+	if in == nil {
+		return nil
+	}
+	out := new(Tuning)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TuningStatus) DeepCopyInto(out *TuningStatus) {
+	*out = *in
+	if in.Sysctl != nil {
+		in, out := &in.Sysctl, &out.Sysctl
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Sysfs != nil {
+		in, out := &in.Sysfs, &out.Sysfs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TuningStatus.
+func (in *TuningStatus) DeepCopy() *TuningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TuningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,88 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+# reports huge pages allocated on NUMA nodes under the node annotation read by the operator, the node
+# patches its own node object with the kubelet client certificate
+
+annotation="performance.openshift.io/hugepages-allocation"
+nodes_path="/sys/devices/system/node"
+kubeconfig="/var/lib/kubelet/kubeconfig"
+client_cert="/var/lib/kubelet/pki/kubelet-client-current.pem"
+ca_cert="$(mktemp)"
+trap 'rm -f "${ca_cert}"' EXIT
+
+# huge pages are read every interval and reported when they change, the report is repeated every heartbeat,
+# so the operator can tell nodes that failed to allocate huge pages from nodes that did not report yet, the operator
+# compares only huge pages of reports, so the heartbeat does not trigger the reconcile
+interval=10
+heartbeat=300
+
+server=$(sed -n 's/^ *server: *//p' "${kubeconfig}" | head -n 1)
+sed -n 's/^ *certificate-authority-data: *//p' "${kubeconfig}" | head -n 1 | base64 -d >"${ca_cert}"
+node_name=$(openssl x509 -in "${client_cert}" -noout -subject | sed -n 's/.*CN *= *system:node:\([^,/]*\).*/\1/p')
+
+if [ -z "${server}" ] || [ -z "${node_name}" ]; then
+  echo "ERROR: failed to read the API server or the node name from ${kubeconfig} and ${client_cert}"
+  exit 1
+fi
+
+# format_size converts the size in kilobytes to the huge page size of the performance profile, for example 2M
+format_size() {
+  local size_kb=$1
+  if ((size_kb % 1048576 == 0)); then
+    echo "$((size_kb / 1048576))G"
+  elif ((size_kb % 1024 == 0)); then
+    echo "$((size_kb / 1024))M"
+  else
+    echo "${size_kb}K"
+  fi
+}
+
+# get_pages prints huge pages allocated on NUMA nodes as a JSON list
+get_pages() {
+  local pages=()
+  local file node size count
+  for file in "${nodes_path}"/node*/hugepages/hugepages-*kB/nr_hugepages; do
+    [ -f "${file}" ] || continue
+
+    count=$(cat "${file}")
+    [ "${count}" -ne 0 ] || continue
+
+    node=${file#${nodes_path}/node}
+    node=${node%%/*}
+    size=${file##*/hugepages-}
+    size=${size%%kB/*}
+    pages+=("{\"size\":\"$(format_size "${size}")\",\"node\":${node},\"count\":${count}}")
+  done
+
+  local IFS=,
+  echo "[${pages[*]}]"
+}
+
+# report patches the node annotation with huge pages and the time they were read
+report() {
+  local value patch
+  value="{\"timestamp\":\"$(date -u +%Y-%m-%dT%H:%M:%SZ)\",\"pages\":$1}"
+  patch="{\"metadata\":{\"annotations\":{\"${annotation}\":\"${value//\"/\\\"}\"}}}"
+
+  curl --silent --show-error --fail --output /dev/null \
+    --cacert "${ca_cert}" --cert "${client_cert}" --key "${client_cert}" \
+    --request PATCH --header "Content-Type: application/merge-patch+json" --data "${patch}" \
+    "${server}/api/v1/nodes/${node_name}"
+}
+
+last_pages=""
+last_report=0
+while true; do
+  pages=$(get_pages)
+  now=$(date +%s)
+  if [ "${pages}" != "${last_pages}" ] || [ $((now - last_report)) -ge ${heartbeat} ]; then
+    if report "${pages}"; then
+      last_pages=${pages}
+      last_report=${now}
+    fi
+  fi
+
+  sleep ${interval}
+done
@@ -58,6 +58,7 @@ type ProfileData struct {
 	additionalKernelArgs       []string
 	userLevelNetworking        *bool
 	disableHT                  bool
+	cpuVendor                  string
 }
 
 // ClusterData collects the cluster wide information, each mcp points to a list of ghw node handlers
@@ -422,7 +423,12 @@ func getProfileData(args ProfileCreatorArgs, cluster ClusterData) (*ProfileData,
 	}
 	log.Infof("%d reserved CPUs allocated: %v ", reservedCPUs.Size(), reservedCPUs.String())
 	log.Infof("%d isolated CPUs allocated: %v", isolatedCPUs.Size(), isolatedCPUs.String())
-	kernelArgs := profilecreator.GetAdditionalKernelArgs(args.PowerConsumptionMode, args.DisableHT)
+	cpuVendor, err := nodeHandle.GetCPUVendor()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the CPU vendor: %v", err)
+	}
+	log.Infof("CPU vendor of nodes: %s", cpuVendor)
+	kernelArgs := profilecreator.GetAdditionalKernelArgs(args.PowerConsumptionMode, args.DisableHT, cpuVendor)
 	profileData := &ProfileData{
 		reservedCPUs:           reservedCPUs.String(),
 		isolatedCPUs:           isolatedCPUs.String(),
@@ -433,6 +439,7 @@ func getProfileData(args ProfileCreatorArgs, cluster ClusterData) (*ProfileData,
 		rtKernel:               args.RTKernel,
 		additionalKernelArgs:   kernelArgs,
 		userLevelNetworking:    args.UserLevelNetworking,
+		cpuVendor:              cpuVendor,
 	}
 	return profileData, nil
 }
@@ -498,6 +505,12 @@ func createProfile(profileData ProfileData) {
 		},
 	}
 
+	// Intel is the default CPU vendor of the profile
+	if profileData.cpuVendor != profilecreator.CPUVendorIntel {
+		cpuVendor := performancev2.CPUVendor(profileData.cpuVendor)
+		profile.Spec.CPU.Vendor = &cpuVendor
+	}
+
 	if profileData.userLevelNetworking != nil {
 		profile.Spec.Net = &performancev2.Net{
 			UserLevelNetworking: profileData.userLevelNetworking,
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"context"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/manifestset"
+	profileutil "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
+)
+
+// GetChangeImpact returns the impact on nodes of the change between the old and the new profiles, the nil old
+// profile means that the profile is created, components are rendered from the base profile merged with its
+// overlays and completed with data of nodes and ConfigMaps the same way the reconcile renders them, so the
+// change of the overlay is estimated for its base profile
+func (r *PerformanceProfileReconciler) GetChangeImpact(old, new *performancev2.PerformanceProfile) (*performancev2.ChangeImpact, error) {
+	profiles := &performancev2.PerformanceProfileList{}
+	if err := r.List(context.TODO(), profiles); err != nil {
+		return nil, err
+	}
+
+	// the changed profile replaces its stored version, overlays that are being deleted are not merged anymore
+	var stored []performancev2.PerformanceProfile
+	for _, p := range profiles.Items {
+		if p.Name != new.Name && p.DeletionTimestamp == nil {
+			stored = append(stored, p)
+		}
+	}
+
+	baseName := new.Name
+	if profileutil.IsOverlay(new) {
+		baseName = new.Spec.Overlay.BaseProfile
+	}
+
+	oldEffective, err := r.getChangedEffectiveProfile(baseName, old, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	newEffective, err := r.getChangedEffectiveProfile(baseName, new, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	// the overlay of the base profile that does not exist does not change any components
+	if newEffective == nil {
+		return manifestset.GetChangeImpact(&manifestset.ManifestResultSetDiff{}), nil
+	}
+	return manifestset.GetProfileChangeImpact(oldEffective, newEffective, r.AssetsDir)
+}
+
+// getChangedEffectiveProfile returns the base profile merged with overlays and completed for the render,
+// the changed profile is added to stored profiles unless it is nil, the nil value means that the base
+// profile does not exist
+func (r *PerformanceProfileReconciler) getChangedEffectiveProfile(baseName string, changed *performancev2.PerformanceProfile, stored []performancev2.PerformanceProfile) (*performancev2.PerformanceProfile, error) {
+	profiles := append([]performancev2.PerformanceProfile{}, stored...)
+	if changed != nil {
+		profiles = append(profiles, *changed)
+	}
+
+	var base *performancev2.PerformanceProfile
+	for i := range profiles {
+		if profiles[i].Name == baseName && !profileutil.IsOverlay(&profiles[i]) {
+			base = &profiles[i]
+		}
+	}
+
+	if base == nil {
+		return nil, nil
+	}
+
+	effective, _, err := performancev2.GetEffectiveProfile(base, performancev2.GetOverlays(base, profiles))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.completeEffectiveProfile(effective); err != nil {
+		return nil, err
+	}
+	return effective, nil
+}
+
+// completeEffectiveProfile sets data of nodes and ConfigMaps used by the render into the effective profile,
+// like the reconcile does, except for huge pages allocated at runtime, their fallback to boot is taken from
+// the profile status
+func (r *PerformanceProfileReconciler) completeEffectiveProfile(effective *performancev2.PerformanceProfile) error {
+	cpus, err := r.getComputedCPUs(effective)
+	if err != nil {
+		return err
+	}
+
+	if cpus != nil {
+		profileutil.SetComputedCPUs(effective, cpus)
+	}
+
+	if err := r.setNodesArchitecture(effective); err != nil {
+		return err
+	}
+
+	if profileutil.IsReservedMemoryDerived(effective) {
+		reservedMemory, err := r.getReservedMemory(effective)
+		if err != nil {
+			return err
+		}
+		profileutil.SetReservedMemory(effective, reservedMemory)
+	}
+
+	if profileutil.IsTunedProfilesReferenced(effective) {
+		tunedProfilesData, err := r.getTunedProfilesData(effective)
+		if err != nil {
+			return err
+		}
+		profileutil.SetTunedProfilesData(effective, tunedProfilesData)
+	}
+
+	if profileutil.IsHugePagesFallbackToBoot(effective) {
+		profileutil.DisableHugePagesRuntimeAllocation(effective)
+	}
+	return nil
+}
@@ -18,7 +18,10 @@ package controllers
 
 import (
 	"context"
+	"fmt"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
@@ -26,6 +29,8 @@ import (
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/machineconfig"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/manifestset"
 	profileutil "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/tuned"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/metrics"
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
@@ -38,10 +43,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog"
 
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -53,17 +60,69 @@ import (
 
 const finalizer = "foreground-deletion"
 
+// hugePagesAllocationTimeout is the time given to nodes to allocate huge pages at runtime after they were changed
+const hugePagesAllocationTimeout = 2 * time.Minute
+
+// hugePagesAllocationRequeuePeriod is the period of checking nodes that did not allocate all huge pages yet,
+// periodic reports of nodes do not trigger the reconcile when huge pages do not change
+const hugePagesAllocationRequeuePeriod = time.Minute
+
+// applyResult describes the outcome of the components apply
+type applyResult struct {
+	// changeImpact is set when components were updated
+	changeImpact *performancev2.ChangeImpact
+	// pendingChange is set when the components update is held by the rollout policy
+	pendingChange *performancev2.PendingChange
+	// componentStatuses contains the sync state of the generated components
+	componentStatuses []performancev2.ComponentStatus
+	// overlays describes overlays merged into the profile
+	overlays *performancev2.OverlaysStatus
+	// cpus contains reserved and isolated CPUs computed from the node topology
+	cpus *performancev2.CPUStatus
+	// hugePages describes the memory taken by huge pages
+	hugePages *performancev2.HugePagesStatus
+	// tuning contains sysctl and sysfs settings of the applied tuned profile
+	tuning *performancev2.TuningStatus
+}
+
 // PerformanceProfileReconciler reconciles a PerformanceProfile object
 type PerformanceProfileReconciler struct {
 	client.Client
 	Scheme    *runtime.Scheme
 	Recorder  record.EventRecorder
 	AssetsDir string
+	// ConfigMapReader reads ConfigMaps of the operator namespace referenced by performance profiles,
+	// it is set to the cache of the operator namespace when the controller is added to the manager
+	ConfigMapReader client.Reader
+	// PodReader reads CPU topology discovery pods of the operator namespace, it is set to the cache
+	// of the operator namespace as well
+	PodReader client.Reader
+	// OperatorImage is the image of the operator used by CPU topology discovery pods, the CPU topology
+	// of nodes is not discovered when it is empty
+	OperatorImage string
+	// Namespace is the namespace the operator runs in, discovery pods run under it and the namespace
+	// cache of ConfigMapReader and PodReader is restricted to it
+	Namespace string
 }
 
 // SetupWithManager creates a new PerformanceProfile Controller and adds it to the Manager.
 // The Manager will set fields on the Controller and Start it when the Manager is Started.
 func (r *PerformanceProfileReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// the manager cache watches cluster wide resources, referenced ConfigMaps and discovery pods are restricted
+	// to the operator namespace, so they are watched by a separate cache to avoid watching the whole cluster
+	namespaceCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme:    mgr.GetScheme(),
+		Mapper:    mgr.GetRESTMapper(),
+		Namespace: r.Namespace,
+	})
+	if err != nil {
+		return err
+	}
+	if err := mgr.Add(namespaceCache); err != nil {
+		return err
+	}
+	r.ConfigMapReader = namespaceCache
+	r.PodReader = namespaceCache
 
 	// we want to initate reconcile loop only on change under labels or spec of the object
 	p := predicate.Funcs{
@@ -99,11 +158,71 @@ func (r *PerformanceProfileReconciler) SetupWithManager(mgr ctrl.Manager) error
 			mcpOld := e.ObjectOld.(*mcov1.MachineConfigPool)
 			mcpNew := e.ObjectNew.(*mcov1.MachineConfigPool)
 
-			return !reflect.DeepEqual(mcpOld.Status.Conditions, mcpNew.Status.Conditions)
+			// machine counts change when nodes finish the update, it is needed to track the nodes rollout state
+			return !reflect.DeepEqual(mcpOld.Status.Conditions, mcpNew.Status.Conditions) ||
+				mcpOld.Spec.Configuration.Name != mcpNew.Spec.Configuration.Name ||
+				mcpOld.Status.UpdatedMachineCount != mcpNew.Status.UpdatedMachineCount ||
+				mcpOld.Status.DegradedMachineCount != mcpNew.Status.DegradedMachineCount
+		},
+	}
+
+	tunedProfilePredicates := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !validateUpdateEvent(&e) {
+				return false
+			}
+
+			tunedProfileOld := e.ObjectOld.(*tunedv1.Profile)
+			tunedProfileNew := e.ObjectNew.(*tunedv1.Profile)
+
+			return !reflect.DeepEqual(tunedProfileOld.Status.Conditions, tunedProfileNew.Status.Conditions)
 		},
 	}
 
-	err := ctrl.NewControllerManagedBy(mgr).
+	// nodes report the CPU topology used to compute reserved and isolated CPUs, huge pages allocated at runtime
+	// and the kernel version that selects huge page sizes of arm64 nodes
+	nodePredicates := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !validateUpdateEvent(&e) {
+				return false
+			}
+
+			return e.ObjectOld.GetAnnotations()[performancev2.CPUTopologyAnnotation] != e.ObjectNew.GetAnnotations()[performancev2.CPUTopologyAnnotation] ||
+				profileutil.IsNodeHugePagesAllocationChanged(e.ObjectOld.(*corev1.Node), e.ObjectNew.(*corev1.Node)) ||
+				e.ObjectOld.(*corev1.Node).Status.NodeInfo.KernelVersion != e.ObjectNew.(*corev1.Node).Status.NodeInfo.KernelVersion ||
+				!apiequality.Semantic.DeepEqual(e.ObjectNew.GetLabels(), e.ObjectOld.GetLabels())
+		},
+	}
+
+	// ConfigMaps contain user tuned profiles included into the generated tuned
+	configMapPredicates := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !validateUpdateEvent(&e) {
+				return false
+			}
+
+			configMapOld := e.ObjectOld.(*corev1.ConfigMap)
+			configMapNew := e.ObjectNew.(*corev1.ConfigMap)
+
+			return !reflect.DeepEqual(configMapOld.Data, configMapNew.Data)
+		},
+	}
+
+	// CPU topology discovery pods report the topology when they complete
+	podPredicates := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if !validateUpdateEvent(&e) {
+				return false
+			}
+
+			podOld := e.ObjectOld.(*corev1.Pod)
+			podNew := e.ObjectNew.(*corev1.Pod)
+
+			return podOld.Status.Phase != podNew.Status.Phase
+		},
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
 		For(&performancev2.PerformanceProfile{}).
 		Owns(&mcov1.MachineConfig{}, builder.WithPredicates(p)).
 		Owns(&mcov1.KubeletConfig{}, builder.WithPredicates(kubeletPredicates)).
@@ -113,6 +232,26 @@ func (r *PerformanceProfileReconciler) SetupWithManager(mgr ctrl.Manager) error
 			&source.Kind{Type: &mcov1.MachineConfigPool{}},
 			handler.EnqueueRequestsFromMapFunc(r.mcpToPerformanceProfile),
 			builder.WithPredicates(mcpPredicates)).
+		Watches(
+			&source.Kind{Type: &performancev2.PerformanceProfile{}},
+			r.getOverlayEventHandler(),
+			builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(
+			&source.Kind{Type: &tunedv1.Profile{}},
+			handler.EnqueueRequestsFromMapFunc(r.tunedProfileToPerformanceProfile),
+			builder.WithPredicates(tunedProfilePredicates)).
+		Watches(
+			&source.Kind{Type: &corev1.Node{}},
+			handler.EnqueueRequestsFromMapFunc(r.nodeToPerformanceProfile),
+			builder.WithPredicates(nodePredicates)).
+		Watches(
+			source.NewKindWithCache(&corev1.ConfigMap{}, namespaceCache),
+			handler.EnqueueRequestsFromMapFunc(r.configMapToPerformanceProfile),
+			builder.WithPredicates(configMapPredicates)).
+		Watches(
+			source.NewKindWithCache(&corev1.Pod{}, namespaceCache),
+			&handler.EnqueueRequestForOwner{OwnerType: &performancev2.PerformanceProfile{}, IsController: true},
+			builder.WithPredicates(podPredicates)).
 		Complete(r)
 	if err != nil {
 		return err
@@ -155,6 +294,111 @@ func (r *PerformanceProfileReconciler) mcpToPerformanceProfile(mcpObj client.Obj
 	return requests
 }
 
+// overlayToBaseProfile maps the overlay profile to its base profile
+func (r *PerformanceProfileReconciler) overlayToBaseProfile(profileObj client.Object) []reconcile.Request {
+	profile, ok := profileObj.(*performancev2.PerformanceProfile)
+	if !ok || !profileutil.IsOverlay(profile) {
+		return nil
+	}
+
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{Name: profile.Spec.Overlay.BaseProfile},
+		},
+	}
+}
+
+// getOverlayEventHandler returns the handler that enqueues base profiles of overlays, when the overlay changes
+// its base profile, the old base profile is enqueued as well, so it stops merging the overlay
+func (r *PerformanceProfileReconciler) getOverlayEventHandler() handler.EventHandler {
+	enqueue := func(q workqueue.RateLimitingInterface, objs ...client.Object) {
+		for _, obj := range objs {
+			for _, request := range r.overlayToBaseProfile(obj) {
+				q.Add(request)
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent, q workqueue.RateLimitingInterface) {
+			enqueue(q, e.Object)
+		},
+	}
+}
+
+// tunedProfileToPerformanceProfile maps the tuned profile to performance profiles that select its node,
+// the tuned profile has the same name as the node
+func (r *PerformanceProfileReconciler) tunedProfileToPerformanceProfile(tunedProfileObj client.Object) []reconcile.Request {
+	node := &corev1.Node{}
+	if err := r.Get(context.TODO(), types.NamespacedName{Name: tunedProfileObj.GetName()}, node); err != nil {
+		if !k8serros.IsNotFound(err) {
+			klog.Errorf("failed to get the node %q", tunedProfileObj.GetName())
+		}
+		return nil
+	}
+
+	return r.nodeToPerformanceProfile(node)
+}
+
+// nodeToPerformanceProfile maps the node to performance profiles that select it
+func (r *PerformanceProfileReconciler) nodeToPerformanceProfile(nodeObj client.Object) []reconcile.Request {
+	node, ok := nodeObj.(*corev1.Node)
+	if !ok {
+		return nil
+	}
+
+	profiles := &performancev2.PerformanceProfileList{}
+	if err := r.List(context.TODO(), profiles); err != nil {
+		klog.Error("failed to get performance profiles")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i, profile := range profiles.Items {
+		selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+		if selector.Matches(labels.Set(node.Labels)) {
+			requests = append(requests, reconcile.Request{NamespacedName: namespacedName(&profiles.Items[i])})
+		}
+	}
+
+	return requests
+}
+
+// configMapToPerformanceProfile maps the ConfigMap to performance profiles that read user tuned profiles from it,
+// overlays are mapped to their base profiles
+func (r *PerformanceProfileReconciler) configMapToPerformanceProfile(configMapObj client.Object) []reconcile.Request {
+	profiles := &performancev2.PerformanceProfileList{}
+	if err := r.List(context.TODO(), profiles); err != nil {
+		klog.Error("failed to get performance profiles")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range profiles.Items {
+		profile := &profiles.Items[i]
+		if !profileutil.IsTunedProfilesConfigMap(profile, configMapObj.GetNamespace(), configMapObj.GetName()) {
+			continue
+		}
+
+		if profileutil.IsOverlay(profile) {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: profile.Spec.Overlay.BaseProfile}})
+			continue
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: namespacedName(profile)})
+	}
+
+	return requests
+}
+
 func validateUpdateEvent(e *event.UpdateEvent) bool {
 	if e.ObjectOld == nil {
 		klog.Error("Update event has no old runtime object to update")
@@ -169,7 +413,7 @@ func validateUpdateEvent(e *event.UpdateEvent) bool {
 }
 
 // +kubebuilder:rbac:groups="",resources=events,verbs=*
-// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=performance.openshift.io,resources=performanceprofiles;performanceprofiles/status;performanceprofiles/finalizers,verbs=*
 // +kubebuilder:rbac:groups=machineconfiguration.openshift.io,resources=machineconfigs;machineconfigpools;kubeletconfigs,verbs=*
 // +kubebuilder:rbac:groups=tuned.openshift.io,resources=tuneds;profiles,verbs=*
@@ -201,6 +445,11 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return reconcile.Result{}, err
 	}
 
+	// overlays are merged into their base profiles and do not generate components
+	if profileutil.IsOverlay(instance) {
+		return r.reconcileOverlay(instance)
+	}
+
 	if instance.DeletionTimestamp != nil {
 		// delete components
 		if err := r.deleteComponents(instance); err != nil {
@@ -221,6 +470,8 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 				return reconcile.Result{}, err
 			}
 
+			metrics.DeleteProfile(instance.Name)
+
 			return reconcile.Result{}, nil
 		}
 	}
@@ -242,19 +493,124 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
+	// merge overlays into the profile
+	overlayProfiles, ignoredOverlays, err := r.getOverlays(instance)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionFailedGettingOverlays, err)
+	}
+
+	// the webhook validates every profile by itself, but overlays can make the merged spec invalid
+	effective, overlays, err := performancev2.GetEffectiveProfile(instance, overlayProfiles)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionReasonValidationFailed, err)
+	}
+
+	// overlays with other selectors are reported by the base profile as well, not only by their own conditions
+	if len(ignoredOverlays) != 0 {
+		if overlays == nil {
+			overlays = &performancev2.OverlaysStatus{}
+		}
+		overlays.Ignored = ignoredOverlays
+		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "Overlays ignored",
+			"Overlays %s do not select the same nodes and machine config pool as the profile", strings.Join(ignoredOverlays, ", "))
+	}
+
+	// run discovery pods on nodes that did not report the CPU topology yet
+	if err := r.discoverCPUTopology(effective); err != nil {
+		return r.updateDegradedCondition(instance, conditionFailedDiscoveringCPUTopology, err)
+	}
+
+	// compute reserved and isolated CPUs from the node topology
+	cpus, err := r.getComputedCPUs(effective)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionFailedComputingCPUs, err)
+	}
+
+	if cpus != nil {
+		profileutil.SetComputedCPUs(effective, cpus)
+	}
+
+	// huge page sizes and vendor specific kernel arguments depend on the architecture of nodes
+	if err := r.setNodesArchitecture(effective); err != nil {
+		return r.updateDegradedCondition(instance, conditionFailedGettingNodesArchitecture, err)
+	}
+
+	// kubelet config fields owned by the operator can not be overridden, the webhook rejects such profiles,
+	// but the cluster can run the operator without the webhook
+	conflicts, err := performancev2.GetKubeletConfigOverridesConflicts(&effective.Spec)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionReasonKubeletConfigOverridesConflict, err)
+	}
+	if len(conflicts) != 0 {
+		return r.updateDegradedCondition(instance, conditionReasonKubeletConfigOverridesConflict, fmt.Errorf("%s", strings.Join(conflicts, ", ")))
+	}
+
+	// sysctl and sysfs settings the operator depends on can not be overridden as well
+	if tuningConflicts := performancev2.GetTuningConflicts(&effective.Spec); len(tuningConflicts) != 0 {
+		return r.updateDegradedCondition(instance, conditionReasonTuningOverridesConflict, fmt.Errorf("%s", strings.Join(tuningConflicts, ", ")))
+	}
+
+	// the memory of the Static memory manager policy is reserved on the NUMA node of reserved CPUs
+	if profileutil.IsReservedMemoryDerived(effective) {
+		reservedMemory, err := r.getReservedMemory(effective)
+		if err != nil {
+			return r.updateDegradedCondition(instance, conditionFailedReservingMemory, err)
+		}
+		profileutil.SetReservedMemory(effective, reservedMemory)
+	}
+
+	// user tuned profiles read from ConfigMaps are included into the generated tuned as inline profiles
+	if profileutil.IsTunedProfilesReferenced(effective) {
+		tunedProfilesData, err := r.getTunedProfilesData(effective)
+		if err != nil {
+			return r.updateDegradedCondition(instance, conditionFailedGettingTunedProfiles, err)
+		}
+		profileutil.SetTunedProfilesData(effective, tunedProfilesData)
+	}
+
+	// huge pages that do not fit into the memory prevent nodes from booting, so components are not applied
+	hugePages, err := r.getHugePagesFootprint(effective)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionReasonHugePagesExceedMemory, err)
+	}
+
+	// huge pages that nodes failed to allocate at runtime are allocated on boot
+	if hugePages != nil {
+		hugePages.RuntimeAllocation, err = r.getHugePagesRuntimeAllocation(effective, instance.Status.HugePages)
+		if err != nil {
+			return r.updateDegradedCondition(instance, conditionFailedGettingHugePagesAllocation, err)
+		}
+
+		if hugePages.RuntimeAllocation != nil && hugePages.RuntimeAllocation.FallbackToBoot {
+			profileutil.DisableHugePagesRuntimeAllocation(effective)
+		}
+	}
+
 	// apply components
-	result, err := r.applyComponents(instance)
+	result, applied, err := r.applyComponents(effective)
+	if applied != nil {
+		applied.overlays = overlays
+		applied.cpus = cpus
+		applied.hugePages = hugePages
+	}
 	if err != nil {
 		klog.Errorf("failed to deploy performance profile %q components: %v", instance.Name, err)
 		r.Recorder.Eventf(instance, corev1.EventTypeWarning, "Creation failed", "Failed to create all components: %v", err)
 		conditions := r.getDegradedConditions(conditionReasonComponentsCreationFailed, err.Error())
-		if err := r.updateStatus(instance, conditions); err != nil {
+		if err := r.updateStatus(instance, conditions, nil, nil); err != nil {
 			klog.Errorf("failed to update performance profile %q status: %v", instance.Name, err)
 			return reconcile.Result{}, err
 		}
 		return reconcile.Result{}, err
 	}
 
+	// the approval is consumed by the applied change, so a later change with the same ID is held again
+	if applied != nil && applied.changeImpact != nil {
+		if err := r.clearRolloutApproval(instance); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
 	// get kubelet false condition
 	conditions, err := r.getKubeletConditionsByProfile(instance)
 	if err != nil {
@@ -282,7 +638,13 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 		conditions = r.getAvailableConditions()
 	}
 
-	if err := r.updateStatus(instance, conditions); err != nil {
+	// get the rollout state of nodes
+	nodesStatus, err := r.getNodesStatusByProfile(instance)
+	if err != nil {
+		return r.updateDegradedCondition(instance, conditionFailedGettingNodesStatus, err)
+	}
+
+	if err := r.updateStatus(instance, conditions, applied, nodesStatus); err != nil {
 		klog.Errorf("failed to update performance profile %q status: %v", instance.Name, err)
 		// we still want to requeue after some, also in case of error, to avoid chance of multiple reboots
 		if result != nil {
@@ -292,6 +654,11 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return reconcile.Result{}, err
 	}
 
+	// nodes that still miss huge pages are checked again, until they allocate them or the timeout expires
+	if (result == nil || result.IsZero()) && hugePages != nil && isHugePagesAllocationAwaited(hugePages.RuntimeAllocation) {
+		return reconcile.Result{RequeueAfter: hugePagesAllocationRequeuePeriod}, nil
+	}
+
 	if result != nil {
 		return *result, nil
 	}
@@ -299,6 +666,340 @@ func (r *PerformanceProfileReconciler) Reconcile(ctx context.Context, req ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// reconcileOverlay updates the overlay status, the overlay itself is merged by the reconcile of the base profile
+func (r *PerformanceProfileReconciler) reconcileOverlay(instance *performancev2.PerformanceProfile) (ctrl.Result, error) {
+	// the profile owned components before it became an overlay
+	if hasFinalizer(instance, finalizer) {
+		if err := r.deleteComponents(instance); err != nil {
+			klog.Errorf("failed to delete components: %v", err)
+			return reconcile.Result{}, err
+		}
+
+		if r.isComponentsExist(instance) {
+			return reconcile.Result{RequeueAfter: 10 * time.Second}, nil
+		}
+
+		removeFinalizer(instance, finalizer)
+		if err := r.Update(context.TODO(), instance); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		metrics.DeleteProfile(instance.Name)
+		return reconcile.Result{}, nil
+	}
+
+	if instance.DeletionTimestamp != nil {
+		return reconcile.Result{}, nil
+	}
+
+	base := &performancev2.PerformanceProfile{}
+	err := r.Get(context.TODO(), types.NamespacedName{Name: instance.Spec.Overlay.BaseProfile}, base)
+	if k8serros.IsNotFound(err) {
+		conditions := r.getDegradedConditions(conditionReasonBaseProfileNotFound, fmt.Sprintf("the base profile %q does not exist", instance.Spec.Overlay.BaseProfile))
+		return reconcile.Result{}, r.updateStatus(instance, conditions, nil, nil)
+	}
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if len(performancev2.GetOverlays(base, []performancev2.PerformanceProfile{*instance})) == 0 {
+		conditions := r.getDegradedConditions(conditionReasonBaseProfileMismatch, fmt.Sprintf("the overlay does not select the same nodes and machine config pool as the base profile %q", base.Name))
+		return reconcile.Result{}, r.updateStatus(instance, conditions, nil, nil)
+	}
+
+	return reconcile.Result{}, r.updateStatus(instance, r.getAvailableConditions(), nil, nil)
+}
+
+// getOverlays returns overlays of the profile sorted in the order of merging and names of overlays ignored
+// because of other selectors
+func (r *PerformanceProfileReconciler) getOverlays(profile *performancev2.PerformanceProfile) ([]performancev2.PerformanceProfile, []string, error) {
+	profiles := &performancev2.PerformanceProfileList{}
+	if err := r.List(context.TODO(), profiles); err != nil {
+		return nil, nil, err
+	}
+
+	// overlays that are being deleted are not merged anymore
+	var existing []performancev2.PerformanceProfile
+	for _, p := range profiles.Items {
+		if p.DeletionTimestamp == nil {
+			existing = append(existing, p)
+		}
+	}
+
+	return performancev2.GetOverlays(profile, existing), performancev2.GetIgnoredOverlays(profile, existing), nil
+}
+
+// getComputedCPUs returns reserved and isolated CPUs computed from the CPU topology of nodes selected by the profile,
+// the nil value means that the profile specifies CPUs explicitly
+func (r *PerformanceProfileReconciler) getComputedCPUs(profile *performancev2.PerformanceProfile) (*performancev2.CPUStatus, error) {
+	if !profileutil.IsCPUsComputed(profile) {
+		return nil, nil
+	}
+
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	// the kubelet config and the machine config are shared by all nodes, so all nodes should get the same CPUs
+	var cpus *performancev2.CPUStatus
+	var cpusNode string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		topologyInfo, err := performancev2.GetNodeCPUTopology(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the CPU topology of the node %q: %v", node.Name, err)
+		}
+
+		// the topology of the node was not reported yet
+		if topologyInfo == nil {
+			continue
+		}
+
+		nodeCPUs, err := profileutil.GetCPUsFromTopology(profile, topologyInfo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute CPUs of the node %q: %v", node.Name, err)
+		}
+
+		if cpus == nil {
+			cpus = nodeCPUs
+			cpusNode = node.Name
+			continue
+		}
+
+		if !reflect.DeepEqual(cpus, nodeCPUs) {
+			return nil, fmt.Errorf("nodes %q and %q have different CPU topologies, specify reserved and isolated CPUs with node overrides instead", cpusNode, node.Name)
+		}
+	}
+
+	if cpus == nil {
+		return nil, fmt.Errorf("none of the nodes selected by the profile reported the CPU topology under the %q annotation", performancev2.CPUTopologyAnnotation)
+	}
+	return cpus, nil
+}
+
+// setNodesArchitecture sets the architecture and the kernel page size of nodes selected by the profile
+// under profile annotations
+func (r *PerformanceProfileReconciler) setNodesArchitecture(profile *performancev2.PerformanceProfile) error {
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+	return performancev2.SetNodesArchitecture(profile, nodes.Items)
+}
+
+// getReservedMemory returns the memory reserved on NUMA nodes derived from the CPU topology of nodes selected
+// by the profile, the first node that reports the CPU topology is used because nodes share the kubelet config
+func (r *PerformanceProfileReconciler) getReservedMemory(profile *performancev2.PerformanceProfile) ([]performancev2.NUMAReservedMemory, error) {
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(nodes.Items, func(i, j int) bool {
+		return nodes.Items[i].Name < nodes.Items[j].Name
+	})
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		topologyInfo, err := performancev2.GetNodeCPUTopology(node)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the CPU topology of the node %q: %v", node.Name, err)
+		}
+
+		if topologyInfo != nil {
+			return profileutil.GetReservedMemory(profile, topologyInfo)
+		}
+	}
+
+	return profileutil.GetReservedMemory(profile, nil)
+}
+
+// getTunedProfilesData returns user tuned profiles read from ConfigMaps keyed by the name of the user tuned profile,
+// ConfigMaps can change after the profile admission, so the data is validated as well
+func (r *PerformanceProfileReconciler) getTunedProfilesData(profile *performancev2.PerformanceProfile) (map[string]string, error) {
+	data := map[string]string{}
+	for _, fragment := range profile.Spec.TunedProfiles {
+		ref := fragment.ConfigMapRef
+		if ref == nil {
+			continue
+		}
+
+		configMap := &corev1.ConfigMap{}
+		key := types.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}
+		if err := r.ConfigMapReader.Get(context.TODO(), key, configMap); err != nil {
+			return nil, fmt.Errorf("failed to get the ConfigMap %s of the tuned profile %q: %v", key, fragment.Name, err)
+		}
+
+		configMapKey := performancev2.GetTunedProfileConfigMapKey(ref)
+		fragmentData, ok := configMap.Data[configMapKey]
+		if !ok {
+			return nil, fmt.Errorf("the ConfigMap %s does not have the %q key of the tuned profile %q", key, configMapKey, fragment.Name)
+		}
+
+		if err := performancev2.ValidateTunedProfileData(fragmentData); err != nil {
+			return nil, fmt.Errorf("invalid tuned profile %q in the ConfigMap %s: %v", fragment.Name, key, err)
+		}
+		data[fragment.Name] = fragmentData
+	}
+	return data, nil
+}
+
+// getHugePagesFootprint returns the memory taken by huge pages of the profile, it fails when huge pages
+// do not fit into the memory capacity of nodes selected by the profile
+func (r *PerformanceProfileReconciler) getHugePagesFootprint(profile *performancev2.PerformanceProfile) (*performancev2.HugePagesStatus, error) {
+	footprint, err := performancev2.GetHugePagesFootprint(profile.Spec.HugePages)
+	if err != nil || footprint == nil {
+		return nil, err
+	}
+
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	if names := performancev2.GetNodesWithoutHugePagesMemory(footprint, nodes.Items); len(names) != 0 {
+		return nil, fmt.Errorf("huge pages take %s, more than the memory capacity of nodes %s", footprint.Total.String(), strings.Join(names, ", "))
+	}
+
+	return footprint, nil
+}
+
+// getHugePagesRuntimeAllocation returns the state of huge pages allocated at runtime on nodes selected by the profile,
+// the nil value means that the profile does not have huge pages allocated at runtime
+func (r *PerformanceProfileReconciler) getHugePagesRuntimeAllocation(profile *performancev2.PerformanceProfile, previous *performancev2.HugePagesStatus) (*performancev2.HugePagesRuntimeAllocationStatus, error) {
+	pages := profileutil.GetRuntimeHugePages(profile)
+	if len(pages) == 0 {
+		return nil, nil
+	}
+
+	var previousAllocation *performancev2.HugePagesRuntimeAllocationStatus
+	if previous != nil && previous.RuntimeAllocation != nil && reflect.DeepEqual(previous.RuntimeAllocation.Pages, pages) {
+		previousAllocation = previous.RuntimeAllocation
+	}
+
+	// huge pages stay allocated on boot until they are changed, otherwise nodes would switch between both paths
+	if previousAllocation != nil && previousAllocation.FallbackToBoot {
+		return previousAllocation.DeepCopy(), nil
+	}
+
+	allocation := &performancev2.HugePagesRuntimeAllocationStatus{
+		Pages:          pages,
+		LastUpdateTime: metav1.NewTime(time.Now().Truncate(time.Second)),
+	}
+	if previousAllocation != nil {
+		allocation.LastUpdateTime = previousAllocation.LastUpdateTime
+	}
+
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	tunedProfileList := &tunedv1.ProfileList{}
+	if err := r.List(context.TODO(), tunedProfileList); err != nil {
+		return nil, err
+	}
+
+	tunedProfiles := map[string]*tunedv1.Profile{}
+	for _, tunedProfile := range removeUnMatchedTunedProfiles(nodes.Items, tunedProfileList.Items) {
+		tunedProfiles[tunedProfile.Name] = tunedProfile.DeepCopy()
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		nodeAllocation := getNodeHugePagesAllocation(node, tunedProfiles[node.Name], pages, allocation.LastUpdateTime)
+		if nodeAllocation.State == performancev2.HugePagesAllocationStateFailed {
+			allocation.FallbackToBoot = true
+		}
+		allocation.Nodes = append(allocation.Nodes, nodeAllocation)
+	}
+
+	sort.Slice(allocation.Nodes, func(i, j int) bool {
+		return allocation.Nodes[i].Name < allocation.Nodes[j].Name
+	})
+
+	if allocation.FallbackToBoot {
+		r.Recorder.Eventf(profile, corev1.EventTypeWarning, "HugePagesFallbackToBoot", "Nodes failed to allocate huge pages at runtime, huge pages will be allocated on boot")
+	}
+	return allocation, nil
+}
+
+// getNodeHugePagesAllocation returns the state of huge pages allocated at runtime on the node, the node
+// reports huge pages periodically, so the kernel gets some time to allocate huge pages after they were changed
+func getNodeHugePagesAllocation(node *corev1.Node, tunedProfile *tunedv1.Profile, pages []performancev2.HugePage, updateTime metav1.Time) performancev2.NodeHugePagesAllocation {
+	nodeAllocation := performancev2.NodeHugePagesAllocation{
+		Name:  node.Name,
+		State: performancev2.HugePagesAllocationStatePending,
+	}
+
+	if !isTunedProfileApplied(tunedProfile) {
+		return nodeAllocation
+	}
+
+	allocated, err := profileutil.GetNodeHugePagesAllocation(node)
+	if err != nil {
+		nodeAllocation.Message = fmt.Sprintf("failed to parse the %q annotation: %v", performancev2.HugePagesAllocationAnnotation, err)
+		return nodeAllocation
+	}
+
+	if allocated == nil {
+		return nodeAllocation
+	}
+
+	var messages []string
+	for i := range pages {
+		page := &pages[i]
+		if count := allocated.GetAllocatedHugePagesCount(page); count < page.Count {
+			messages = append(messages, fmt.Sprintf("allocated %d of %d huge pages of size %s on the NUMA node %d", count, page.Count, page.Size, *page.Node))
+		}
+	}
+
+	if len(messages) == 0 {
+		nodeAllocation.State = performancev2.HugePagesAllocationStateAllocated
+		return nodeAllocation
+	}
+
+	nodeAllocation.Message = strings.Join(messages, ", ")
+	if !allocated.Timestamp.Time.Before(updateTime.Add(hugePagesAllocationTimeout)) {
+		nodeAllocation.State = performancev2.HugePagesAllocationStateFailed
+	}
+	return nodeAllocation
+}
+
+// isHugePagesAllocationAwaited returns whether or not some node reported that it misses huge pages,
+// but the timeout to allocate them did not expire yet
+func isHugePagesAllocationAwaited(allocation *performancev2.HugePagesRuntimeAllocationStatus) bool {
+	if allocation == nil || allocation.FallbackToBoot {
+		return false
+	}
+
+	for _, node := range allocation.Nodes {
+		if node.State == performancev2.HugePagesAllocationStatePending && node.Message != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func isTunedProfileApplied(tunedProfile *tunedv1.Profile) bool {
+	if tunedProfile == nil {
+		return false
+	}
+
+	for _, condition := range tunedProfile.Status.Conditions {
+		if condition.Type == tunedv1.TunedProfileApplied && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *PerformanceProfileReconciler) deleteDeprecatedComponents(instance *performancev2.PerformanceProfile) error {
 	// remove the machine config with the deprecated name
 	name := components.GetComponentName(instance.Name, components.ComponentNamePrefix)
@@ -307,52 +1008,70 @@ func (r *PerformanceProfileReconciler) deleteDeprecatedComponents(instance *perf
 
 func (r *PerformanceProfileReconciler) updateDegradedCondition(instance *performancev2.PerformanceProfile, conditionState string, conditionError error) (ctrl.Result, error) {
 	conditions := r.getDegradedConditions(conditionState, conditionError.Error())
-	if err := r.updateStatus(instance, conditions); err != nil {
+	if err := r.updateStatus(instance, conditions, nil, nil); err != nil {
 		klog.Errorf("failed to update performance profile %q status: %v", instance.Name, err)
 		return reconcile.Result{}, err
 	}
 	return reconcile.Result{}, conditionError
 }
 
-func (r *PerformanceProfileReconciler) applyComponents(profile *performancev2.PerformanceProfile) (*reconcile.Result, error) {
+func (r *PerformanceProfileReconciler) applyComponents(profile *performancev2.PerformanceProfile) (*reconcile.Result, *applyResult, error) {
 
 	if profileutil.IsPaused(profile) {
 		klog.Infof("Ignoring reconcile loop for pause performance profile %s", profile.Name)
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	components, err := manifestset.GetNewComponents(profile, &r.AssetsDir)
 	if err != nil {
-		return nil, err
+		metrics.IncRenderFailures(profile.Name)
+		return nil, nil, err
 	}
 	for _, componentObj := range components.ToObjects() {
 		if err := controllerutil.SetControllerReference(profile, componentObj, r.Scheme); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 	}
 
+	// effective tuning settings are reported only for profiles with tuning overrides or user tuned profiles
+	var tuning *performancev2.TuningStatus
+	if profile.Spec.Tuning != nil || len(profile.Spec.TunedProfiles) != 0 {
+		tuning = tuned.GetTuningStatus(components.Tuned)
+	}
+
+	timer := metrics.NewReconcileTimer()
+	defer timer.Observe()
+
 	// get mutated machine config
+	start := time.Now()
 	mcMutated, err := r.getMutatedMachineConfig(components.MachineConfig)
+	timer.Track(components.MachineConfig.Kind, start)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// get mutated kubelet config
+	start = time.Now()
 	kcMutated, err := r.getMutatedKubeletConfig(components.KubeletConfig)
+	timer.Track(components.KubeletConfig.Kind, start)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// get mutated performance tuned
+	start = time.Now()
 	performanceTunedMutated, err := r.getMutatedTuned(components.Tuned)
+	timer.Track(components.Tuned.Kind, start)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// get mutated RuntimeClass
-	runtimeClassMutated, err := r.getMutatedRuntimeClass(components.RuntimeClass)
+	start = time.Now()
+	runtimeClassMutated, err := r.getMutatedRuntimeClass(profile, components.RuntimeClass)
+	timer.Track(components.RuntimeClass.Kind, start)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	updated := mcMutated != nil ||
@@ -362,35 +1081,223 @@ func (r *PerformanceProfileReconciler) applyComponents(profile *performancev2.Pe
 
 	// does not update any resources, if it no changes to relevant objects and just continue to the status update
 	if !updated {
-		return nil, nil
+		componentStatuses, err := getComponentStatuses(profile, components, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, &applyResult{componentStatuses: componentStatuses, tuning: tuning}, nil
+	}
+
+	// the existing components should be fetched before the update to estimate the change impact
+	diff, err := r.getComponentsDiff(components, mcMutated != nil, kcMutated != nil, performanceTunedMutated != nil, runtimeClassMutated != nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	changeImpact := manifestset.GetChangeImpact(diff)
+
+	// hold the change until it is approved or until the maintenance window starts
+	if profileutil.IsChangeHeld(profile, changeImpact) {
+		pendingChange, requeueAfter, err := r.getPendingChange(profile, components, diff, changeImpact)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if pendingChange != nil {
+			if profile.Status.PendingChange == nil || profile.Status.PendingChange.ID != pendingChange.ID {
+				r.Recorder.Eventf(profile, corev1.EventTypeNormal, "Rollout pending", "The change %s is pending: %s", pendingChange.ID, pendingChange.Reason)
+			}
+
+			outOfSync := map[string]bool{
+				components.MachineConfig.Kind: mcMutated != nil,
+				components.KubeletConfig.Kind: kcMutated != nil,
+				components.Tuned.Kind:         performanceTunedMutated != nil,
+				components.RuntimeClass.Kind:  runtimeClassMutated != nil,
+			}
+			componentStatuses, err := getComponentStatuses(profile, components, outOfSync)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return &reconcile.Result{RequeueAfter: requeueAfter}, &applyResult{
+				pendingChange:     pendingChange,
+				componentStatuses: componentStatuses,
+				// the held change is not applied yet
+				tuning: profile.Status.Tuning,
+			}, nil
+		}
 	}
 
 	if mcMutated != nil {
-		if err := r.createOrUpdateMachineConfig(mcMutated); err != nil {
-			return nil, err
+		start = time.Now()
+		err := r.createOrUpdateMachineConfig(mcMutated)
+		timer.Track(components.MachineConfig.Kind, start)
+		if err != nil {
+			return nil, nil, err
 		}
+		metrics.IncComponentUpdates(profile.Name, components.MachineConfig.Kind)
 	}
 
 	if performanceTunedMutated != nil {
-		if err := r.createOrUpdateTuned(performanceTunedMutated, profile.Name); err != nil {
-			return nil, err
+		start = time.Now()
+		err := r.createOrUpdateTuned(performanceTunedMutated, profile.Name)
+		timer.Track(components.Tuned.Kind, start)
+		if err != nil {
+			return nil, nil, err
 		}
+		metrics.IncComponentUpdates(profile.Name, components.Tuned.Kind)
 	}
 
 	if kcMutated != nil {
-		if err := r.createOrUpdateKubeletConfig(kcMutated); err != nil {
-			return nil, err
+		start = time.Now()
+		err := r.createOrUpdateKubeletConfig(kcMutated)
+		timer.Track(components.KubeletConfig.Kind, start)
+		if err != nil {
+			return nil, nil, err
 		}
+		metrics.IncComponentUpdates(profile.Name, components.KubeletConfig.Kind)
 	}
 
 	if runtimeClassMutated != nil {
-		if err := r.createOrUpdateRuntimeClass(runtimeClassMutated); err != nil {
-			return nil, err
+		start = time.Now()
+		err := r.createOrUpdateRuntimeClass(profile, runtimeClassMutated)
+		timer.Track(components.RuntimeClass.Kind, start)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := r.deleteStaleRuntimeClasses(profile, runtimeClassMutated.Name); err != nil {
+			return nil, nil, err
 		}
+		metrics.IncComponentUpdates(profile.Name, components.RuntimeClass.Kind)
+	}
+
+	changeImpact.LastTransitionTime = metav1.Now()
+
+	componentStatuses, err := getComponentStatuses(profile, components, nil)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	r.Recorder.Eventf(profile, corev1.EventTypeNormal, "Creation succeeded", "Succeeded to create all components")
-	return &reconcile.Result{}, nil
+	return &reconcile.Result{}, &applyResult{
+		changeImpact:      changeImpact,
+		componentStatuses: componentStatuses,
+		tuning:            tuning,
+	}, nil
+}
+
+// clearRolloutApproval removes the rollout approval annotation from the profile
+func (r *PerformanceProfileReconciler) clearRolloutApproval(profile *performancev2.PerformanceProfile) error {
+	if _, ok := profile.Annotations[performancev2.PerformanceProfileApproveRolloutAnnotation]; !ok {
+		return nil
+	}
+
+	patch := client.MergeFrom(profile.DeepCopy())
+	delete(profile.Annotations, performancev2.PerformanceProfileApproveRolloutAnnotation)
+	return r.Patch(context.TODO(), profile, patch)
+}
+
+// getPendingChange returns the pending change when the rollout policy does not allow to apply it now,
+// the second returned value is the time left until the next maintenance window
+func (r *PerformanceProfileReconciler) getPendingChange(
+	profile *performancev2.PerformanceProfile,
+	components *manifestset.ManifestResultSet,
+	diff *manifestset.ManifestResultSetDiff,
+	changeImpact *performancev2.ChangeImpact) (*performancev2.PendingChange, time.Duration, error) {
+	changeID, err := components.GetHash()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if profileutil.IsRolloutApproved(profile, changeID) {
+		klog.Infof("Applying the approved change %s of the performance profile %q", changeID, profile.Name)
+		return nil, 0, nil
+	}
+
+	now := time.Now()
+	strategy := profileutil.GetRolloutStrategy(profile)
+	if strategy == performancev2.RolloutStrategyMaintenanceWindow && profileutil.IsInMaintenanceWindow(profile, now) {
+		klog.Infof("Applying the change %s of the performance profile %q during the maintenance window", changeID, profile.Name)
+		return nil, 0, nil
+	}
+
+	pendingChange := &performancev2.PendingChange{
+		ID:     changeID,
+		Impact: *changeImpact,
+		Reason: "waiting for the approval",
+	}
+
+	for _, c := range diff.Components {
+		if len(c.Changes) == 0 {
+			continue
+		}
+
+		componentChanges := performancev2.ComponentChanges{
+			Kind: c.Kind,
+			Name: c.Name,
+		}
+		for _, change := range c.Changes {
+			componentChanges.Fields = append(componentChanges.Fields, change.Path)
+		}
+		pendingChange.Changes = append(pendingChange.Changes, componentChanges)
+	}
+
+	var requeueAfter time.Duration
+	if strategy == performancev2.RolloutStrategyMaintenanceWindow {
+		pendingChange.Reason = "waiting for the maintenance window or for the approval"
+		if next := profileutil.GetNextMaintenanceWindow(profile, now); next != nil {
+			pendingChange.NextMaintenanceWindow = &metav1.Time{Time: *next}
+			requeueAfter = next.Sub(now)
+		}
+	}
+
+	return pendingChange, requeueAfter, nil
+}
+
+// getComponentsDiff returns the diff between existing and new components,
+// components that were not mutated are considered as unchanged
+func (r *PerformanceProfileReconciler) getComponentsDiff(newComponents *manifestset.ManifestResultSet, mcMutated, kcMutated, tunedMutated, runtimeClassMutated bool) (*manifestset.ManifestResultSetDiff, error) {
+	existing := &manifestset.ManifestResultSet{
+		MachineConfig: newComponents.MachineConfig,
+		KubeletConfig: newComponents.KubeletConfig,
+		Tuned:         newComponents.Tuned,
+		RuntimeClass:  newComponents.RuntimeClass,
+	}
+
+	if mcMutated {
+		mc, err := r.getMachineConfig(newComponents.MachineConfig.Name)
+		if err != nil && !k8serros.IsNotFound(err) {
+			return nil, err
+		}
+		existing.MachineConfig = mc
+	}
+
+	if kcMutated {
+		kc, err := r.getKubeletConfig(newComponents.KubeletConfig.Name)
+		if err != nil && !k8serros.IsNotFound(err) {
+			return nil, err
+		}
+		existing.KubeletConfig = kc
+	}
+
+	if tunedMutated {
+		tuned, err := r.getTuned(newComponents.Tuned.Name, newComponents.Tuned.Namespace)
+		if err != nil && !k8serros.IsNotFound(err) {
+			return nil, err
+		}
+		existing.Tuned = tuned
+	}
+
+	if runtimeClassMutated {
+		runtimeClass, err := r.getRuntimeClass(newComponents.RuntimeClass.Name)
+		if err != nil && !k8serros.IsNotFound(err) {
+			return nil, err
+		}
+		existing.RuntimeClass = runtimeClass
+	}
+
+	return manifestset.Diff(existing, newComponents)
 }
 
 func (r *PerformanceProfileReconciler) deleteComponents(profile *performancev2.PerformanceProfile) error {
@@ -404,7 +1311,7 @@ func (r *PerformanceProfileReconciler) deleteComponents(profile *performancev2.P
 		return err
 	}
 
-	if err := r.deleteRuntimeClass(name); err != nil {
+	if err := r.deleteRuntimeClass(profile, performancev2.GetRuntimeClassName(profile)); err != nil {
 		return err
 	}
 
@@ -429,8 +1336,11 @@ func (r *PerformanceProfileReconciler) isComponentsExist(profile *performancev2.
 		return true
 	}
 
-	if _, err := r.getRuntimeClass(name); !k8serros.IsNotFound(err) {
-		klog.Infof("Runtime class %q exists under the cluster", name)
+	// runtime classes of other owners are not deleted with the profile components
+	runtimeClassName := performancev2.GetRuntimeClassName(profile)
+	runtimeClass, err := r.getRuntimeClass(runtimeClassName)
+	if err == nil && isControlledByProfile(runtimeClass, profile) || err != nil && !k8serros.IsNotFound(err) {
+		klog.Infof("Runtime class %q exists under the cluster", runtimeClassName)
 		return true
 	}
 
@@ -18,22 +18,29 @@ import (
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/machineconfig"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/runtimeclass"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/tuned"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/metrics"
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
 	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	dto "github.com/prometheus/client_model/go"
 
 	corev1 "k8s.io/api/core/v1"
 	nodev1beta1 "k8s.io/api/node/v1beta1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/utils/pointer"
 
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -110,6 +117,204 @@ var _ = Describe("Controller", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("should create the runtime class of the profile and delete the renamed one", func() {
+			r := newFakeReconciler(profile)
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			oldKey := types.NamespacedName{
+				Name:      components.GetComponentName(profile.Name, components.ComponentNamePrefix),
+				Namespace: metav1.NamespaceNone,
+			}
+			Expect(r.Get(context.TODO(), oldKey, &nodev1beta1.RuntimeClass{})).To(Succeed())
+
+			updatedProfile := &performancev2.PerformanceProfile{}
+			Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).To(Succeed())
+			toleration := corev1.Toleration{
+				Key:      "node-role.kubernetes.io/worker-cnf",
+				Operator: corev1.TolerationOpExists,
+				Effect:   corev1.TaintEffectNoSchedule,
+			}
+			updatedProfile.Spec.RuntimeClass = &performancev2.RuntimeClass{
+				Name:        pointer.StringPtr("low-latency"),
+				Handler:     pointer.StringPtr("low-latency"),
+				Overhead:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+				Tolerations: []corev1.Toleration{toleration},
+			}
+			Expect(r.Update(context.TODO(), updatedProfile)).To(Succeed())
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			runtimeClass := &nodev1beta1.RuntimeClass{}
+			key := types.NamespacedName{
+				Name:      "low-latency",
+				Namespace: metav1.NamespaceNone,
+			}
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			Expect(runtimeClass.Handler).To(Equal("low-latency"))
+			Expect(runtimeClass.Overhead.PodFixed.Cpu().String()).To(Equal("250m"))
+			Expect(runtimeClass.Scheduling.NodeSelector).To(Equal(profile.Spec.NodeSelector))
+			Expect(runtimeClass.Scheduling.Tolerations).To(Equal([]corev1.Toleration{toleration}))
+
+			err := r.Get(context.TODO(), oldKey, &nodev1beta1.RuntimeClass{})
+			Expect(errors.IsNotFound(err)).To(BeTrue())
+
+			Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(updatedProfile.Status.RuntimeClass).NotTo(BeNil())
+			Expect(*updatedProfile.Status.RuntimeClass).To(Equal("low-latency"))
+		})
+
+		It("should update the overhead and recreate the runtime class when the handler changes", func() {
+			r := newFakeReconciler(profile)
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			key := types.NamespacedName{
+				Name:      components.GetComponentName(profile.Name, components.ComponentNamePrefix),
+				Namespace: metav1.NamespaceNone,
+			}
+			runtimeClass := &nodev1beta1.RuntimeClass{}
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			uid := types.UID("original")
+			runtimeClass.UID = uid
+			Expect(r.Update(context.TODO(), runtimeClass)).To(Succeed())
+
+			updatedProfile := &performancev2.PerformanceProfile{}
+			Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).To(Succeed())
+			updatedProfile.Spec.RuntimeClass = &performancev2.RuntimeClass{
+				Overhead: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+			}
+			Expect(r.Update(context.TODO(), updatedProfile)).To(Succeed())
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			Expect(runtimeClass.Overhead.PodFixed.Cpu().String()).To(Equal("250m"))
+			Expect(runtimeClass.UID).To(Equal(uid))
+
+			Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).To(Succeed())
+			updatedProfile.Spec.RuntimeClass.Handler = pointer.StringPtr("low-latency")
+			Expect(r.Update(context.TODO(), updatedProfile)).To(Succeed())
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			runtimeClass = &nodev1beta1.RuntimeClass{}
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			Expect(runtimeClass.Handler).To(Equal("low-latency"))
+			Expect(runtimeClass.UID).ToNot(Equal(uid))
+			Expect(metav1.IsControlledBy(runtimeClass, updatedProfile)).To(BeTrue())
+		})
+
+		It("should not update or delete runtime classes of other owners", func() {
+			runtimeClass := &nodev1beta1.RuntimeClass{
+				ObjectMeta: metav1.ObjectMeta{Name: components.GetComponentName(profile.Name, components.ComponentNamePrefix)},
+				Handler:    "runc",
+			}
+			r := newFakeReconciler(profile, runtimeClass)
+			_, err := r.Reconcile(context.TODO(), request)
+			Expect(err).To(MatchError(ContainSubstring(`the runtime class "performance-test" already exists and is not owned by the performance profile "test"`)))
+
+			key := types.NamespacedName{Name: runtimeClass.Name}
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			Expect(runtimeClass.Handler).To(Equal("runc"))
+
+			updatedProfile := &performancev2.PerformanceProfile{}
+			Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).To(Succeed())
+			Expect(r.deleteComponents(updatedProfile)).To(Succeed())
+			Expect(r.Get(context.TODO(), key, runtimeClass)).To(Succeed())
+			Expect(r.isComponentsExist(updatedProfile)).To(BeFalse())
+		})
+
+		It("should include user tuned profiles read from ConfigMaps into the tuned", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "tuned-profiles", Namespace: performancev2.ReferencedConfigMapsNamespace},
+				Data:       map[string]string{"ksm": "[sysfs]\n/sys/kernel/mm/ksm/run=0\n"},
+			}
+			profile.Spec.TunedProfiles = []performancev2.TunedProfileFragment{
+				{
+					Name: "ksm",
+					ConfigMapRef: &performancev2.TunedProfileConfigMapReference{
+						Name:      configMap.Name,
+						Namespace: configMap.Namespace,
+						Key:       pointer.StringPtr("ksm"),
+					},
+				},
+			}
+
+			r := newFakeReconciler(profile, configMap)
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			key := types.NamespacedName{
+				Name:      components.GetComponentName(profile.Name, components.ProfileNamePerformance),
+				Namespace: components.NamespaceNodeTuningOperator,
+			}
+			tuned := &tunedv1.Tuned{}
+			Expect(r.Get(context.TODO(), key, tuned)).To(Succeed())
+			Expect(tuned.Spec.Profile).To(HaveLen(2))
+			Expect(*tuned.Spec.Profile[1].Name).To(Equal(key.Name + "-ksm"))
+			Expect(*tuned.Spec.Profile[1].Data).To(Equal("[main]\ninclude=" + key.Name + "\n\n[sysfs]\n/sys/kernel/mm/ksm/run=0\n"))
+			Expect(*tuned.Spec.Recommend[0].Profile).To(Equal(key.Name + "-ksm"))
+
+			Expect(r.configMapToPerformanceProfile(configMap)).To(Equal([]reconcile.Request{request}))
+
+			configMap.Data["ksm"] = "[sysfs]\n/sys/kernel/mm/ksm/run=1\n"
+			Expect(r.Update(context.TODO(), configMap)).To(Succeed())
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			Expect(r.Get(context.TODO(), key, tuned)).To(Succeed())
+			Expect(*tuned.Spec.Profile[1].Data).To(ContainSubstring("/sys/kernel/mm/ksm/run=1"))
+		})
+
+		It("should report invalid user tuned profiles read from ConfigMaps under the Degraded condition", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "tuned-profiles", Namespace: performancev2.ReferencedConfigMapsNamespace},
+				Data:       map[string]string{performancev2.DefaultTunedProfileConfigMapKey: "[bootloader]\ncmdline_custom=+nosmt\n"},
+			}
+			profile.Spec.TunedProfiles = []performancev2.TunedProfileFragment{
+				{
+					Name:         "custom",
+					ConfigMapRef: &performancev2.TunedProfileConfigMapReference{Name: configMap.Name, Namespace: configMap.Namespace},
+				},
+			}
+
+			r := newFakeReconciler(profile, configMap)
+			degradedCondition := reconcileDegraded(r, request, conditionFailedGettingTunedProfiles)
+			Expect(degradedCondition.Message).To(ContainSubstring(`invalid tuned profile "custom" in the ConfigMap openshift-performance-addon-operator/tuned-profiles: line 1: the section "bootloader" is not allowed`))
+
+			Expect(r.Delete(context.TODO(), configMap)).To(Succeed())
+			degradedCondition = reconcileDegraded(r, request, conditionFailedGettingTunedProfiles)
+			Expect(degradedCondition.Message).To(ContainSubstring(`failed to get the ConfigMap openshift-performance-addon-operator/tuned-profiles of the tuned profile "custom"`))
+		})
+
+		It("should report metrics of the performance profile", func() {
+			metrics.DeleteProfile(profile.Name)
+			r := newFakeReconciler(profile)
+
+			Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+			for _, kind := range []string{"MachineConfig", "KubeletConfig", "Tuned", "RuntimeClass"} {
+				m := &dto.Metric{}
+				Expect(metrics.ComponentUpdates.WithLabelValues(profile.Name, kind).Write(m)).To(Succeed())
+				Expect(m.GetCounter().GetValue()).To(Equal(1.0))
+			}
+
+			m := &dto.Metric{}
+			Expect(metrics.ProfileCondition.WithLabelValues(profile.Name, string(conditionsv1.ConditionAvailable)).Write(m)).To(Succeed())
+			Expect(m.GetGauge().GetValue()).To(Equal(1.0))
+
+			m = &dto.Metric{}
+			Expect(metrics.ProfileNodes.WithLabelValues(profile.Name, string(performancev2.NodeRolloutStateUpdated)).Write(m)).To(Succeed())
+			Expect(m.GetGauge().GetValue()).To(Equal(0.0))
+		})
+
+		It("should count render failures", func() {
+			metrics.DeleteProfile(profile.Name)
+			r := newFakeReconciler(profile)
+			r.AssetsDir = "/nonexistent"
+
+			_, err := r.Reconcile(context.TODO(), request)
+			Expect(err).To(HaveOccurred())
+
+			m := &dto.Metric{}
+			Expect(metrics.RenderFailures.WithLabelValues(profile.Name).Write(m)).To(Succeed())
+			Expect(m.GetCounter().GetValue()).To(Equal(1.0))
+		})
+
 		It("should create event on the second reconcile loop", func() {
 			r := newFakeReconciler(profile)
 
@@ -341,7 +546,8 @@ var _ = Describe("Controller", func() {
 				tunedPerformance, err = tuned.NewNodePerformance(assetsDir, profile)
 				Expect(err).ToNot(HaveOccurred())
 
-				runtimeClass = runtimeclass.New(profile, machineconfig.HighPerformanceRuntime)
+				runtimeClass = runtimeclass.New(profile, machineconfig.HighPerformanceRuntimeAnnotations)
+				Expect(controllerutil.SetControllerReference(profile, runtimeClass, scheme.Scheme)).To(Succeed())
 			})
 
 			It("should not record new create event", func() {
@@ -584,6 +790,771 @@ var _ = Describe("Controller", func() {
 				Expect(*updatedProfile.Status.RuntimeClass).To(Equal(runtimeClass.Name))
 			})
 
+			It("should update status with effective tuning settings", func() {
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.Tuning).To(BeNil())
+
+				updatedProfile.Spec.Tuning = &performancev2.Tuning{
+					Sysctl: map[string]string{"kernel.hung_task_timeout_secs": "700"},
+					Sysfs:  map[string]string{"/sys/kernel/mm/ksm/run": "0"},
+				}
+				Expect(r.Update(context.TODO(), updatedProfile)).To(Succeed())
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.Tuning).ToNot(BeNil())
+				Expect(updatedProfile.Status.Tuning.Sysctl).To(HaveKeyWithValue("kernel.hung_task_timeout_secs", "700"))
+				Expect(updatedProfile.Status.Tuning.Sysctl).To(HaveKeyWithValue("kernel.sched_rt_runtime_us", "-1"))
+				Expect(updatedProfile.Status.Tuning.Sysfs).To(Equal(map[string]string{"/sys/kernel/mm/ksm/run": "0"}))
+			})
+
+			It("should update status with the tuned reload change impact", func() {
+				profile.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				key := types.NamespacedName{
+					Name:      profile.Name,
+					Namespace: metav1.NamespaceNone,
+				}
+				Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.LastChangeImpact).ToNot(BeNil())
+				Expect(updatedProfile.Status.LastChangeImpact.Type).To(Equal(performancev2.ChangeImpactTunedReload))
+				Expect(updatedProfile.Status.LastChangeImpact.RebootRequired).To(BeFalse())
+				Expect(updatedProfile.Status.LastChangeImpact.Components).To(ConsistOf("Tuned"))
+			})
+
+			It("should update status with the machine config change impact", func() {
+				profile.Spec.RealTimeKernel.Enabled = pointer.BoolPtr(false)
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				key := types.NamespacedName{
+					Name:      profile.Name,
+					Namespace: metav1.NamespaceNone,
+				}
+				Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.LastChangeImpact).ToNot(BeNil())
+				Expect(updatedProfile.Status.LastChangeImpact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+				Expect(updatedProfile.Status.LastChangeImpact.RebootRequired).To(BeTrue())
+				Expect(updatedProfile.Status.LastChangeImpact.Components).To(ConsistOf("MachineConfig"))
+			})
+
+			It("should not update the change impact when components do not change", func() {
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				key := types.NamespacedName{
+					Name:      profile.Name,
+					Namespace: metav1.NamespaceNone,
+				}
+				Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.LastChangeImpact).To(BeNil())
+			})
+
+			It("should update status with the components sync state and the observed generation", func() {
+				profile.Generation = 2
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				key := types.NamespacedName{
+					Name:      profile.Name,
+					Namespace: metav1.NamespaceNone,
+				}
+				Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+				Expect(updatedProfile.Status.ObservedGeneration).To(Equal(int64(2)))
+				Expect(updatedProfile.Status.Components).To(HaveLen(4))
+				for _, c := range updatedProfile.Status.Components {
+					Expect(c.InSync).To(BeTrue())
+					Expect(c.Hash).ToNot(BeEmpty())
+				}
+				Expect(updatedProfile.Status.Components[2].Kind).To(Equal("Tuned"))
+				Expect(updatedProfile.Status.Components[2].Name).To(Equal(tunedPerformance.Name))
+				Expect(updatedProfile.Status.Components[2].Namespace).To(Equal(tunedPerformance.Namespace))
+			})
+
+			Context("with the approval rollout strategy", func() {
+				BeforeEach(func() {
+					strategy := performancev2.RolloutStrategyApproval
+					profile.Spec.Rollout = &performancev2.Rollout{Strategy: &strategy}
+					profile.Spec.RealTimeKernel.Enabled = pointer.BoolPtr(false)
+				})
+
+				It("should hold the change until it is approved", func() {
+					r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					mcKey := types.NamespacedName{
+						Name:      machineconfig.GetMachineConfigName(profile),
+						Namespace: metav1.NamespaceNone,
+					}
+					updatedMC := &mcov1.MachineConfig{}
+					Expect(r.Get(context.TODO(), mcKey, updatedMC)).ToNot(HaveOccurred())
+					Expect(updatedMC.Spec.KernelType).To(Equal(machineconfig.MCKernelRT))
+
+					profileKey := types.NamespacedName{
+						Name:      profile.Name,
+						Namespace: metav1.NamespaceNone,
+					}
+					updatedProfile := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), profileKey, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.PendingChange).ToNot(BeNil())
+					Expect(updatedProfile.Status.PendingChange.ID).ToNot(BeEmpty())
+					Expect(updatedProfile.Status.PendingChange.Impact.RebootRequired).To(BeTrue())
+					Expect(updatedProfile.Status.PendingChange.Changes).To(HaveLen(1))
+					Expect(updatedProfile.Status.PendingChange.Changes[0].Kind).To(Equal("MachineConfig"))
+					Expect(updatedProfile.Status.PendingChange.Changes[0].Fields).To(ContainElement("spec.kernelType"))
+
+					for _, c := range updatedProfile.Status.Components {
+						Expect(c.InSync).To(Equal(c.Kind != "MachineConfig"))
+					}
+
+					updatedProfile.Annotations = map[string]string{
+						performancev2.PerformanceProfileApproveRolloutAnnotation: updatedProfile.Status.PendingChange.ID,
+					}
+					Expect(r.Update(context.TODO(), updatedProfile)).ToNot(HaveOccurred())
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedMC = &mcov1.MachineConfig{}
+					Expect(r.Get(context.TODO(), mcKey, updatedMC)).ToNot(HaveOccurred())
+					Expect(updatedMC.Spec.KernelType).To(Equal(machineconfig.MCKernelDefault))
+
+					updatedProfile = &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), profileKey, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.PendingChange).To(BeNil())
+					Expect(updatedProfile.Status.LastChangeImpact).ToNot(BeNil())
+					Expect(updatedProfile.Status.LastChangeImpact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+					Expect(updatedProfile.Annotations).ToNot(HaveKey(performancev2.PerformanceProfileApproveRolloutAnnotation))
+
+					By("Holding the same change again after the machine config was reverted")
+					revertedMC := mc.DeepCopy()
+					revertedMC.ResourceVersion = updatedMC.ResourceVersion
+					Expect(r.Update(context.TODO(), revertedMC)).ToNot(HaveOccurred())
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedMC = &mcov1.MachineConfig{}
+					Expect(r.Get(context.TODO(), mcKey, updatedMC)).ToNot(HaveOccurred())
+					Expect(updatedMC.Spec.KernelType).To(Equal(machineconfig.MCKernelRT))
+
+					updatedProfile = &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), profileKey, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.PendingChange).ToNot(BeNil())
+				})
+
+				It("should not hold changes that do not reboot nodes", func() {
+					profile.Spec.RealTimeKernel.Enabled = pointer.BoolPtr(true)
+					profile.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+					r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					key := types.NamespacedName{
+						Name:      profile.Name,
+						Namespace: metav1.NamespaceNone,
+					}
+					Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.PendingChange).To(BeNil())
+					Expect(updatedProfile.Status.LastChangeImpact).ToNot(BeNil())
+					Expect(updatedProfile.Status.LastChangeImpact.Type).To(Equal(performancev2.ChangeImpactTunedReload))
+				})
+			})
+
+			Context("with the maintenance window rollout strategy", func() {
+				It("should report the next maintenance window", func() {
+					strategy := performancev2.RolloutStrategyMaintenanceWindow
+					profile.Spec.Rollout = &performancev2.Rollout{
+						Strategy: &strategy,
+						MaintenanceWindows: []performancev2.MaintenanceWindow{
+							{
+								StartTime: time.Now().UTC().Add(2 * time.Hour).Format("15:04"),
+								Duration:  metav1.Duration{Duration: time.Hour},
+							},
+						},
+					}
+					profile.Spec.RealTimeKernel.Enabled = pointer.BoolPtr(false)
+
+					r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
+					result := reconcileTimes(r, request, 1)
+					Expect(result.RequeueAfter).To(BeNumerically(">", time.Hour))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					key := types.NamespacedName{
+						Name:      profile.Name,
+						Namespace: metav1.NamespaceNone,
+					}
+					Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.PendingChange).ToNot(BeNil())
+					Expect(updatedProfile.Status.PendingChange.NextMaintenanceWindow).ToNot(BeNil())
+				})
+			})
+
+			Context("with overlays", func() {
+				var overlay *performancev2.PerformanceProfile
+
+				BeforeEach(func() {
+					overlay = &performancev2.PerformanceProfile{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: "overlay",
+						},
+						Spec: performancev2.PerformanceProfileSpec{
+							HugePages: &performancev2.HugePages{
+								Pages: []performancev2.HugePage{
+									{
+										Count: 8,
+										Size:  "2M",
+										Node:  pointer.Int32Ptr(0),
+									},
+								},
+							},
+							MachineConfigLabel:        profile.Spec.MachineConfigLabel,
+							MachineConfigPoolSelector: profile.Spec.MachineConfigPoolSelector,
+							NodeSelector:              profile.Spec.NodeSelector,
+							Overlay: &performancev2.Overlay{
+								BaseProfile: profile.Name,
+							},
+						},
+					}
+				})
+
+				It("should merge the overlay into the base profile components", func() {
+					r := newFakeReconciler(profile, overlay, mc, kc, tunedPerformance, runtimeClass)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					key := types.NamespacedName{
+						Name:      machineconfig.GetMachineConfigName(profile),
+						Namespace: metav1.NamespaceNone,
+					}
+					updatedMC := &mcov1.MachineConfig{}
+					Expect(r.Get(context.TODO(), key, updatedMC)).ToNot(HaveOccurred())
+
+					config := &igntypes.Config{}
+					Expect(json.Unmarshal(updatedMC.Spec.Config.Raw, config)).ToNot(HaveOccurred())
+					Expect(config.Systemd.Units).To(ContainElement(MatchFields(IgnoreMissing|IgnoreExtras, Fields{
+						"Contents": And(
+							ContainSubstring("Environment=HUGEPAGES_COUNT=8"),
+							ContainSubstring("Environment=HUGEPAGES_SIZE=2048"),
+						),
+					})))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					key = types.NamespacedName{
+						Name:      profile.Name,
+						Namespace: metav1.NamespaceNone,
+					}
+					Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.Overlays).ToNot(BeNil())
+					Expect(updatedProfile.Status.Overlays.Applied).To(Equal([]string{overlay.Name}))
+					Expect(updatedProfile.Status.Overlays.Conflicts).To(BeEmpty())
+				})
+
+				It("should not create components for the overlay", func() {
+					r := newFakeReconciler(profile, overlay)
+					overlayRequest := reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: overlay.Name},
+					}
+					Expect(reconcileTimes(r, overlayRequest, 2)).To(Equal(reconcile.Result{}))
+
+					tunedList := &tunedv1.TunedList{}
+					Expect(r.List(context.TODO(), tunedList)).ToNot(HaveOccurred())
+					Expect(tunedList.Items).To(BeEmpty())
+
+					updatedOverlay := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), overlayRequest.NamespacedName, updatedOverlay)).ToNot(HaveOccurred())
+					Expect(hasFinalizer(updatedOverlay, finalizer)).To(BeFalse())
+					Expect(updatedOverlay.Status.Tuned).To(BeNil())
+
+					availableCondition := conditionsv1.FindStatusCondition(updatedOverlay.Status.Conditions, conditionsv1.ConditionAvailable)
+					Expect(availableCondition).ToNot(BeNil())
+					Expect(availableCondition.Status).To(Equal(corev1.ConditionTrue))
+				})
+
+				It("should report the missing base profile under the overlay status", func() {
+					r := newFakeReconciler(overlay)
+					overlayRequest := reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: overlay.Name},
+					}
+					Expect(reconcileTimes(r, overlayRequest, 1)).To(Equal(reconcile.Result{}))
+
+					updatedOverlay := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), overlayRequest.NamespacedName, updatedOverlay)).ToNot(HaveOccurred())
+
+					degradedCondition := conditionsv1.FindStatusCondition(updatedOverlay.Status.Conditions, conditionsv1.ConditionDegraded)
+					Expect(degradedCondition).ToNot(BeNil())
+					Expect(degradedCondition.Status).To(Equal(corev1.ConditionTrue))
+					Expect(degradedCondition.Reason).To(Equal(conditionReasonBaseProfileNotFound))
+				})
+
+				It("should map the overlay to the base profile", func() {
+					r := newFakeReconciler(profile, overlay)
+					requests := r.overlayToBaseProfile(overlay)
+					Expect(requests).To(HaveLen(1))
+					Expect(requests[0].Name).To(Equal(profile.Name))
+
+					Expect(r.overlayToBaseProfile(profile)).To(BeEmpty())
+				})
+
+				It("should enqueue the old base profile when the overlay changes the base profile", func() {
+					r := newFakeReconciler(profile, overlay)
+					updatedOverlay := overlay.DeepCopy()
+					updatedOverlay.Spec.Overlay.BaseProfile = "another"
+
+					queue := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+					defer queue.ShutDown()
+					r.getOverlayEventHandler().Update(event.UpdateEvent{ObjectOld: overlay, ObjectNew: updatedOverlay}, queue)
+
+					var names []string
+					for queue.Len() != 0 {
+						item, _ := queue.Get()
+						names = append(names, item.(reconcile.Request).Name)
+						queue.Done(item)
+					}
+					Expect(names).To(ConsistOf(profile.Name, "another"))
+				})
+
+				It("should report the invalid merged spec under the Degraded condition", func() {
+					overlay.Spec.CPU = &performancev2.CPU{
+						ReservedCount: pointer.Int32Ptr(2),
+					}
+
+					r := newFakeReconciler(profile, overlay)
+					degradedCondition := reconcileDegraded(r, request, conditionReasonValidationFailed)
+					Expect(degradedCondition.Message).To(ContainSubstring("the profile merged with overlays overlay is invalid"))
+				})
+
+				It("should report the overlay that selects other nodes under the overlay status", func() {
+					overlay.Spec.NodeSelector = map[string]string{"another": "node"}
+					r := newFakeReconciler(profile, overlay)
+					overlayRequest := reconcile.Request{
+						NamespacedName: types.NamespacedName{Name: overlay.Name},
+					}
+					Expect(reconcileTimes(r, overlayRequest, 1)).To(Equal(reconcile.Result{}))
+
+					updatedOverlay := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), overlayRequest.NamespacedName, updatedOverlay)).ToNot(HaveOccurred())
+
+					degradedCondition := conditionsv1.FindStatusCondition(updatedOverlay.Status.Conditions, conditionsv1.ConditionDegraded)
+					Expect(degradedCondition).ToNot(BeNil())
+					Expect(degradedCondition.Reason).To(Equal(conditionReasonBaseProfileMismatch))
+				})
+
+				It("should report the overlay that selects other nodes under the base profile status", func() {
+					overlay.Spec.NodeSelector = map[string]string{"another": "node"}
+					r := newFakeReconciler(profile, overlay, mc, kc, tunedPerformance, runtimeClass)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					key := types.NamespacedName{
+						Name:      profile.Name,
+						Namespace: metav1.NamespaceNone,
+					}
+					Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.Overlays).ToNot(BeNil())
+					Expect(updatedProfile.Status.Overlays.Applied).To(BeEmpty())
+					Expect(updatedProfile.Status.Overlays.Ignored).To(Equal([]string{overlay.Name}))
+				})
+			})
+
+			Context("with the reserved CPUs count", func() {
+				var node *corev1.Node
+
+				BeforeEach(func() {
+					profile.Spec.CPU = &performancev2.CPU{
+						ReservedCount: pointer.Int32Ptr(2),
+					}
+
+					node = &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   "node",
+							Labels: profile.Spec.NodeSelector,
+							Annotations: map[string]string{
+								performancev2.CPUTopologyAnnotation: `{"nodes":[{"id":0,"cores":[` +
+									`{"id":0,"logical_processors":[0,4]},{"id":1,"logical_processors":[1,5]},` +
+									`{"id":2,"logical_processors":[2,6]},{"id":3,"logical_processors":[3,7]}]}]}`,
+							},
+						},
+					}
+				})
+
+				It("should compute CPUs from the node topology", func() {
+					r := newFakeReconciler(profile, node)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					key := types.NamespacedName{
+						Name:      components.GetComponentName(profile.Name, components.ProfileNamePerformance),
+						Namespace: components.NamespaceNodeTuningOperator,
+					}
+					tuned := &tunedv1.Tuned{}
+					Expect(r.Get(context.TODO(), key, tuned)).ToNot(HaveOccurred())
+					Expect(*tuned.Spec.Profile[0].Data).To(ContainSubstring("isolated_cores=1-3,5-7"))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.CPU).To(Equal(&performancev2.CPUStatus{
+						Reserved: "0,4",
+						Isolated: "1-3,5-7",
+					}))
+				})
+
+				It("should report the missing node topology under the profile status", func() {
+					node.Annotations = nil
+					r := newFakeReconciler(profile, node)
+					reconcileDegraded(r, request, conditionFailedComputingCPUs)
+				})
+
+				It("should discover the node topology with the discovery pod", func() {
+					node.Annotations = nil
+					r := newFakeReconciler(profile, node)
+					r.OperatorImage = "operator-image"
+					_, err := r.Reconcile(context.TODO(), request)
+					Expect(err).To(HaveOccurred())
+
+					key := types.NamespacedName{
+						Name:      cpuTopologyDiscoveryPodPrefix + node.Name,
+						Namespace: performancev2.ReferencedConfigMapsNamespace,
+					}
+					pod := &corev1.Pod{}
+					Expect(r.Get(context.TODO(), key, pod)).ToNot(HaveOccurred())
+					Expect(pod.Spec.NodeName).To(Equal(node.Name))
+					Expect(pod.Spec.Containers[0].Image).To(Equal("operator-image"))
+					Expect(pod.Spec.Containers[0].Command).To(Equal([]string{operatorCommand, cpuTopologyDiscoveryCommand}))
+					Expect(isControlledByProfile(pod, profile)).To(BeTrue())
+
+					topologyInfo, err := cpuallocation.ParseTopology(`{"nodes":[{"id":0,"cores":[` +
+						`{"id":0,"logical_processors":[0,4]},{"id":1,"logical_processors":[1,5]},` +
+						`{"id":2,"logical_processors":[2,6]},{"id":3,"logical_processors":[3,7]}]}]}`)
+					Expect(err).ToNot(HaveOccurred())
+					report, err := cpuallocation.FormatTopologyReport(topologyInfo)
+					Expect(err).ToNot(HaveOccurred())
+
+					pod.Status.Phase = corev1.PodSucceeded
+					pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+						{
+							Name: pod.Spec.Containers[0].Name,
+							State: corev1.ContainerState{
+								Terminated: &corev1.ContainerStateTerminated{
+									Message: report,
+								},
+							},
+						},
+					}
+					Expect(r.Update(context.TODO(), pod)).ToNot(HaveOccurred())
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedNode := &corev1.Node{}
+					Expect(r.Get(context.TODO(), types.NamespacedName{Name: node.Name}, updatedNode)).ToNot(HaveOccurred())
+					Expect(updatedNode.Annotations[performancev2.CPUTopologyAnnotation]).To(Equal(`{"nodes":[{"id":0,"cores":[` +
+						`{"id":0,"logical_processors":[0,4]},{"id":1,"logical_processors":[1,5]},` +
+						`{"id":2,"logical_processors":[2,6]},{"id":3,"logical_processors":[3,7]}]}]}`))
+					Expect(errors.IsNotFound(r.Get(context.TODO(), key, &corev1.Pod{}))).To(BeTrue())
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.CPU).To(Equal(&performancev2.CPUStatus{
+						Reserved: "0,4",
+						Isolated: "1-3,5-7",
+					}))
+				})
+
+				It("should keep the failed discovery pod", func() {
+					node.Annotations = nil
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      cpuTopologyDiscoveryPodPrefix + node.Name,
+							Namespace: performancev2.ReferencedConfigMapsNamespace,
+						},
+						Status: corev1.PodStatus{
+							Phase: corev1.PodFailed,
+						},
+					}
+					r := newFakeReconciler(profile, node, pod)
+					r.OperatorImage = "operator-image"
+					_, err := r.Reconcile(context.TODO(), request)
+					Expect(err).To(HaveOccurred())
+
+					key := types.NamespacedName{Name: pod.Name, Namespace: pod.Namespace}
+					Expect(r.Get(context.TODO(), key, &corev1.Pod{})).ToNot(HaveOccurred())
+				})
+
+				It("should map the node to the profile", func() {
+					r := newFakeReconciler(profile, node)
+					requests := r.nodeToPerformanceProfile(node)
+					Expect(requests).To(HaveLen(1))
+					Expect(requests[0].Name).To(Equal(profile.Name))
+				})
+			})
+
+			It("should report kubelet config overrides of fields owned by the operator under the Degraded condition", func() {
+				profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+					Raw: []byte(`{"maxPods":500,"cpuManagerPolicy":"none"}`),
+				}
+
+				r := newFakeReconciler(profile)
+				degradedCondition := reconcileDegraded(r, request, conditionReasonKubeletConfigOverridesConflict)
+				Expect(degradedCondition.Message).To(ContainSubstring(`the kubelet config field "cpuManagerPolicy" is owned by the operator`))
+			})
+
+			It("should report tuning overrides of settings owned by the operator under the Degraded condition", func() {
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysctl: map[string]string{"kernel.panic": "10", "kernel.sched_rt_runtime_us": "950000"},
+				}
+
+				r := newFakeReconciler(profile)
+				degradedCondition := reconcileDegraded(r, request, conditionReasonTuningOverridesConflict)
+				Expect(degradedCondition.Message).To(ContainSubstring(`the sysctl "kernel.sched_rt_runtime_us" is owned by the operator`))
+			})
+
+			Context("with the Static memory manager policy", func() {
+				It("should reserve the memory on the NUMA node of reserved CPUs", func() {
+					policy := performancev2.MemoryManagerPolicyStatic
+					profile.Spec.Kubelet = &performancev2.Kubelet{
+						MemoryManager: &performancev2.MemoryManager{Policy: &policy},
+					}
+
+					node := &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   "node",
+							Labels: profile.Spec.NodeSelector,
+							Annotations: map[string]string{
+								performancev2.CPUTopologyAnnotation: `{"nodes":[` +
+									`{"id":0,"cores":[{"id":0,"logical_processors":[4,5]},{"id":1,"logical_processors":[6,7]}]},` +
+									`{"id":1,"cores":[{"id":0,"logical_processors":[0,1]},{"id":1,"logical_processors":[2,3]}]}]}`,
+							},
+						},
+					}
+
+					r := newFakeReconciler(profile, node)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					key := types.NamespacedName{
+						Name:      components.GetComponentName(profile.Name, components.ComponentNamePrefix),
+						Namespace: metav1.NamespaceNone,
+					}
+					kc := &mcov1.KubeletConfig{}
+					Expect(r.Get(context.TODO(), key, kc)).ToNot(HaveOccurred())
+					Expect(string(kc.Spec.KubeletConfig.Raw)).To(ContainSubstring(`"reservedMemory":[{"numaNode":1,"limits":{"memory":"1100Mi"}}]`))
+				})
+			})
+
+			Context("with huge pages", func() {
+				var node *corev1.Node
+
+				BeforeEach(func() {
+					node = &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   "node",
+							Labels: profile.Spec.NodeSelector,
+						},
+						Status: corev1.NodeStatus{
+							Capacity: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("64Gi"),
+							},
+						},
+					}
+				})
+
+				It("should report the huge pages memory footprint under the profile status", func() {
+					profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages, performancev2.HugePage{
+						Size:  "2M",
+						Count: 512,
+						Node:  pointer.Int32Ptr(1),
+					})
+
+					r := newFakeReconciler(profile, node)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+					Expect(updatedProfile.Status.HugePages).ToNot(BeNil())
+					Expect(updatedProfile.Status.HugePages.Total.String()).To(Equal("5Gi"))
+					Expect(updatedProfile.Status.HugePages.Distributed.String()).To(Equal("4Gi"))
+					Expect(updatedProfile.Status.HugePages.NUMANodes).To(HaveLen(1))
+					Expect(updatedProfile.Status.HugePages.NUMANodes[0].Node).To(Equal(int32(1)))
+					Expect(updatedProfile.Status.HugePages.NUMANodes[0].Memory.String()).To(Equal("1Gi"))
+				})
+
+				It("should render huge page sizes of the architecture and the kernel page size of nodes", func() {
+					node.Labels = map[string]string{corev1.LabelArchStable: performancev2.ArchitectureARM64}
+					for key, value := range profile.Spec.NodeSelector {
+						node.Labels[key] = value
+					}
+					node.Status.NodeInfo.KernelVersion = "5.14.0-284.el9.aarch64+64k"
+
+					defaultSize := performancev2.HugePageSize("512M")
+					profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+					profile.Spec.HugePages.Pages = []performancev2.HugePage{{Size: "16G", Count: 1}}
+
+					r := newFakeReconciler(profile, node)
+					Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+					key := types.NamespacedName{
+						Name:      components.GetComponentName(profile.Name, components.ProfileNamePerformance),
+						Namespace: components.NamespaceNodeTuningOperator,
+					}
+					tuned := &tunedv1.Tuned{}
+					Expect(r.Get(context.TODO(), key, tuned)).To(Succeed())
+					Expect(*tuned.Spec.Profile[0].Data).To(ContainSubstring("hugepagesz=16G"))
+				})
+
+				It("should report nodes with different architectures under the Degraded condition", func() {
+					node.Labels = map[string]string{corev1.LabelArchStable: performancev2.ArchitectureARM64}
+					for key, value := range profile.Spec.NodeSelector {
+						node.Labels[key] = value
+					}
+
+					otherNode := node.DeepCopy()
+					otherNode.Name = "other-node"
+					otherNode.Labels[corev1.LabelArchStable] = performancev2.ArchitectureAMD64
+
+					r := newFakeReconciler(profile, node, otherNode)
+					degradedCondition := reconcileDegraded(r, request, conditionFailedGettingNodesArchitecture)
+					Expect(degradedCondition.Message).To(ContainSubstring("nodes selected by the profile have different architectures amd64, arm64"))
+				})
+
+				It("should not apply huge pages that do not fit into the node memory", func() {
+					node.Status.Capacity[corev1.ResourceMemory] = resource.MustParse("2Gi")
+
+					r := newFakeReconciler(profile, node)
+					_, err := r.Reconcile(context.TODO(), request)
+					Expect(err).To(HaveOccurred())
+
+					key := types.NamespacedName{
+						Name:      machineconfig.GetMachineConfigName(profile),
+						Namespace: metav1.NamespaceNone,
+					}
+					mc := &mcov1.MachineConfig{}
+					Expect(errors.IsNotFound(r.Get(context.TODO(), key, mc))).To(BeTrue())
+
+					updatedProfile := &performancev2.PerformanceProfile{}
+					Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+
+					degradedCondition := conditionsv1.FindStatusCondition(updatedProfile.Status.Conditions, conditionsv1.ConditionDegraded)
+					Expect(degradedCondition).ToNot(BeNil())
+					Expect(degradedCondition.Status).To(Equal(corev1.ConditionTrue))
+					Expect(degradedCondition.Reason).To(Equal(conditionReasonHugePagesExceedMemory))
+					Expect(degradedCondition.Message).To(ContainSubstring("huge pages take 4Gi, more than the memory capacity of nodes node"))
+				})
+
+				Context("with huge pages allocated at runtime", func() {
+					var tunedProfile *tunedv1.Profile
+
+					runtimePage := performancev2.HugePage{
+						Size:  "2M",
+						Count: 512,
+						Node:  pointer.Int32Ptr(1),
+					}
+
+					setAllocatedHugePages := func(count int32, timestamp time.Time) {
+						node.Annotations = map[string]string{
+							performancev2.HugePagesAllocationAnnotation: fmt.Sprintf(`{"timestamp":%q,"pages":[{"size":"2M","node":1,"count":%d}]}`,
+								timestamp.UTC().Format(time.RFC3339), count),
+						}
+					}
+
+					getMachineConfigUnits := func(r *PerformanceProfileReconciler) []string {
+						key := types.NamespacedName{
+							Name:      machineconfig.GetMachineConfigName(profile),
+							Namespace: metav1.NamespaceNone,
+						}
+						mc := &mcov1.MachineConfig{}
+						Expect(r.Get(context.TODO(), key, mc)).ToNot(HaveOccurred())
+
+						config := &igntypes.Config{}
+						Expect(json.Unmarshal(mc.Spec.Config.Raw, config)).ToNot(HaveOccurred())
+
+						var units []string
+						for _, unit := range config.Systemd.Units {
+							units = append(units, unit.Name)
+						}
+						return units
+					}
+
+					BeforeEach(func() {
+						profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+						profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages, runtimePage)
+
+						tunedProfile = &tunedv1.Profile{
+							ObjectMeta: metav1.ObjectMeta{
+								Name: node.Name,
+							},
+							Status: tunedv1.ProfileStatus{
+								Conditions: []tunedv1.ProfileStatusCondition{
+									{
+										Type:   tunedv1.TunedProfileApplied,
+										Status: corev1.ConditionTrue,
+									},
+								},
+							},
+						}
+					})
+
+					It("should report nodes that allocated huge pages at runtime", func() {
+						setAllocatedHugePages(512, time.Now())
+
+						r := newFakeReconciler(profile, node, tunedProfile)
+						Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+						Expect(getMachineConfigUnits(r)).ToNot(ContainElement("hugepages-allocation-2048kB-NUMA1.service"))
+
+						updatedProfile := &performancev2.PerformanceProfile{}
+						Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+						allocation := updatedProfile.Status.HugePages.RuntimeAllocation
+						Expect(allocation).ToNot(BeNil())
+						Expect(allocation.Pages).To(Equal([]performancev2.HugePage{runtimePage}))
+						Expect(allocation.FallbackToBoot).To(BeFalse())
+						Expect(allocation.Nodes).To(Equal([]performancev2.NodeHugePagesAllocation{
+							{Name: node.Name, State: performancev2.HugePagesAllocationStateAllocated},
+						}))
+					})
+
+					It("should wait for nodes to allocate huge pages after they were changed", func() {
+						setAllocatedHugePages(100, time.Now())
+
+						r := newFakeReconciler(profile, node, tunedProfile)
+						Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{RequeueAfter: hugePagesAllocationRequeuePeriod}))
+
+						updatedProfile := &performancev2.PerformanceProfile{}
+						Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+						allocation := updatedProfile.Status.HugePages.RuntimeAllocation
+						Expect(allocation.FallbackToBoot).To(BeFalse())
+						Expect(allocation.Nodes[0].State).To(Equal(performancev2.HugePagesAllocationStatePending))
+					})
+
+					It("should allocate huge pages on boot when nodes fail to allocate them at runtime", func() {
+						profile.Status.HugePages = &performancev2.HugePagesStatus{
+							RuntimeAllocation: &performancev2.HugePagesRuntimeAllocationStatus{
+								Pages:          []performancev2.HugePage{runtimePage},
+								LastUpdateTime: metav1.NewTime(time.Now().Add(-10 * time.Minute).Truncate(time.Second)),
+							},
+						}
+						setAllocatedHugePages(100, time.Now())
+
+						r := newFakeReconciler(profile, node, tunedProfile)
+						Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+						Expect(getMachineConfigUnits(r)).To(ContainElement("hugepages-allocation-2048kB-NUMA1.service"))
+
+						updatedProfile := &performancev2.PerformanceProfile{}
+						Expect(r.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+						allocation := updatedProfile.Status.HugePages.RuntimeAllocation
+						Expect(allocation.FallbackToBoot).To(BeTrue())
+						Expect(allocation.Nodes[0].State).To(Equal(performancev2.HugePagesAllocationStateFailed))
+						Expect(allocation.Nodes[0].Message).To(Equal("allocated 100 of 512 huge pages of size 2M on the NUMA node 1"))
+
+						By("Keeping the boot allocation after nodes allocate huge pages")
+						node.Annotations = nil
+						Expect(r.Update(context.TODO(), node)).ToNot(HaveOccurred())
+						Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+						Expect(getMachineConfigUnits(r)).To(ContainElement("hugepages-allocation-2048kB-NUMA1.service"))
+					})
+				})
+			})
+
 			It("should update status when MCP is degraded", func() {
 				mcpReason := "mcpReason"
 				mcpMessage := "MCP message"
@@ -706,8 +1677,224 @@ var _ = Describe("Controller", func() {
 				Expect(degradedCondition.Reason).To(Equal(conditionReasonTunedDegraded))
 				Expect(degradedCondition.Message).To(ContainSubstring(tunedMessage))
 			})
+
+			It("should update status with the nodes rollout state", func() {
+				mcp := &mcov1.MachineConfigPool{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "mcp-test",
+					},
+					Spec: mcov1.MachineConfigPoolSpec{
+						MachineConfigSelector: &metav1.LabelSelector{
+							MatchLabels: map[string]string{
+								testutils.MachineConfigPoolLabelKey: testutils.MachineConfigPoolLabelValue,
+							},
+						},
+						Configuration: mcov1.MachineConfigPoolStatusConfiguration{
+							ObjectReference: corev1.ObjectReference{Name: "rendered-new"},
+						},
+					},
+				}
+
+				kc.Status.Conditions = []mcov1.KubeletConfigCondition{
+					{
+						Type:   mcov1.KubeletConfigSuccess,
+						Status: corev1.ConditionTrue,
+					},
+				}
+
+				newNode := func(name string, currentConfig string, state string) *corev1.Node {
+					return &corev1.Node{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:   name,
+							Labels: profile.Spec.NodeSelector,
+							Annotations: map[string]string{
+								machineConfigDaemonCurrentConfigAnnotation: currentConfig,
+								machineConfigDaemonStateAnnotation:         state,
+							},
+						},
+					}
+				}
+
+				newTunedProfile := func(name string, applied corev1.ConditionStatus) *tunedv1.Profile {
+					return &tunedv1.Profile{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: name,
+						},
+						Status: tunedv1.ProfileStatus{
+							Conditions: []tunedv1.ProfileStatusCondition{
+								{
+									Type:   tunedv1.TunedProfileApplied,
+									Status: applied,
+								},
+							},
+						},
+					}
+				}
+
+				r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass, mcp,
+					newNode("node-updated", "rendered-new", machineConfigDaemonStateDone),
+					newNode("node-updating", "rendered-old", "Working"),
+					newNode("node-degraded", "rendered-old", machineConfigDaemonStateDegraded),
+					newTunedProfile("node-updated", corev1.ConditionTrue),
+					newTunedProfile("node-updating", corev1.ConditionFalse),
+				)
+				Expect(reconcileTimes(r, request, 1)).To(Equal(reconcile.Result{}))
+
+				updatedProfile := &performancev2.PerformanceProfile{}
+				key := types.NamespacedName{
+					Name:      profile.Name,
+					Namespace: metav1.NamespaceNone,
+				}
+				Expect(r.Get(context.TODO(), key, updatedProfile)).ToNot(HaveOccurred())
+
+				nodesStatus := updatedProfile.Status.Nodes
+				Expect(nodesStatus).ToNot(BeNil())
+				Expect(nodesStatus.Total).To(Equal(int32(3)))
+				Expect(nodesStatus.Updated).To(Equal(int32(1)))
+				Expect(nodesStatus.Updating).To(Equal(int32(1)))
+				Expect(nodesStatus.Degraded).To(Equal(int32(1)))
+				Expect(nodesStatus.Nodes).To(HaveLen(3))
+
+				// nodes are sorted by the name
+				Expect(nodesStatus.Nodes[0].Name).To(Equal("node-degraded"))
+				Expect(nodesStatus.Nodes[0].State).To(Equal(performancev2.NodeRolloutStateDegraded))
+
+				Expect(nodesStatus.Nodes[1].Name).To(Equal("node-updated"))
+				Expect(nodesStatus.Nodes[1].State).To(Equal(performancev2.NodeRolloutStateUpdated))
+				Expect(nodesStatus.Nodes[1].CurrentMachineConfig).To(Equal("rendered-new"))
+				Expect(nodesStatus.Nodes[1].DesiredMachineConfig).To(Equal("rendered-new"))
+				Expect(nodesStatus.Nodes[1].TunedProfileApplied).To(BeTrue())
+				Expect(nodesStatus.Nodes[1].KubeletConfigState).To(Equal(performancev2.KubeletConfigStateApplied))
+
+				Expect(nodesStatus.Nodes[2].Name).To(Equal("node-updating"))
+				Expect(nodesStatus.Nodes[2].State).To(Equal(performancev2.NodeRolloutStateUpdating))
+				Expect(nodesStatus.Nodes[2].CurrentMachineConfig).To(Equal("rendered-old"))
+				Expect(nodesStatus.Nodes[2].TunedProfileApplied).To(BeFalse())
+				Expect(nodesStatus.Nodes[2].KubeletConfigState).To(Equal(performancev2.KubeletConfigStatePending))
+			})
+		})
+
+	})
+
+	Context("with the change impact estimation", func() {
+		var newProfile *performancev2.PerformanceProfile
+
+		BeforeEach(func() {
+			newProfile = profile.DeepCopy()
+		})
+
+		It("should estimate the change of CPUs computed from the node topology", func() {
+			profile.Spec.CPU = &performancev2.CPU{
+				ReservedCount: pointer.Int32Ptr(2),
+			}
+			newProfile.Spec.CPU = &performancev2.CPU{
+				ReservedCount: pointer.Int32Ptr(4),
+			}
+			node := &corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "node",
+					Labels: profile.Spec.NodeSelector,
+					Annotations: map[string]string{
+						performancev2.CPUTopologyAnnotation: `{"nodes":[{"id":0,"cores":[` +
+							`{"id":0,"logical_processors":[0,4]},{"id":1,"logical_processors":[1,5]},` +
+							`{"id":2,"logical_processors":[2,6]},{"id":3,"logical_processors":[3,7]}]}]}`,
+					},
+				},
+			}
+
+			r := newFakeReconciler(profile, node)
+			impact, err := r.GetChangeImpact(profile, newProfile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+			Expect(impact.RebootRequired).To(BeTrue())
+
+			impact, err = r.GetChangeImpact(profile, profile.DeepCopy())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactNone))
+
+			// CPUs can not be computed without the node topology
+			node.Annotations = nil
+			r = newFakeReconciler(profile, node)
+			_, err = r.GetChangeImpact(profile, newProfile)
+			Expect(err).To(MatchError(ContainSubstring("none of the nodes selected by the profile reported the CPU topology")))
+		})
+
+		It("should estimate the change of the overlay for its base profile", func() {
+			overlay := &performancev2.PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: "overlay"},
+				Spec: performancev2.PerformanceProfileSpec{
+					NodeSelector:                    profile.Spec.NodeSelector,
+					MachineConfigPoolSelector:       profile.Spec.MachineConfigPoolSelector,
+					GloballyDisableIrqLoadBalancing: pointer.BoolPtr(true),
+					Overlay:                         &performancev2.Overlay{BaseProfile: profile.Name},
+				},
+			}
+
+			r := newFakeReconciler(profile)
+			impact, err := r.GetChangeImpact(nil, overlay)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactTunedReload))
+			Expect(impact.Components).To(ConsistOf("Tuned"))
+
+			// the base profile is estimated with its stored overlays
+			r = newFakeReconciler(profile, overlay)
+			impact, err = r.GetChangeImpact(profile, newProfile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactNone))
+
+			// the overlay of the base profile that does not exist does not change components
+			overlay.Spec.Overlay.BaseProfile = "missing"
+			impact, err = r.GetChangeImpact(nil, overlay)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactNone))
+		})
+
+		It("should estimate the overlay converted to the base profile as created", func() {
+			overlay := &performancev2.PerformanceProfile{
+				ObjectMeta: metav1.ObjectMeta{Name: profile.Name},
+				Spec: performancev2.PerformanceProfileSpec{
+					NodeSelector: profile.Spec.NodeSelector,
+					Overlay:      &performancev2.Overlay{BaseProfile: "base"},
+				},
+			}
+
+			r := newFakeReconciler(overlay)
+			impact, err := r.GetChangeImpact(overlay, newProfile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+			Expect(impact.Components).To(ConsistOf("MachineConfig", "KubeletConfig", "Tuned", "RuntimeClass"))
 		})
 
+		It("should estimate the change of user tuned profiles read from ConfigMaps", func() {
+			configMap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "tuned-profiles", Namespace: performancev2.ReferencedConfigMapsNamespace},
+				Data: map[string]string{
+					"ksm":     "[sysfs]\n/sys/kernel/mm/ksm/run=0\n",
+					"ksm-new": "[sysfs]\n/sys/kernel/mm/ksm/run=1\n",
+				},
+			}
+			profile.Spec.TunedProfiles = []performancev2.TunedProfileFragment{
+				{
+					Name: "ksm",
+					ConfigMapRef: &performancev2.TunedProfileConfigMapReference{
+						Name:      configMap.Name,
+						Namespace: configMap.Namespace,
+						Key:       pointer.StringPtr("ksm"),
+					},
+				},
+			}
+			newProfile = profile.DeepCopy()
+			newProfile.Spec.TunedProfiles[0].ConfigMapRef.Key = pointer.StringPtr("ksm-new")
+
+			r := newFakeReconciler(profile, configMap)
+			impact, err := r.GetChangeImpact(profile, newProfile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(impact.Type).To(Equal(performancev2.ChangeImpactTunedReload))
+
+			newProfile.Spec.TunedProfiles[0].ConfigMapRef.Key = pointer.StringPtr("missing")
+			_, err = r.GetChangeImpact(profile, newProfile)
+			Expect(err).To(MatchError(ContainSubstring(`does not have the "missing" key of the tuned profile "ksm"`)))
+		})
 	})
 
 	Context("with profile with deletion timestamp", func() {
@@ -729,7 +1916,8 @@ var _ = Describe("Controller", func() {
 			tunedPerformance, err := tuned.NewNodePerformance(assetsDir, profile)
 			Expect(err).ToNot(HaveOccurred())
 
-			runtimeClass := runtimeclass.New(profile, machineconfig.HighPerformanceRuntime)
+			runtimeClass := runtimeclass.New(profile, machineconfig.HighPerformanceRuntimeAnnotations)
+			Expect(controllerutil.SetControllerReference(profile, runtimeClass, scheme.Scheme)).To(Succeed())
 
 			r := newFakeReconciler(profile, mc, kc, tunedPerformance, runtimeClass)
 			result, err := r.Reconcile(context.TODO(), request)
@@ -796,6 +1984,29 @@ var _ = Describe("Controller", func() {
 		Expect(requests).NotTo(BeEmpty())
 		Expect(requests[0].Name).To(Equal(profile.Name))
 	})
+
+	It("should map tuned profile to the performance profile", func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "node-test",
+				Labels: profile.Spec.NodeSelector,
+			},
+		}
+		tunedProfile := &tunedv1.Profile{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      node.Name,
+				Namespace: components.NamespaceNodeTuningOperator,
+			},
+		}
+		r := newFakeReconciler(profile, node, tunedProfile)
+		requests := r.tunedProfileToPerformanceProfile(tunedProfile)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Name).To(Equal(profile.Name))
+
+		node.Labels = nil
+		r = newFakeReconciler(profile, node, tunedProfile)
+		Expect(r.tunedProfileToPerformanceProfile(tunedProfile)).To(BeEmpty())
+	})
 })
 
 func reconcileTimes(reconciler *PerformanceProfileReconciler, request reconcile.Request, times int) reconcile.Result {
@@ -808,14 +2019,33 @@ func reconcileTimes(reconciler *PerformanceProfileReconciler, request reconcile.
 	return result
 }
 
+// reconcileDegraded reconciles the profile once, expects the reconcile to fail and returns the degraded
+// condition of the profile after verifying it has the expected reason
+func reconcileDegraded(reconciler *PerformanceProfileReconciler, request reconcile.Request, reason string) *conditionsv1.Condition {
+	_, err := reconciler.Reconcile(context.TODO(), request)
+	Expect(err).To(HaveOccurred())
+
+	updatedProfile := &performancev2.PerformanceProfile{}
+	Expect(reconciler.Get(context.TODO(), request.NamespacedName, updatedProfile)).ToNot(HaveOccurred())
+
+	degradedCondition := conditionsv1.FindStatusCondition(updatedProfile.Status.Conditions, conditionsv1.ConditionDegraded)
+	Expect(degradedCondition).ToNot(BeNil())
+	Expect(degradedCondition.Status).To(Equal(corev1.ConditionTrue))
+	Expect(degradedCondition.Reason).To(Equal(reason))
+	return degradedCondition
+}
+
 // newFakeReconciler returns a new reconcile.Reconciler with a fake client
 func newFakeReconciler(initObjects ...runtime.Object) *PerformanceProfileReconciler {
 	fakeClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(initObjects...).Build()
 	fakeRecorder := record.NewFakeRecorder(10)
 	return &PerformanceProfileReconciler{
-		Client:    fakeClient,
-		Scheme:    scheme.Scheme,
-		Recorder:  fakeRecorder,
-		AssetsDir: assetsDir,
+		Client:          fakeClient,
+		Scheme:          scheme.Scheme,
+		Recorder:        fakeRecorder,
+		AssetsDir:       assetsDir,
+		ConfigMapReader: fakeClient,
+		PodReader:       fakeClient,
+		Namespace:       performancev2.ReferencedConfigMapsNamespace,
 	}
 }
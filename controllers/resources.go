@@ -3,8 +3,10 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"reflect"
 
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
@@ -274,7 +276,7 @@ func (r *PerformanceProfileReconciler) getRuntimeClass(name string) (*nodev1beta
 	return runtimeClass, nil
 }
 
-func (r *PerformanceProfileReconciler) getMutatedRuntimeClass(runtimeClass *nodev1beta1.RuntimeClass) (*nodev1beta1.RuntimeClass, error) {
+func (r *PerformanceProfileReconciler) getMutatedRuntimeClass(profile *performancev2.PerformanceProfile, runtimeClass *nodev1beta1.RuntimeClass) (*nodev1beta1.RuntimeClass, error) {
 	existing, err := r.getRuntimeClass(runtimeClass.Name)
 	if errors.IsNotFound(err) {
 		return runtimeClass, nil
@@ -284,14 +286,21 @@ func (r *PerformanceProfileReconciler) getMutatedRuntimeClass(runtimeClass *node
 		return nil, err
 	}
 
+	// runtime classes of other owners are not mutated, the update of such runtime class fails
+	if !isControlledByProfile(existing, profile) {
+		return runtimeClass, nil
+	}
+
 	mutated := existing.DeepCopy()
 	mergeMaps(runtimeClass.Annotations, mutated.Annotations)
 	mergeMaps(runtimeClass.Labels, mutated.Labels)
 	mutated.Handler = runtimeClass.Handler
+	mutated.Overhead = runtimeClass.Overhead
 	mutated.Scheduling = runtimeClass.Scheduling
 
 	// we do not need to update if it no change between mutated and existing object
 	if apiequality.Semantic.DeepEqual(existing.Handler, mutated.Handler) &&
+		apiequality.Semantic.DeepEqual(existing.Overhead, mutated.Overhead) &&
 		apiequality.Semantic.DeepEqual(existing.Scheduling, mutated.Scheduling) &&
 		apiequality.Semantic.DeepEqual(existing.Labels, mutated.Labels) &&
 		apiequality.Semantic.DeepEqual(existing.Annotations, mutated.Annotations) {
@@ -301,8 +310,11 @@ func (r *PerformanceProfileReconciler) getMutatedRuntimeClass(runtimeClass *node
 	return mutated, nil
 }
 
-func (r *PerformanceProfileReconciler) createOrUpdateRuntimeClass(runtimeClass *nodev1beta1.RuntimeClass) error {
-	_, err := r.getRuntimeClass(runtimeClass.Name)
+// createOrUpdateRuntimeClass creates or updates the runtime class controlled by the profile, it refuses to update
+// runtime classes of other owners, and recreates the runtime class when the handler changes, because the handler
+// of a runtime class is immutable
+func (r *PerformanceProfileReconciler) createOrUpdateRuntimeClass(profile *performancev2.PerformanceProfile, runtimeClass *nodev1beta1.RuntimeClass) error {
+	existing, err := r.getRuntimeClass(runtimeClass.Name)
 	if errors.IsNotFound(err) {
 		klog.Infof("Create runtime class %q", runtimeClass.Name)
 		if err := r.Create(context.TODO(), runtimeClass); err != nil {
@@ -315,11 +327,27 @@ func (r *PerformanceProfileReconciler) createOrUpdateRuntimeClass(runtimeClass *
 		return err
 	}
 
+	if !isControlledByProfile(existing, profile) {
+		return fmt.Errorf("the runtime class %q already exists and is not owned by the performance profile %q", runtimeClass.Name, profile.Name)
+	}
+
+	if existing.Handler != runtimeClass.Handler {
+		klog.Infof("Recreate runtime class %q with the handler %q", runtimeClass.Name, runtimeClass.Handler)
+		if err := r.Delete(context.TODO(), existing); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+
+		runtimeClass.ResourceVersion = ""
+		runtimeClass.UID = ""
+		return r.Create(context.TODO(), runtimeClass)
+	}
+
 	klog.Infof("Update runtime class %q", runtimeClass.Name)
 	return r.Update(context.TODO(), runtimeClass)
 }
 
-func (r *PerformanceProfileReconciler) deleteRuntimeClass(name string) error {
+// deleteRuntimeClass deletes the runtime class controlled by the profile, runtime classes of other owners are kept
+func (r *PerformanceProfileReconciler) deleteRuntimeClass(profile *performancev2.PerformanceProfile, name string) error {
 	runtimeClass, err := r.getRuntimeClass(name)
 	if errors.IsNotFound(err) {
 		return nil
@@ -327,5 +355,41 @@ func (r *PerformanceProfileReconciler) deleteRuntimeClass(name string) error {
 	if err != nil {
 		return err
 	}
+
+	if !isControlledByProfile(runtimeClass, profile) {
+		return nil
+	}
 	return r.Delete(context.TODO(), runtimeClass)
 }
+
+// deleteStaleRuntimeClasses deletes runtime classes controlled by the profile with a name other than the current one,
+// they remain after the runtime class of the profile is renamed
+func (r *PerformanceProfileReconciler) deleteStaleRuntimeClasses(profile *performancev2.PerformanceProfile, name string) error {
+	runtimeClasses := &nodev1beta1.RuntimeClassList{}
+	if err := r.List(context.TODO(), runtimeClasses); err != nil {
+		return err
+	}
+
+	for i := range runtimeClasses.Items {
+		runtimeClass := &runtimeClasses.Items[i]
+		if runtimeClass.Name == name {
+			continue
+		}
+
+		if !isControlledByProfile(runtimeClass, profile) {
+			continue
+		}
+
+		klog.Infof("Delete stale runtime class %q", runtimeClass.Name)
+		if err := r.Delete(context.TODO(), runtimeClass); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isControlledByProfile returns whether or not the object is controlled by the profile
+func isControlledByProfile(obj metav1.Object, profile *performancev2.PerformanceProfile) bool {
+	owner := metav1.GetControllerOf(obj)
+	return owner != nil && owner.Kind == "PerformanceProfile" && owner.Name == profile.Name && owner.UID == profile.UID
+}
@@ -3,11 +3,15 @@ package controllers
 import (
 	"bytes"
 	"context"
+	"reflect"
+	"sort"
 	"time"
 
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/manifestset"
 	profileutil "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/metrics"
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
@@ -21,17 +25,40 @@ import (
 )
 
 const (
-	conditionReasonValidationFailed          = "ValidationFailed"
-	conditionReasonComponentsCreationFailed  = "ComponentCreationFailed"
-	conditionReasonMCPDegraded               = "MCPDegraded"
-	conditionFailedGettingMCPStatus          = "GettingMCPStatusFailed"
-	conditionKubeletFailed                   = "KubeletConfig failure"
-	conditionFailedGettingKubeletStatus      = "GettingKubeletStatusFailed"
-	conditionReasonTunedDegraded             = "TunedProfileDegraded"
-	conditionFailedGettingTunedProfileStatus = "GettingTunedStatusFailed"
+	conditionReasonValidationFailed               = "ValidationFailed"
+	conditionReasonComponentsCreationFailed       = "ComponentCreationFailed"
+	conditionReasonMCPDegraded                    = "MCPDegraded"
+	conditionFailedGettingMCPStatus               = "GettingMCPStatusFailed"
+	conditionKubeletFailed                        = "KubeletConfig failure"
+	conditionFailedGettingKubeletStatus           = "GettingKubeletStatusFailed"
+	conditionReasonTunedDegraded                  = "TunedProfileDegraded"
+	conditionFailedGettingTunedProfileStatus      = "GettingTunedStatusFailed"
+	conditionFailedGettingNodesStatus             = "GettingNodesStatusFailed"
+	conditionFailedGettingOverlays                = "GettingOverlaysFailed"
+	conditionReasonBaseProfileNotFound            = "BaseProfileNotFound"
+	conditionReasonBaseProfileMismatch            = "BaseProfileMismatch"
+	conditionFailedDiscoveringCPUTopology         = "DiscoveringCPUTopologyFailed"
+	conditionFailedComputingCPUs                  = "ComputingCPUsFailed"
+	conditionFailedGettingNodesArchitecture       = "GettingNodesArchitectureFailed"
+	conditionReasonHugePagesExceedMemory          = "HugePagesExceedMemory"
+	conditionFailedGettingHugePagesAllocation     = "GettingHugePagesAllocationFailed"
+	conditionFailedReservingMemory                = "ReservingMemoryFailed"
+	conditionReasonKubeletConfigOverridesConflict = "KubeletConfigOverridesConflict"
+	conditionReasonTuningOverridesConflict        = "TuningOverridesConflict"
+	conditionFailedGettingTunedProfiles           = "GettingTunedProfilesFailed"
 )
 
-func (r *PerformanceProfileReconciler) updateStatus(profile *performancev2.PerformanceProfile, conditions []conditionsv1.Condition) error {
+// the node annotations and states reported by the machine config daemon
+const (
+	machineConfigDaemonCurrentConfigAnnotation = "machineconfiguration.openshift.io/currentConfig"
+	machineConfigDaemonDesiredConfigAnnotation = "machineconfiguration.openshift.io/desiredConfig"
+	machineConfigDaemonStateAnnotation         = "machineconfiguration.openshift.io/state"
+	machineConfigDaemonStateDone               = "Done"
+	machineConfigDaemonStateDegraded           = "Degraded"
+	machineConfigDaemonStateUnreconcilable     = "Unreconcilable"
+)
+
+func (r *PerformanceProfileReconciler) updateStatus(profile *performancev2.PerformanceProfile, conditions []conditionsv1.Condition, applied *applyResult, nodesStatus *performancev2.NodesStatus) error {
 	profileCopy := profile.DeepCopy()
 
 	if conditions != nil {
@@ -59,7 +86,10 @@ func (r *PerformanceProfileReconciler) updateStatus(profile *performancev2.Perfo
 		}
 	}
 
-	if profileCopy.Status.Tuned == nil {
+	// overlays do not generate components
+	isOverlay := profileutil.IsOverlay(profile)
+
+	if profileCopy.Status.Tuned == nil && !isOverlay {
 		tunedNamespacedname := types.NamespacedName{
 			Name:      components.GetComponentName(profile.Name, components.ProfileNamePerformance),
 			Namespace: components.NamespaceNodeTuningOperator,
@@ -69,12 +99,67 @@ func (r *PerformanceProfileReconciler) updateStatus(profile *performancev2.Perfo
 		modified = true
 	}
 
-	if profileCopy.Status.RuntimeClass == nil {
-		runtimeClassName := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
+	// the RuntimeClass name can be changed under the profile spec
+	runtimeClassName := performancev2.GetRuntimeClassName(profile)
+	if !isOverlay && (profileCopy.Status.RuntimeClass == nil || *profileCopy.Status.RuntimeClass != runtimeClassName) {
 		profileCopy.Status.RuntimeClass = &runtimeClassName
 		modified = true
 	}
 
+	// the apply result is provided only when components were applied or held by the rollout policy
+	if applied != nil {
+		if applied.changeImpact != nil {
+			profileCopy.Status.LastChangeImpact = applied.changeImpact
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.PendingChange, applied.pendingChange) {
+			profileCopy.Status.PendingChange = applied.pendingChange
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.Overlays, applied.overlays) {
+			profileCopy.Status.Overlays = applied.overlays
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.CPU, applied.cpus) {
+			profileCopy.Status.CPU = applied.cpus
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.HugePages, applied.hugePages) {
+			profileCopy.Status.HugePages = applied.hugePages
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.Tuning, applied.tuning) {
+			profileCopy.Status.Tuning = applied.tuning
+			modified = true
+		}
+
+		if !reflect.DeepEqual(profile.Status.Components, applied.componentStatuses) {
+			profileCopy.Status.Components = applied.componentStatuses
+			modified = true
+		}
+
+		if profile.Status.ObservedGeneration != profile.Generation {
+			profileCopy.Status.ObservedGeneration = profile.Generation
+			modified = true
+		}
+	}
+
+	// the nodes status is provided only when it was collected successfully
+	if nodesStatus != nil && !reflect.DeepEqual(profile.Status.Nodes, nodesStatus) {
+		profileCopy.Status.Nodes = nodesStatus
+		modified = true
+	}
+
+	metrics.SetProfileConditions(profile.Name, profileCopy.Status.Conditions)
+	if nodesStatus != nil {
+		metrics.SetProfileNodes(profile.Name, nodesStatus)
+	}
+
 	if !modified {
 		return nil
 	}
@@ -83,6 +168,48 @@ func (r *PerformanceProfileReconciler) updateStatus(profile *performancev2.Perfo
 	return r.Status().Update(context.TODO(), profileCopy)
 }
 
+// getComponentStatuses returns the sync state of the rendered components, components with kinds
+// marked as out of sync keep the hash of the last applied content
+func getComponentStatuses(profile *performancev2.PerformanceProfile, components *manifestset.ManifestResultSet, outOfSync map[string]bool) ([]performancev2.ComponentStatus, error) {
+	hashes, err := components.GetComponentHashes()
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []struct {
+		kind string
+		obj  metav1.Object
+	}{
+		{kind: components.MachineConfig.Kind, obj: components.MachineConfig},
+		{kind: components.KubeletConfig.Kind, obj: components.KubeletConfig},
+		{kind: components.Tuned.Kind, obj: components.Tuned},
+		{kind: components.RuntimeClass.Kind, obj: components.RuntimeClass},
+	}
+
+	var statuses []performancev2.ComponentStatus
+	for _, o := range objs {
+		status := performancev2.ComponentStatus{
+			Kind:      o.kind,
+			Name:      o.obj.GetName(),
+			Namespace: o.obj.GetNamespace(),
+			Hash:      hashes[o.kind],
+			InSync:    true,
+		}
+
+		if outOfSync[o.kind] {
+			status.InSync = false
+			status.Hash = ""
+			for _, old := range profile.Status.Components {
+				if old.Kind == status.Kind && old.Name == status.Name {
+					status.Hash = old.Hash
+				}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
 func (r *PerformanceProfileReconciler) getAvailableConditions() []conditionsv1.Condition {
 	now := time.Now()
 	return []conditionsv1.Condition{
@@ -175,29 +302,13 @@ func (r *PerformanceProfileReconciler) getProgressingConditions(reason string, m
 }
 
 func (r *PerformanceProfileReconciler) getMCPConditionsByProfile(profile *performancev2.PerformanceProfile) ([]conditionsv1.Condition, error) {
-	mcpList := &mcov1.MachineConfigPoolList{}
-	if err := r.List(context.TODO(), mcpList); err != nil {
-		klog.Errorf("Cannot list Machine config pools to match with profile %q : %v", profile.Name, err)
+	mcps, err := r.getMachineConfigPoolsByProfile(profile)
+	if err != nil {
 		return nil, err
 	}
 
-	// TODO: from some reason we have double entries for each MCP during the call to list
-	// for now the code will just filter duplicates entries, but it can be nice to understand
-	// why it happens
-	filtered := removeMCPDuplicateEntries(mcpList.Items)
-
-	machineConfigPoolSelector := labels.Set(profileutil.GetMachineConfigPoolSelector(profile))
 	message := bytes.Buffer{}
-	for _, mcp := range filtered {
-		selector, err := metav1.LabelSelectorAsSelector(mcp.Spec.MachineConfigSelector)
-		if err != nil {
-			return nil, err
-		}
-
-		if !selector.Matches(machineConfigPoolSelector) {
-			continue
-		}
-
+	for _, mcp := range mcps {
 		for _, condition := range mcp.Status.Conditions {
 			if (condition.Type == mcov1.MachineConfigPoolNodeDegraded || condition.Type == mcov1.MachineConfigPoolRenderDegraded) && condition.Status == corev1.ConditionTrue {
 				if len(condition.Reason) > 0 {
@@ -218,6 +329,34 @@ func (r *PerformanceProfileReconciler) getMCPConditionsByProfile(profile *perfor
 	return r.getDegradedConditions(conditionReasonMCPDegraded, messageString), nil
 }
 
+// getMachineConfigPoolsByProfile returns machine config pools that select the machine config generated from the profile
+func (r *PerformanceProfileReconciler) getMachineConfigPoolsByProfile(profile *performancev2.PerformanceProfile) ([]mcov1.MachineConfigPool, error) {
+	mcpList := &mcov1.MachineConfigPoolList{}
+	if err := r.List(context.TODO(), mcpList); err != nil {
+		klog.Errorf("Cannot list Machine config pools to match with profile %q : %v", profile.Name, err)
+		return nil, err
+	}
+
+	// TODO: from some reason we have double entries for each MCP during the call to list
+	// for now the code will just filter duplicates entries, but it can be nice to understand
+	// why it happens
+	filtered := removeMCPDuplicateEntries(mcpList.Items)
+
+	machineConfigPoolSelector := labels.Set(profileutil.GetMachineConfigPoolSelector(profile))
+	var mcps []mcov1.MachineConfigPool
+	for _, mcp := range filtered {
+		selector, err := metav1.LabelSelectorAsSelector(mcp.Spec.MachineConfigSelector)
+		if err != nil {
+			return nil, err
+		}
+
+		if selector.Matches(machineConfigPoolSelector) {
+			mcps = append(mcps, mcp)
+		}
+	}
+	return mcps, nil
+}
+
 func (r *PerformanceProfileReconciler) getKubeletConditionsByProfile(profile *performancev2.PerformanceProfile) ([]conditionsv1.Condition, error) {
 	name := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
 	kc, err := r.getKubeletConfig(name)
@@ -295,6 +434,139 @@ func (r *PerformanceProfileReconciler) getTunedConditionsByProfile(profile *perf
 	return r.getDegradedConditions(conditionReasonTunedDegraded, messageString), nil
 }
 
+// getNodesStatusByProfile returns the rollout state of nodes selected by the profile
+func (r *PerformanceProfileReconciler) getNodesStatusByProfile(profile *performancev2.PerformanceProfile) (*performancev2.NodesStatus, error) {
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return nil, err
+	}
+
+	tunedProfileList := &tunedv1.ProfileList{}
+	if err := r.List(context.TODO(), tunedProfileList); err != nil {
+		klog.Errorf("Cannot list Tuned Profiles to match with profile %q : %v", profile.Name, err)
+		return nil, err
+	}
+
+	tunedProfiles := map[string]*tunedv1.Profile{}
+	for _, tunedProfile := range removeUnMatchedTunedProfiles(nodes.Items, tunedProfileList.Items) {
+		tunedProfiles[tunedProfile.Name] = tunedProfile.DeepCopy()
+	}
+
+	mcps, err := r.getMachineConfigPoolsByProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	// the machine config pool targets the rendered machine config that nodes should run
+	var desiredMachineConfig string
+	if len(mcps) > 0 {
+		desiredMachineConfig = mcps[0].Spec.Configuration.Name
+	}
+
+	kubeletConfigState, err := r.getKubeletConfigState(profile)
+	if err != nil {
+		return nil, err
+	}
+
+	nodesStatus := &performancev2.NodesStatus{}
+	for _, node := range nodes.Items {
+		nodeStatus := getNodeStatus(&node, desiredMachineConfig, tunedProfiles[node.Name], kubeletConfigState)
+
+		nodesStatus.Total++
+		switch nodeStatus.State {
+		case performancev2.NodeRolloutStateUpdated:
+			nodesStatus.Updated++
+		case performancev2.NodeRolloutStateDegraded:
+			nodesStatus.Degraded++
+		default:
+			nodesStatus.Updating++
+		}
+		nodesStatus.Nodes = append(nodesStatus.Nodes, nodeStatus)
+	}
+
+	sort.Slice(nodesStatus.Nodes, func(i, j int) bool {
+		return nodesStatus.Nodes[i].Name < nodesStatus.Nodes[j].Name
+	})
+	return nodesStatus, nil
+}
+
+// getKubeletConfigState returns the state of the kubelet config generated from the profile,
+// the pending state means that the kubelet config was rendered, but nodes can still run the old one
+func (r *PerformanceProfileReconciler) getKubeletConfigState(profile *performancev2.PerformanceProfile) (performancev2.KubeletConfigState, error) {
+	name := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
+	kc, err := r.getKubeletConfig(name)
+	if errors.IsNotFound(err) {
+		return performancev2.KubeletConfigStatePending, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	latestCondition := getLatestKubeletConfigCondition(kc.Status.Conditions)
+	if latestCondition == nil {
+		return performancev2.KubeletConfigStatePending, nil
+	}
+
+	if latestCondition.Type == mcov1.KubeletConfigFailure {
+		return performancev2.KubeletConfigStateFailed, nil
+	}
+
+	return performancev2.KubeletConfigStateApplied, nil
+}
+
+func getNodeStatus(node *corev1.Node, desiredMachineConfig string, tunedProfile *tunedv1.Profile, kubeletConfigState performancev2.KubeletConfigState) performancev2.NodeStatus {
+	nodeStatus := performancev2.NodeStatus{
+		Name:                 node.Name,
+		CurrentMachineConfig: node.Annotations[machineConfigDaemonCurrentConfigAnnotation],
+		DesiredMachineConfig: desiredMachineConfig,
+		MachineConfigState:   node.Annotations[machineConfigDaemonStateAnnotation],
+	}
+
+	// fallback to the node desired config when the machine config pool does not exist
+	if nodeStatus.DesiredMachineConfig == "" {
+		nodeStatus.DesiredMachineConfig = node.Annotations[machineConfigDaemonDesiredConfigAnnotation]
+	}
+
+	if tunedProfile != nil {
+		for _, condition := range tunedProfile.Status.Conditions {
+			if condition.Type == tunedv1.TunedProfileApplied && condition.Status == corev1.ConditionTrue {
+				nodeStatus.TunedProfileApplied = true
+			}
+
+			if condition.Type == tunedv1.TunedDegraded && condition.Status == corev1.ConditionTrue {
+				nodeStatus.TunedProfileDegraded = true
+			}
+		}
+	}
+
+	machineConfigUpdated := nodeStatus.CurrentMachineConfig != "" &&
+		nodeStatus.CurrentMachineConfig == nodeStatus.DesiredMachineConfig &&
+		nodeStatus.MachineConfigState == machineConfigDaemonStateDone
+
+	// the kubelet config is delivered to the node via the rendered machine config
+	nodeStatus.KubeletConfigState = kubeletConfigState
+	if kubeletConfigState == performancev2.KubeletConfigStateApplied && !machineConfigUpdated {
+		nodeStatus.KubeletConfigState = performancev2.KubeletConfigStatePending
+	}
+
+	// the same as for the degraded condition, the tuned profile is degraded only when it was not applied
+	tunedDegraded := nodeStatus.TunedProfileDegraded && !nodeStatus.TunedProfileApplied
+	machineConfigDegraded := nodeStatus.MachineConfigState == machineConfigDaemonStateDegraded ||
+		nodeStatus.MachineConfigState == machineConfigDaemonStateUnreconcilable
+
+	switch {
+	case machineConfigDegraded || tunedDegraded || nodeStatus.KubeletConfigState == performancev2.KubeletConfigStateFailed:
+		nodeStatus.State = performancev2.NodeRolloutStateDegraded
+	case machineConfigUpdated && nodeStatus.TunedProfileApplied && nodeStatus.KubeletConfigState == performancev2.KubeletConfigStateApplied:
+		nodeStatus.State = performancev2.NodeRolloutStateUpdated
+	default:
+		nodeStatus.State = performancev2.NodeRolloutStateUpdating
+	}
+	return nodeStatus
+}
+
 func getLatestKubeletConfigCondition(conditions []mcov1.KubeletConfigCondition) *mcov1.KubeletConfigCondition {
 	var latestCondition *mcov1.KubeletConfigCondition
 	for i := 0; i < len(conditions); i++ {
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// cpuTopologyDiscoveryPodPrefix is the name prefix of pods that discover the CPU topology of nodes
+	cpuTopologyDiscoveryPodPrefix = "cpu-topology-"
+	// cpuTopologyDiscoveryCommand is the operator command run by the discovery pod
+	cpuTopologyDiscoveryCommand = "discover-topology"
+	// operatorCommand is the operator binary of the operator image
+	operatorCommand = "performance-operator"
+)
+
+// discoverCPUTopology runs discovery pods on nodes selected by the profile that did not report the CPU topology yet,
+// the topology reported by the completed pod is set under the node annotation and the pod is deleted, failed pods
+// are kept to investigate the failure and should be deleted to discover the topology again
+func (r *PerformanceProfileReconciler) discoverCPUTopology(profile *performancev2.PerformanceProfile) error {
+	// the operator image is not known when the operator does not run in the cluster
+	if r.OperatorImage == "" {
+		return nil
+	}
+
+	selector := labels.SelectorFromSet(profile.Spec.NodeSelector)
+	nodes := &corev1.NodeList{}
+	if err := r.List(context.TODO(), nodes, &client.ListOptions{LabelSelector: selector}); err != nil {
+		return err
+	}
+
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		pod := &corev1.Pod{}
+		key := types.NamespacedName{
+			Namespace: r.Namespace,
+			Name:      cpuTopologyDiscoveryPodPrefix + node.Name,
+		}
+		err := r.PodReader.Get(context.TODO(), key, pod)
+		if errors.IsNotFound(err) {
+			if _, ok := node.Annotations[performancev2.CPUTopologyAnnotation]; ok {
+				continue
+			}
+
+			pod, err = r.getCPUTopologyDiscoveryPod(profile, key, node.Name)
+			if err != nil {
+				return err
+			}
+
+			klog.Infof("Discovering the CPU topology of the node %q", node.Name)
+			if err := r.Create(context.TODO(), pod); err != nil && !errors.IsAlreadyExists(err) {
+				return err
+			}
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		switch pod.Status.Phase {
+		case corev1.PodSucceeded:
+			if err := r.setNodeCPUTopology(node, pod); err != nil {
+				return err
+			}
+
+			if err := r.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+		case corev1.PodFailed:
+			r.Recorder.Eventf(profile, corev1.EventTypeWarning, "CPU topology discovery failed",
+				"Failed to discover the CPU topology of the node %q, delete the pod %s/%s to try again: %s",
+				node.Name, pod.Namespace, pod.Name, getTerminationMessage(pod))
+		}
+	}
+
+	return nil
+}
+
+// setNodeCPUTopology sets the CPU topology reported by the discovery pod under the node annotation
+func (r *PerformanceProfileReconciler) setNodeCPUTopology(node *corev1.Node, pod *corev1.Pod) error {
+	topologyInfo, err := cpuallocation.ParseTopologyReport(getTerminationMessage(pod))
+	if err != nil {
+		return fmt.Errorf("failed to parse the CPU topology reported by the pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+
+	data, err := cpuallocation.MarshalTopology(topologyInfo)
+	if err != nil {
+		return err
+	}
+
+	if node.Annotations[performancev2.CPUTopologyAnnotation] == data {
+		return nil
+	}
+
+	patch := client.MergeFrom(node.DeepCopy())
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[performancev2.CPUTopologyAnnotation] = data
+	return r.Patch(context.TODO(), node, patch)
+}
+
+// getCPUTopologyDiscoveryPod returns the pod that reports the CPU topology of the node under its termination message,
+// sysfs is readable by unprivileged containers, so the pod does not need any privileges
+func (r *PerformanceProfileReconciler) getCPUTopologyDiscoveryPod(profile *performancev2.PerformanceProfile, key types.NamespacedName, nodeName string) (*corev1.Pod, error) {
+	automountServiceAccountToken := false
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      key.Name,
+			Namespace: key.Namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                     nodeName,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: &automountServiceAccountToken,
+			// the pod should run on nodes with any taints
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "discover-topology",
+					Image:   r.OperatorImage,
+					Command: []string{operatorCommand, cpuTopologyDiscoveryCommand},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10m"),
+							corev1.ResourceMemory: resource.MustParse("50Mi"),
+						},
+					},
+					// the failed command does not write the report, so its error is taken from the log
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				},
+			},
+		},
+	}
+
+	// the pod is removed together with the profile
+	if err := controllerutil.SetControllerReference(profile, pod, r.Scheme); err != nil {
+		return nil, err
+	}
+	return pod, nil
+}
+
+// getTerminationMessage returns the termination message of the first terminated container of the pod
+func getTerminationMessage(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Terminated != nil {
+			return status.State.Terminated.Message
+		}
+	}
+	return ""
+}
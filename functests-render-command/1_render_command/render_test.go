@@ -61,6 +61,30 @@ var _ = Describe("render command e2e test", func() {
 		})
 	})
 
+	Context("With the diff-against directory", func() {
+		It("Gets cli args and prints no changes against the expected components", func() {
+			cmdline := []string{
+				filepath.Join(binPath, "performance-addon-operators"),
+				"render",
+				"--performance-profile-input-files", ppInFiles,
+				"--asset-input-dir", assetsInDir,
+				"--diff-against", filepath.Join(testDataPath, "render-expected-output"),
+			}
+			fmt.Fprintf(GinkgoWriter, "running: %v\n", cmdline)
+
+			cmd := exec.Command(cmdline[0], cmdline[1:]...)
+			out, err := cmd.Output()
+			Expect(err).ToNot(HaveOccurred())
+
+			output := string(out)
+			Expect(output).To(ContainSubstring(`MachineConfig "50-performance-manual": no changes`))
+			Expect(output).To(ContainSubstring(`KubeletConfig "performance-manual": no changes`))
+			Expect(output).To(ContainSubstring(`Tuned "openshift-node-performance-manual": no changes`))
+			Expect(output).To(ContainSubstring(`RuntimeClass "performance-manual": no changes`))
+			Expect(output).To(ContainSubstring("Node reboot required: no"))
+		})
+	})
+
 	AfterEach(func() {
 		cleanArtifacts()
 	})
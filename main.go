@@ -17,9 +17,13 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
+	"os"
 	"runtime"
+	"strings"
 
 	performancev1 "github.com/openshift-kni/performance-addon-operators/api/v1"
 	performancev1alpha1 "github.com/openshift-kni/performance-addon-operators/api/v1alpha1"
@@ -32,19 +36,23 @@ import (
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	"github.com/openshift-kni/performance-addon-operators/pkg/cmd/render"
+	"github.com/openshift-kni/performance-addon-operators/pkg/cmd/topology"
 	apiruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
 )
@@ -55,6 +63,10 @@ const (
 	webhookCertDir   = "/apiserver.local.config/certificates"
 	webhookCertName  = "apiserver.crt"
 	webhookKeyName   = "apiserver.key"
+	// operatorContainerName is the container of the operator deployment
+	operatorContainerName = "performance-operator"
+	// serviceAccountNamespaceFile contains the namespace of the pod
+	serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 )
 
 // Change below variables to serve metrics on different host or port.
@@ -105,6 +117,7 @@ func newRootCommand() *cobra.Command {
 	}
 
 	cmd.AddCommand(render.NewRenderCommand())
+	cmd.AddCommand(topology.NewDiscoverTopologyCommand())
 	return cmd
 }
 
@@ -152,12 +165,28 @@ func runPAO() {
 		klog.Exit(err.Error())
 	}
 
-	if err = (&controllers.PerformanceProfileReconciler{
-		Client:    mgr.GetClient(),
-		Scheme:    mgr.GetScheme(),
-		Recorder:  mgr.GetEventRecorderFor("performance-profile-controller"),
-		AssetsDir: components.AssetsDir,
-	}).SetupWithManager(mgr); err != nil {
+	// referenced ConfigMaps and discovery pods belong to the namespace the operator runs in
+	namespace, err := getOperatorNamespace()
+	if err != nil {
+		klog.Warningf("failed to get the operator namespace, using %q: %v", performancev2.ReferencedConfigMapsNamespace, err)
+		namespace = performancev2.ReferencedConfigMapsNamespace
+	}
+
+	// CPU topology discovery pods run the operator image
+	operatorImage, err := getOperatorImage(mgr.GetAPIReader(), namespace)
+	if err != nil {
+		klog.Warningf("failed to get the operator image, the CPU topology of nodes will not be discovered: %v", err)
+	}
+
+	reconciler := &controllers.PerformanceProfileReconciler{
+		Client:        mgr.GetClient(),
+		Scheme:        mgr.GetScheme(),
+		Recorder:      mgr.GetEventRecorderFor("performance-profile-controller"),
+		AssetsDir:     components.AssetsDir,
+		OperatorImage: operatorImage,
+		Namespace:     namespace,
+	}
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		klog.Exitf("unable to create PerformanceProfile controller : %v", err)
 	}
 
@@ -171,6 +200,10 @@ func runPAO() {
 	if err = (&performancev1.PerformanceProfile{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Exitf("unable to create PerformanceProfile v1 webhook : %v", err)
 	}
+	// referenced ConfigMaps are read from the operator namespace, so the webhook rejects references to other namespaces
+	performancev2.SetOperatorNamespace(namespace)
+	// the impact is estimated from components rendered the same way the controller renders them
+	performancev2.SetChangeImpactEstimator(reconciler.GetChangeImpact)
 	if err = (&performancev2.PerformanceProfile{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Exitf("unable to create PerformanceProfile v2 webhook : %v", err)
 	}
@@ -183,3 +216,35 @@ func runPAO() {
 		klog.Exitf("Manager exited with non-zero code: %v", err)
 	}
 }
+
+// getOperatorNamespace returns the namespace of the operator pod read from the service account mounted into the pod
+func getOperatorNamespace() (string, error) {
+	namespace, err := ioutil.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(namespace)), nil
+}
+
+// getOperatorImage returns the image of the operator pod, the pod name is set by the deployment
+func getOperatorImage(reader client.Reader, namespace string) (string, error) {
+	key := types.NamespacedName{
+		Namespace: namespace,
+		Name:      os.Getenv("POD_NAME"),
+	}
+	if key.Name == "" {
+		return "", fmt.Errorf("the POD_NAME environment variable should be set")
+	}
+
+	pod := &corev1.Pod{}
+	if err := reader.Get(context.TODO(), key, pod); err != nil {
+		return "", err
+	}
+
+	for _, container := range pod.Spec.Containers {
+		if container.Name == operatorContainerName {
+			return container.Image, nil
+		}
+	}
+	return "", fmt.Errorf("the pod %s/%s does not have the %q container", key.Namespace, key.Name, operatorContainerName)
+}
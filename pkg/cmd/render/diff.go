@@ -0,0 +1,289 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/manifestset"
+	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
+	mcov1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
+	nodev1beta1 "k8s.io/api/node/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// diffContextLines is the number of unchanged lines shown around changed lines
+const diffContextLines = 3
+
+// savedManifests contains raw JSON manifests keyed by the kind and the name
+type savedManifests map[string]map[string][]byte
+
+// loadSavedManifests loads all YAML and JSON manifests from the directory,
+// files can contain multiple documents and lists, as exported by "oc get -o yaml"
+func loadSavedManifests(dir string) (savedManifests, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := savedManifests{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(f.Name()))
+		if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+			continue
+		}
+
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %q: %v", f.Name(), err)
+			}
+
+			if len(bytes.TrimSpace(doc)) == 0 {
+				continue
+			}
+
+			data, err := k8syaml.ToJSON(doc)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %v", f.Name(), err)
+			}
+
+			if err := manifests.add(data); err != nil {
+				return nil, fmt.Errorf("failed to decode %q: %v", f.Name(), err)
+			}
+		}
+	}
+	return manifests, nil
+}
+
+func (sm savedManifests) add(data []byte) error {
+	obj := struct {
+		metav1.TypeMeta   `json:",inline"`
+		metav1.ObjectMeta `json:"metadata,omitempty"`
+		Items             []json.RawMessage `json:"items,omitempty"`
+	}{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	if obj.Kind == "List" || strings.HasSuffix(obj.Kind, "List") {
+		for _, item := range obj.Items {
+			if err := sm.add(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if obj.Kind == "" || obj.Name == "" {
+		return nil
+	}
+
+	if _, ok := sm[obj.Kind]; !ok {
+		sm[obj.Kind] = map[string][]byte{}
+	}
+	sm[obj.Kind][obj.Name] = data
+	return nil
+}
+
+func (sm savedManifests) get(kind string, name string, into interface{}) (bool, error) {
+	data, ok := sm[kind][name]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, into)
+}
+
+// getManifestResultSet returns the saved components with the same names as the rendered ones,
+// components that were not saved are left nil
+func (sm savedManifests) getManifestResultSet(rendered *manifestset.ManifestResultSet) (*manifestset.ManifestResultSet, error) {
+	saved := &manifestset.ManifestResultSet{}
+
+	mc := &mcov1.MachineConfig{}
+	found, err := sm.get(rendered.MachineConfig.Kind, rendered.MachineConfig.Name, mc)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		saved.MachineConfig = mc
+	}
+
+	kc := &mcov1.KubeletConfig{}
+	found, err = sm.get(rendered.KubeletConfig.Kind, rendered.KubeletConfig.Name, kc)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		saved.KubeletConfig = kc
+	}
+
+	tuned := &tunedv1.Tuned{}
+	found, err = sm.get(rendered.Tuned.Kind, rendered.Tuned.Name, tuned)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		saved.Tuned = tuned
+	}
+
+	runtimeClass := &nodev1beta1.RuntimeClass{}
+	found, err = sm.get(rendered.RuntimeClass.Kind, rendered.RuntimeClass.Name, runtimeClass)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		saved.RuntimeClass = runtimeClass
+	}
+
+	return saved, nil
+}
+
+// writeDiff writes the human readable diff of the profile components
+func writeDiff(w io.Writer, profileName string, diff *manifestset.ManifestResultSetDiff) {
+	fmt.Fprintf(w, "PerformanceProfile %q:\n", profileName)
+	for _, c := range diff.Components {
+		if len(c.Changes) == 0 {
+			fmt.Fprintf(w, "  %s %q: no changes\n", c.Kind, c.Name)
+			continue
+		}
+
+		fmt.Fprintf(w, "  %s %q:\n", c.Kind, c.Name)
+		for _, change := range c.Changes {
+			switch {
+			case change.Old == nil:
+				writeValue(w, "+", change.Path, *change.New)
+			case change.New == nil:
+				writeValue(w, "-", change.Path, *change.Old)
+			case strings.Contains(*change.Old, "\n") || strings.Contains(*change.New, "\n"):
+				fmt.Fprintf(w, "    ~ %s:\n", change.Path)
+				for _, line := range trimDiffContext(diffLines(splitLines(*change.Old), splitLines(*change.New)), diffContextLines) {
+					fmt.Fprintf(w, "        %s\n", line)
+				}
+			default:
+				fmt.Fprintf(w, "    ~ %s: %q -> %q\n", change.Path, *change.Old, *change.New)
+			}
+		}
+	}
+
+	var rebootComponents []string
+	for _, c := range diff.Components {
+		if c.RebootRequired {
+			rebootComponents = append(rebootComponents, c.Kind)
+		}
+	}
+
+	if len(rebootComponents) == 0 {
+		fmt.Fprintf(w, "Node reboot required: no\n")
+		return
+	}
+	fmt.Fprintf(w, "Node reboot required: yes (changed %s)\n", strings.Join(rebootComponents, ", "))
+}
+
+func writeValue(w io.Writer, sign string, path string, value string) {
+	if !strings.Contains(value, "\n") {
+		fmt.Fprintf(w, "    %s %s: %q\n", sign, path, value)
+		return
+	}
+
+	fmt.Fprintf(w, "    %s %s:\n", sign, path)
+	for _, line := range splitLines(value) {
+		fmt.Fprintf(w, "        %s %s\n", sign, line)
+	}
+}
+
+func splitLines(s string) []string {
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines returns the line based diff of two texts, computed via the longest common subsequence,
+// unchanged lines are prefixed with a space, removed lines with "-" and added lines with "+"
+func diffLines(oldLines, newLines []string) []string {
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var result []string
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			result = append(result, "  "+oldLines[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			result = append(result, "- "+oldLines[i])
+			i++
+		default:
+			result = append(result, "+ "+newLines[j])
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		result = append(result, "- "+oldLines[i])
+	}
+	for ; j < len(newLines); j++ {
+		result = append(result, "+ "+newLines[j])
+	}
+	return result
+}
+
+// trimDiffContext replaces unchanged lines that are further than context lines from any change with "..."
+func trimDiffContext(lines []string, context int) []string {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if strings.HasPrefix(line, " ") {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var result []string
+	skipped := false
+	for i, line := range lines {
+		if keep[i] {
+			result = append(result, line)
+			skipped = false
+			continue
+		}
+		if !skipped {
+			result = append(result, "  ...")
+			skipped = true
+		}
+	}
+	return result
+}
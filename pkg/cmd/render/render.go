@@ -18,6 +18,7 @@ package render
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -27,6 +28,7 @@ import (
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/manifestset"
+	profileutil "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -37,6 +39,8 @@ type renderOpts struct {
 	performanceProfileInputFiles performanceProfileFiles
 	assetsInDir                  string
 	assetsOutDir                 string
+	diffAgainstDir               string
+	out                          io.Writer
 }
 
 type performanceProfileFiles []string
@@ -63,7 +67,9 @@ func (ppf *performanceProfileFiles) Set(value string) error {
 
 //NewRenderCommand creates a render command.
 func NewRenderCommand() *cobra.Command {
-	renderOpts := renderOpts{}
+	renderOpts := renderOpts{
+		out: os.Stdout,
+	}
 
 	cmd := &cobra.Command{
 		Use:   "render",
@@ -89,6 +95,7 @@ func (r *renderOpts) AddFlags(fs *pflag.FlagSet) {
 	fs.Var(&r.performanceProfileInputFiles, "performance-profile-input-files", "A comma-separated list of performance-profile manifests.")
 	fs.StringVar(&r.assetsInDir, "asset-input-dir", components.AssetsDir, "Input path for the assets directory.")
 	fs.StringVar(&r.assetsOutDir, "asset-output-dir", r.assetsOutDir, "Output path for the rendered manifests.")
+	fs.StringVar(&r.diffAgainstDir, "diff-against", r.diffAgainstDir, "Path to the directory with previously rendered or exported manifests, when specified the diff between them and the rendered manifests is printed.")
 	// environment variables has precedence over standard input
 	r.readFlagsFromEnv()
 }
@@ -105,6 +112,10 @@ func (r *renderOpts) readFlagsFromEnv() {
 	if assetsOutDir := os.Getenv("ASSET_OUTPUT_DIR"); len(assetsOutDir) > 0 {
 		r.assetsOutDir = assetsOutDir
 	}
+
+	if diffAgainstDir := os.Getenv("DIFF_AGAINST_DIR"); len(diffAgainstDir) > 0 {
+		r.diffAgainstDir = diffAgainstDir
+	}
 }
 
 func (r *renderOpts) Validate() error {
@@ -112,26 +123,67 @@ func (r *renderOpts) Validate() error {
 		return fmt.Errorf("performance-profile-input-files must be specified")
 	}
 
-	if len(r.assetsOutDir) == 0 {
-		return fmt.Errorf("asset-output-dir must be specified")
+	// the output directory is optional in the diff mode
+	if len(r.assetsOutDir) == 0 && len(r.diffAgainstDir) == 0 {
+		return fmt.Errorf("asset-output-dir or diff-against must be specified")
 	}
 
 	return nil
 }
 
 func (r *renderOpts) Run() error {
+	var saved savedManifests
+	if len(r.diffAgainstDir) > 0 {
+		var err error
+		if saved, err = loadSavedManifests(r.diffAgainstDir); err != nil {
+			return err
+		}
+	}
+
+	var profiles []performancev2.PerformanceProfile
 	for _, pp := range r.performanceProfileInputFiles {
 		b, err := ioutil.ReadFile(pp)
 		if err != nil {
 			return err
 		}
 
-		profile := &performancev2.PerformanceProfile{}
-		err = yaml.Unmarshal(b, profile)
+		profile := performancev2.PerformanceProfile{}
+		err = yaml.Unmarshal(b, &profile)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	for i := range profiles {
+		// overlays are rendered as part of their base profiles
+		if profileutil.IsOverlay(&profiles[i]) {
+			continue
+		}
+
+		profile, _, err := performancev2.GetEffectiveProfile(&profiles[i], performancev2.GetOverlays(&profiles[i], profiles))
 		if err != nil {
 			return err
 		}
 
+		// the render does not have access to nodes, so it can only use CPUs already computed by the operator
+		if profileutil.IsCPUsComputed(profile) {
+			if profile.Status.CPU == nil {
+				return fmt.Errorf("the performance profile %q CPUs are computed from the node topology, the profile status should contain computed CPUs", profile.Name)
+			}
+			profileutil.SetComputedCPUs(profile, profile.Status.CPU)
+		}
+
+		// the render does not have access to ConfigMaps, so it can only use inline user tuned profiles
+		if profileutil.IsTunedProfilesReferenced(profile) {
+			return fmt.Errorf("the performance profile %q reads tuned profiles from ConfigMaps, the render supports only inline tuned profiles", profile.Name)
+		}
+
+		// nodes failed to allocate huge pages at runtime, so the operator allocates them on boot
+		if profileutil.IsHugePagesFallbackToBoot(profile) {
+			profileutil.DisableHugePagesRuntimeAllocation(profile)
+		}
+
 		components, err := manifestset.GetNewComponents(profile, &r.assetsInDir)
 		if err != nil {
 			return err
@@ -147,6 +199,23 @@ func (r *renderOpts) Run() error {
 			componentObj.SetOwnerReferences(or)
 		}
 
+		if saved != nil {
+			savedComponents, err := saved.getManifestResultSet(components)
+			if err != nil {
+				return err
+			}
+
+			diff, err := manifestset.Diff(savedComponents, components)
+			if err != nil {
+				return err
+			}
+			writeDiff(r.out, profile.Name, diff)
+		}
+
+		if len(r.assetsOutDir) == 0 {
+			continue
+		}
+
 		for kind, manifest := range components.ToManifestTable() {
 			b, err := yaml.Marshal(manifest)
 			if err != nil {
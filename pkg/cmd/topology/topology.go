@@ -0,0 +1,71 @@
+/*
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"io/ioutil"
+
+	"github.com/jaypipes/ghw"
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"k8s.io/klog"
+)
+
+// defaultOutput is the file read by the kubelet to set the termination message of the container
+const defaultOutput = "/dev/termination-log"
+
+type discoverTopologyOpts struct {
+	output string
+}
+
+// NewDiscoverTopologyCommand returns the command run by the CPU topology discovery pod, the command
+// reads the CPU topology of the node and writes its compact representation to the termination message
+func NewDiscoverTopologyCommand() *cobra.Command {
+	opts := discoverTopologyOpts{}
+
+	cmd := &cobra.Command{
+		Use:   "discover-topology",
+		Short: "Report the CPU topology of the node",
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := opts.Run(); err != nil {
+				klog.Fatal(err)
+			}
+		},
+	}
+
+	opts.AddFlags(cmd.Flags())
+
+	return cmd
+}
+
+func (d *discoverTopologyOpts) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&d.output, "output", defaultOutput, "Path to the file the CPU topology is written to.")
+}
+
+func (d *discoverTopologyOpts) Run() error {
+	topologyInfo, err := ghw.Topology()
+	if err != nil {
+		return err
+	}
+
+	cpuallocation.SortTopology(topologyInfo)
+	report, err := cpuallocation.FormatTopologyReport(topologyInfo)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(d.output, []byte(report), 0644)
+}
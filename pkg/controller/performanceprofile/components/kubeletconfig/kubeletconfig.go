@@ -2,6 +2,8 @@ package kubeletconfig
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 	"time"
 
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
@@ -9,39 +11,94 @@ import (
 	profile2 "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
 	machineconfigv1 "github.com/openshift/machine-config-operator/pkg/apis/machineconfiguration.openshift.io/v1"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
 )
 
 const (
-	cpuManagerPolicyStatic      = "static"
-	defaultKubeReservedCPU      = "1000m"
-	defaultKubeReservedMemory   = "500Mi"
-	defaultSystemReservedCPU    = "1000m"
-	defaultSystemReservedMemory = "500Mi"
+	cpuManagerPolicyStatic = "static"
 )
 
+// kubeletConfiguration adds the kubelet config fields missing under the vendored kubelet config API
+type kubeletConfiguration struct {
+	kubeletconfigv1beta1.KubeletConfiguration
+	// CPUManagerPolicyOptions is a set of key=value which allows to set extra options to fine tune
+	// the behaviour of the CPU manager policies
+	CPUManagerPolicyOptions map[string]string `json:"cpuManagerPolicyOptions,omitempty"`
+}
+
 // New returns new KubeletConfig object for performance sensetive workflows
 func New(profile *performancev2.PerformanceProfile) (*machineconfigv1.KubeletConfig, error) {
 	name := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
-	kubeletConfig := &kubeletconfigv1beta1.KubeletConfiguration{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: "kubelet.config.k8s.io/v1beta1",
-			Kind:       "KubeletConfiguration",
-		},
-		CPUManagerPolicy:          cpuManagerPolicyStatic,
-		CPUManagerReconcilePeriod: metav1.Duration{Duration: 5 * time.Second},
-		TopologyManagerPolicy:     kubeletconfigv1beta1.BestEffortTopologyManagerPolicy,
-		KubeReserved: map[string]string{
-			"cpu":    defaultKubeReservedCPU,
-			"memory": defaultKubeReservedMemory,
-		},
-		SystemReserved: map[string]string{
-			"cpu":    defaultSystemReservedCPU,
-			"memory": defaultSystemReservedMemory,
+	config := &kubeletConfiguration{
+		KubeletConfiguration: kubeletconfigv1beta1.KubeletConfiguration{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "kubelet.config.k8s.io/v1beta1",
+				Kind:       "KubeletConfiguration",
+			},
+			CPUManagerPolicy:          cpuManagerPolicyStatic,
+			CPUManagerReconcilePeriod: metav1.Duration{Duration: 5 * time.Second},
+			TopologyManagerPolicy:     kubeletconfigv1beta1.BestEffortTopologyManagerPolicy,
+			KubeReserved: map[string]string{
+				"cpu":    performancev2.DefaultReservedCPU,
+				"memory": performancev2.DefaultReservedMemory,
+			},
+			SystemReserved: map[string]string{
+				"cpu":    performancev2.DefaultReservedCPU,
+				"memory": performancev2.DefaultReservedMemory,
+			},
 		},
 	}
+	kubeletConfig := &config.KubeletConfiguration
+
+	// the CPUManagerPolicyOptions feature gate is enabled by the cluster FeatureGate resource,
+	// the machine config operator does not accept feature gates of kubelet configs
+	if profile.Spec.CPU != nil && len(profile.Spec.CPU.ManagerPolicyOptions) != 0 {
+		config.CPUManagerPolicyOptions = map[string]string{}
+		for _, option := range profile.Spec.CPU.ManagerPolicyOptions {
+			config.CPUManagerPolicyOptions[string(option)] = "true"
+		}
+	}
+
+	if kubelet := profile.Spec.Kubelet; kubelet != nil {
+		if kubelet.KubeReserved != nil {
+			kubeletConfig.KubeReserved = getResourcesMap(kubelet.KubeReserved)
+		}
+
+		if kubelet.SystemReserved != nil {
+			kubeletConfig.SystemReserved = getResourcesMap(kubelet.SystemReserved)
+		}
+
+		if kubelet.EvictionHard != nil {
+			kubeletConfig.EvictionHard = make(map[string]string, len(kubelet.EvictionHard))
+			for signal, threshold := range kubelet.EvictionHard {
+				kubeletConfig.EvictionHard[signal] = threshold
+			}
+		}
+
+		if kubelet.CPUManagerReconcilePeriod != nil {
+			kubeletConfig.CPUManagerReconcilePeriod = *kubelet.CPUManagerReconcilePeriod
+		}
+	}
+
+	if performancev2.GetMemoryManagerPolicy(&profile.Spec) == performancev2.MemoryManagerPolicyStatic {
+		// the controller derives the reserved memory from the CPU topology of nodes, without the topology
+		// the memory is reserved on the NUMA node 0
+		reservedMemory, err := profile2.GetReservedMemory(profile, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		kubeletConfig.MemoryManagerPolicy = kubeletconfigv1beta1.StaticMemoryManagerPolicy
+		for _, numaReserved := range reservedMemory {
+			kubeletConfig.ReservedMemory = append(kubeletConfig.ReservedMemory, kubeletconfigv1beta1.MemoryReservation{
+				NumaNode: numaReserved.NUMANode,
+				Limits:   numaReserved.Limits.DeepCopy(),
+			})
+		}
+	}
 
 	if profile.Spec.CPU != nil && profile.Spec.CPU.Reserved != nil {
 		kubeletConfig.ReservedSystemCPUs = string(*profile.Spec.CPU.Reserved)
@@ -51,9 +108,18 @@ func New(profile *performancev2.PerformanceProfile) (*machineconfigv1.KubeletCon
 		if profile.Spec.NUMA.TopologyPolicy != nil {
 			kubeletConfig.TopologyManagerPolicy = string(*profile.Spec.NUMA.TopologyPolicy)
 		}
+
+		if profile.Spec.NUMA.TopologyManagerScope != nil {
+			kubeletConfig.TopologyManagerScope = string(*profile.Spec.NUMA.TopologyManagerScope)
+		}
 	}
 
-	raw, err := json.Marshal(kubeletConfig)
+	raw, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err = mergeOverrides(profile, raw)
 	if err != nil {
 		return nil, err
 	}
@@ -76,3 +142,50 @@ func New(profile *performancev2.PerformanceProfile) (*machineconfigv1.KubeletCon
 		},
 	}, nil
 }
+
+// getResourcesMap returns resources in the format of the kubelet config reserved resources
+func getResourcesMap(resources corev1.ResourceList) map[string]string {
+	resourcesMap := make(map[string]string, len(resources))
+	for name, quantity := range resources {
+		resourcesMap[string(name)] = quantity.String()
+	}
+	return resourcesMap
+}
+
+// mergeOverrides merges kubelet config overrides of the profile into the generated kubelet config
+func mergeOverrides(profile *performancev2.PerformanceProfile, raw []byte) ([]byte, error) {
+	overrides, err := performancev2.GetKubeletConfigOverrides(&profile.Spec)
+	if err != nil || overrides == nil {
+		return raw, err
+	}
+
+	// the webhook rejects such profiles, but the render command and the controller can still get them
+	conflicts, err := performancev2.GetKubeletConfigOverridesConflicts(&profile.Spec)
+	if err != nil {
+		return nil, err
+	}
+	if len(conflicts) != 0 {
+		return nil, fmt.Errorf("%s", strings.Join(conflicts, ", "))
+	}
+
+	generated := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &generated); err != nil {
+		return nil, err
+	}
+
+	mergeObjects(generated, overrides)
+	return json.Marshal(generated)
+}
+
+// mergeObjects merges src into dst recursively, values other than objects replace values of dst
+func mergeObjects(dst, src map[string]interface{}) {
+	for key, srcValue := range src {
+		srcObject, srcIsObject := srcValue.(map[string]interface{})
+		dstObject, dstIsObject := dst[key].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			mergeObjects(dstObject, srcObject)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
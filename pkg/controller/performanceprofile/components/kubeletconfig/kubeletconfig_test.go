@@ -2,13 +2,20 @@ package kubeletconfig
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ghodss/yaml"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 var _ = Describe("Kubelet Config", func() {
@@ -28,4 +35,104 @@ var _ = Describe("Kubelet Config", func() {
 		Expect(manifest).To(ContainSubstring("topologyManagerPolicy: single-numa-node"))
 		Expect(manifest).To(ContainSubstring("cpuManagerPolicy: static"))
 	})
+	It("should set kubelet reserved resources and eviction thresholds of the profile", func() {
+		profile := testutils.NewPerformanceProfile("test")
+		profile.Spec.Kubelet = &performancev2.Kubelet{
+			KubeReserved: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			EvictionHard:              map[string]string{performancev2.EvictionSignalMemoryAvailable: "200Mi"},
+			CPUManagerReconcilePeriod: &metav1.Duration{Duration: 10 * time.Second},
+		}
+
+		kc, err := New(profile)
+		Expect(err).ToNot(HaveOccurred())
+
+		y, err := yaml.Marshal(kc)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest := string(y)
+		Expect(manifest).To(MatchRegexp(`kubeReserved:\s+cpu: 500m\s+memory: 1Gi`))
+		Expect(manifest).To(MatchRegexp(`systemReserved:\s+cpu: 1000m\s+memory: 500Mi`))
+		Expect(manifest).To(MatchRegexp(`evictionHard:\s+memory.available: 200Mi`))
+		Expect(manifest).To(ContainSubstring("cpuManagerReconcilePeriod: 10s"))
+		Expect(manifest).ToNot(ContainSubstring("memoryManagerPolicy"))
+	})
+
+	It("should reserve the memory on the NUMA node 0 with the Static memory manager policy", func() {
+		profile := testutils.NewPerformanceProfile("test")
+		policy := performancev2.MemoryManagerPolicyStatic
+		profile.Spec.Kubelet = &performancev2.Kubelet{
+			MemoryManager: &performancev2.MemoryManager{Policy: &policy},
+		}
+
+		kc, err := New(profile)
+		Expect(err).ToNot(HaveOccurred())
+
+		y, err := yaml.Marshal(kc)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest := string(y)
+		Expect(manifest).To(ContainSubstring("memoryManagerPolicy: Static"))
+		// 500Mi of the kube reserved memory, 500Mi of the system reserved memory and 100Mi of the eviction threshold
+		Expect(manifest).To(MatchRegexp(`reservedMemory:\s+- limits:\s+memory: 1100Mi\s+numaNode: 0`))
+	})
+
+	It("should set CPU manager policy options without feature gates and the topology manager scope", func() {
+		profile := testutils.NewPerformanceProfile("test")
+		profile.Spec.CPU.ManagerPolicyOptions = []performancev2.CPUManagerPolicyOption{
+			performancev2.CPUManagerPolicyOptionFullPCPUsOnly,
+		}
+		scope := performancev2.TopologyManagerScopePod
+		profile.Spec.NUMA.TopologyManagerScope = &scope
+
+		kc, err := New(profile)
+		Expect(err).ToNot(HaveOccurred())
+
+		y, err := yaml.Marshal(kc)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest := string(y)
+		Expect(manifest).To(MatchRegexp(`cpuManagerPolicyOptions:\s+full-pcpus-only: "true"`))
+		Expect(manifest).ToNot(ContainSubstring("featureGates"))
+		Expect(manifest).To(ContainSubstring("topologyManagerScope: pod"))
+		Expect(manifest).To(ContainSubstring("cpuManagerPolicy: static"))
+	})
+
+	It("should merge kubelet config overrides into the generated kubelet config", func() {
+		profile := testutils.NewPerformanceProfile("test")
+		profile.Spec.CPU.ManagerPolicyOptions = []performancev2.CPUManagerPolicyOption{
+			performancev2.CPUManagerPolicyOptionFullPCPUsOnly,
+		}
+		profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+			Raw: []byte(`{"maxPods":500,"ContainerLogMaxSize":"50Mi","evictionSoft":{"memory.available":"500Mi"}}`),
+		}
+
+		kc, err := New(profile)
+		Expect(err).ToNot(HaveOccurred())
+
+		y, err := yaml.Marshal(kc)
+		Expect(err).ToNot(HaveOccurred())
+
+		manifest := string(y)
+		Expect(manifest).To(ContainSubstring("maxPods: 500"))
+		Expect(manifest).To(ContainSubstring("containerLogMaxSize: 50Mi"))
+		Expect(manifest).To(MatchRegexp(`evictionSoft:\s+memory.available: 500Mi`))
+		Expect(manifest).To(ContainSubstring("reservedSystemCPUs: 0-3"))
+	})
+
+	It("should fail to override kubelet config fields owned by the operator", func() {
+		profile := testutils.NewPerformanceProfile("test")
+		profile.Spec.KubeletConfigOverrides = &runtime.RawExtension{
+			Raw: []byte(`{"maxPods":500,"reservedSystemCPUs":"0-1"}`),
+		}
+
+		_, err := New(profile)
+		Expect(err).To(MatchError(`the kubelet config field "reservedSystemCPUs" is owned by the operator, use spec.cpu.reserved instead`))
+
+		profile.Spec.KubeletConfigOverrides.Raw = []byte(`{"ReservedSystemCPUs":"0-1"}`)
+		_, err = New(profile)
+		Expect(err).To(MatchError(`the kubelet config field "reservedSystemCPUs" is owned by the operator, use spec.cpu.reserved instead`))
+	})
 })
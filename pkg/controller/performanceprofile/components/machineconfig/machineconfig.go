@@ -7,6 +7,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"text/template"
 
 	"github.com/coreos/go-systemd/unit"
@@ -31,10 +33,11 @@ const (
 	MCKernelRT = "realtime"
 	// MCKernelDefault is the value of the kernel setting in MachineConfig for the default kernel
 	MCKernelDefault = "default"
-	// HighPerformanceRuntime contains the name of the high-performance runtime
-	HighPerformanceRuntime = "high-performance"
+	// HighPerformanceRuntime contains the name of the default high-performance runtime handler
+	HighPerformanceRuntime = performancev2.DefaultRuntimeClassHandler
 
 	hugepagesAllocation = "hugepages-allocation"
+	hugepagesReport     = "hugepages-report"
 	bashScriptsDir      = "/usr/local/bin"
 	crioConfd           = "/etc/crio/crio.conf.d"
 	crioRuntimesConfig  = "99-runtimes.conf"
@@ -47,6 +50,10 @@ const (
 	udevRulesDir       = "/etc/udev/rules.d"
 	udevRpsRule        = "99-netdev-rps"
 	setRPSMask         = "set-rps-mask"
+	stalld             = "stalld"
+	stalldConfigDir    = "/etc/sysconfig"
+	stalldConfig       = "stalld-performance"
+	stalldDropin       = "10-performance.conf"
 )
 
 const (
@@ -55,16 +62,21 @@ const (
 	systemdSectionInstall  = "Install"
 	systemdDescription     = "Description"
 	systemdBefore          = "Before"
+	systemdAfter           = "After"
 	systemdEnvironment     = "Environment"
 	systemdType            = "Type"
 	systemdRemainAfterExit = "RemainAfterExit"
 	systemdExecStart       = "ExecStart"
+	systemdRestart         = "Restart"
+	systemdRestartSec      = "RestartSec"
+	systemdEnvironmentFile = "EnvironmentFile"
 	systemdWantedBy        = "WantedBy"
 )
 
 const (
 	systemdServiceKubelet     = "kubelet.service"
 	systemdServiceTypeOneshot = "oneshot"
+	systemdRestartAlways      = "always"
 	systemdTargetMultiUser    = "multi-user.target"
 	systemdTrue               = "true"
 )
@@ -76,9 +88,25 @@ const (
 )
 
 const (
-	templateReservedCpus = "ReservedCpus"
+	templateReservedCpus       = "ReservedCpus"
+	templateAllowedAnnotations = "AllowedAnnotations"
+	templateRuntimeHandler     = "RuntimeHandler"
+	templateStalldCpus         = "Cpus"
+	templateBoostPeriod        = "BoostPeriod"
+	templateBoostRuntime       = "BoostRuntime"
+	templateStarvingThreshold  = "StarvingThreshold"
 )
 
+// HighPerformanceRuntimeAnnotations contains pod annotations allowed under the high-performance runtime handler,
+// the runtime applies them to CPUs of the container, so power settings can be changed only for pinned CPUs of the pod
+var HighPerformanceRuntimeAnnotations = []string{
+	"cpu-load-balancing.crio.io",
+	"cpu-quota.crio.io",
+	"irq-load-balancing.crio.io",
+	"cpu-c-states.crio.io",
+	"cpu-freq-governor.crio.io",
+}
+
 // New returns new machine configuration object for performance sensitive workloads
 func New(assetsDir string, profile *performancev2.PerformanceProfile) (*machineconfigv1.MachineConfig, error) {
 	name := GetMachineConfigName(profile)
@@ -189,12 +217,26 @@ func getIgnitionConfig(assetsDir string, profile *performancev2.PerformanceProfi
 	}
 
 	if profile.Spec.HugePages != nil {
+		hugePagesArch, err := performancev2.GetHugePagesArchitecture(profile)
+		if err != nil {
+			return nil, err
+		}
+
 		for _, page := range profile.Spec.HugePages.Pages {
+			if !hugePagesArch.IsSupportedSize(page.Size) {
+				return nil, fmt.Errorf("the huge page size %q is not supported on the %s", page.Size, hugePagesArch)
+			}
+
 			// we already allocated non NUMA specific hugepages via kernel arguments
 			if page.Node == nil {
 				continue
 			}
 
+			// huge pages allocated at runtime are configured via the tuned profile
+			if profile2.IsRuntimeHugePage(profile, &page) {
+				continue
+			}
+
 			hugepagesSize, err := GetHugepagesSizeKilobytes(page.Size)
 			if err != nil {
 				return nil, err
@@ -217,6 +259,58 @@ func getIgnitionConfig(assetsDir string, profile *performancev2.PerformanceProfi
 		}
 	}
 
+	// nodes report huge pages allocated at runtime, so the operator can fall back to the boot allocation
+	// when nodes fail to allocate them
+	if profile2.IsHugePagesRuntimeAllocation(profile) {
+		if err := addFile(ignitionConfig, filepath.Join(assetsDir, "scripts", fmt.Sprintf("%s.sh", hugepagesReport)), getBashScriptPath(hugepagesReport), &mode); err != nil {
+			return nil, err
+		}
+
+		hugepagesReportService, err := getSystemdContent(getHugepagesReportUnitOptions())
+		if err != nil {
+			return nil, err
+		}
+
+		ignitionConfig.Systemd.Units = append(ignitionConfig.Systemd.Units, igntypes.Unit{
+			Contents: &hugepagesReportService,
+			Enabled:  pointer.BoolPtr(true),
+			Name:     getSystemdService(hugepagesReport),
+		})
+	}
+
+	// the stalld configuration is loaded by the drop-in after the default one, so it overrides default values
+	if profile.Spec.Stalld != nil && profile2.IsStalldEnabled(profile) {
+		stalldConfigMode := 0644
+		stalldConfigContent, err := getStalldConfig(profile, filepath.Join(assetsDir, "configs", stalld))
+		if err != nil {
+			return nil, err
+		}
+
+		stalldConfigPath := filepath.Join(stalldConfigDir, stalldConfig)
+		if err := addContent(ignitionConfig, stalldConfigContent, stalldConfigPath, &stalldConfigMode); err != nil {
+			return nil, err
+		}
+
+		stalldDropinContent, err := getSystemdContent([]*unit.UnitOption{
+			// [Service]
+			// EnvironmentFile
+			unit.NewUnitOption(systemdSectionService, systemdEnvironmentFile, stalldConfigPath),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		ignitionConfig.Systemd.Units = append(ignitionConfig.Systemd.Units, igntypes.Unit{
+			Name: getSystemdService(stalld),
+			Dropins: []igntypes.Dropin{
+				{
+					Name:     stalldDropin,
+					Contents: &stalldDropinContent,
+				},
+			},
+		})
+	}
+
 	if profile.Spec.CPU != nil && profile.Spec.CPU.Reserved != nil {
 		rpsMask, err := components.CPUListToMaskList(string(*profile.Spec.CPU.Reserved))
 		if err != nil {
@@ -285,14 +379,11 @@ func GetOCIHooksConfigContent(configFile string, profile *performancev2.Performa
 
 // GetHugepagesSizeKilobytes retruns hugepages size in kilobytes
 func GetHugepagesSizeKilobytes(hugepagesSize performancev2.HugePageSize) (string, error) {
-	switch hugepagesSize {
-	case "1G":
-		return "1048576", nil
-	case "2M":
-		return "2048", nil
-	default:
+	size, err := performancev2.GetHugePageSizeBytes(hugepagesSize)
+	if err != nil {
 		return "", fmt.Errorf("can not convert size %q to kilobytes", hugepagesSize)
 	}
+	return strconv.FormatInt(size/1024, 10), nil
 }
 
 func getHugepagesAllocationUnitOptions(hugepagesSize string, hugepagesCount int32, numaNode int32) []*unit.UnitOption {
@@ -319,6 +410,26 @@ func getHugepagesAllocationUnitOptions(hugepagesSize string, hugepagesCount int3
 	}
 }
 
+func getHugepagesReportUnitOptions() []*unit.UnitOption {
+	return []*unit.UnitOption{
+		// [Unit]
+		// Description
+		unit.NewUnitOption(systemdSectionUnit, systemdDescription, "Reports huge pages allocated on NUMA nodes"),
+		// After
+		unit.NewUnitOption(systemdSectionUnit, systemdAfter, systemdServiceKubelet),
+		// [Service]
+		// ExecStart
+		unit.NewUnitOption(systemdSectionService, systemdExecStart, getBashScriptPath(hugepagesReport)),
+		// Restart
+		unit.NewUnitOption(systemdSectionService, systemdRestart, systemdRestartAlways),
+		// RestartSec
+		unit.NewUnitOption(systemdSectionService, systemdRestartSec, "30"),
+		// [Install]
+		// WantedBy
+		unit.NewUnitOption(systemdSectionInstall, systemdWantedBy, systemdTargetMultiUser),
+	}
+}
+
 func getRPSUnitOptions(rpsMask string) []*unit.UnitOption {
 	cmd := fmt.Sprintf("%s %%i %s", getBashScriptPath(setRPSMask), rpsMask)
 	return []*unit.UnitOption{
@@ -351,10 +462,17 @@ func addContent(ignitionConfig *igntypes.Config, content []byte, dst string, mod
 
 func addCrioConfigSnippet(profile *performancev2.PerformanceProfile, src string) ([]byte, error) {
 	templateArgs := make(map[string]string)
-	if profile.Spec.CPU.Reserved != nil {
+	if profile.Spec.CPU != nil && profile.Spec.CPU.Reserved != nil {
 		templateArgs[templateReservedCpus] = string(*profile.Spec.CPU.Reserved)
 	}
 
+	var annotations []string
+	for _, annotation := range HighPerformanceRuntimeAnnotations {
+		annotations = append(annotations, strconv.Quote(annotation))
+	}
+	templateArgs[templateAllowedAnnotations] = strings.Join(annotations, ", ")
+	templateArgs[templateRuntimeHandler] = performancev2.GetRuntimeClassHandler(&profile.Spec)
+
 	content, err := ioutil.ReadFile(src)
 	if err != nil {
 		return nil, err
@@ -369,6 +487,38 @@ func addCrioConfigSnippet(profile *performancev2.PerformanceProfile, src string)
 	return crioConfig.Bytes(), nil
 }
 
+// getStalldConfig returns the stalld environment file with command line options of the stalld service
+func getStalldConfig(profile *performancev2.PerformanceProfile, src string) ([]byte, error) {
+	templateArgs := make(map[string]string)
+	if profile.Spec.Stalld.CPUs != nil {
+		templateArgs[templateStalldCpus] = string(*profile.Spec.Stalld.CPUs)
+	}
+
+	// stalld expects the boost period and runtime in nanoseconds and the starving threshold in seconds
+	if profile.Spec.Stalld.BoostPeriod != nil {
+		templateArgs[templateBoostPeriod] = strconv.FormatInt(profile.Spec.Stalld.BoostPeriod.Nanoseconds(), 10)
+	}
+	if profile.Spec.Stalld.BoostRuntime != nil {
+		templateArgs[templateBoostRuntime] = strconv.FormatInt(profile.Spec.Stalld.BoostRuntime.Nanoseconds(), 10)
+	}
+	if profile.Spec.Stalld.StarvingThreshold != nil {
+		templateArgs[templateStarvingThreshold] = strconv.FormatInt(int64(profile.Spec.Stalld.StarvingThreshold.Seconds()), 10)
+	}
+
+	content, err := ioutil.ReadFile(src)
+	if err != nil {
+		return nil, err
+	}
+
+	stalldConfig := &bytes.Buffer{}
+	stalldTemplate := template.Must(template.New("stalldConfig").Parse(string(content)))
+	if err := stalldTemplate.Execute(stalldConfig, templateArgs); err != nil {
+		return nil, err
+	}
+
+	return stalldConfig.Bytes(), nil
+}
+
 func addFile(ignitionConfig *igntypes.Config, src string, dst string, mode *int) error {
 	content, err := ioutil.ReadFile(src)
 	if err != nil {
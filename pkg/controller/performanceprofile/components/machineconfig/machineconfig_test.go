@@ -2,13 +2,18 @@ package machineconfig
 
 import (
 	"fmt"
+	"path/filepath"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
 	"github.com/ghodss/yaml"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
 )
@@ -47,6 +52,25 @@ var _ = Describe("Machine Config", func() {
 		})
 	})
 
+	Context("with the high-performance runtime", func() {
+		It("should allow power annotations under the high-performance runtime", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			content, err := addCrioConfigSnippet(profile, filepath.Join(testAssetsDir, "configs", crioRuntimesConfig))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring(`allowed_annotations = ["cpu-load-balancing.crio.io", "cpu-quota.crio.io", ` +
+				`"irq-load-balancing.crio.io", "cpu-c-states.crio.io", "cpu-freq-governor.crio.io"]`))
+		})
+
+		It("should configure the runtime handler of the profile", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			profile.Spec.RuntimeClass = &performancev2.RuntimeClass{Handler: pointer.StringPtr("low-latency")}
+			content, err := addCrioConfigSnippet(profile, filepath.Join(testAssetsDir, "configs", crioRuntimesConfig))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(ContainSubstring("[crio.runtime.runtimes.low-latency]"))
+			Expect(string(content)).ToNot(ContainSubstring("[crio.runtime.runtimes.high-performance]"))
+		})
+	})
+
 	Context("with hugepages with specified NUMA node", func() {
 		var manifest string
 
@@ -76,4 +100,129 @@ var _ = Describe("Machine Config", func() {
 		})
 
 	})
+
+	Context("with hugepages of arm64 nodes", func() {
+		It("should add systemd units with arm64 hugepages sizes", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitectureARM64
+			profile.Spec.HugePages.Pages = []performancev2.HugePage{
+				{Size: "64K", Count: 1024, Node: pointer.Int32Ptr(0)},
+				{Size: "32M", Count: 16, Node: pointer.Int32Ptr(1)},
+			}
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest := string(y)
+			Expect(manifest).To(ContainSubstring("hugepages-allocation-64kB-NUMA0.service"))
+			Expect(manifest).To(ContainSubstring("Environment=HUGEPAGES_SIZE=32768"))
+			Expect(manifest).To(ContainSubstring("hugepages-allocation-32768kB-NUMA1.service"))
+		})
+
+		It("should fail on hugepages sizes not supported by the architecture", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitectureAMD64
+			profile.Spec.HugePages.Pages[0].Size = "32M"
+			profile.Spec.HugePages.Pages[0].Node = pointer.Int32Ptr(0)
+
+			_, err := New(testAssetsDir, profile)
+			Expect(err).To(MatchError(`the huge page size "32M" is not supported on the amd64 architecture`))
+		})
+	})
+
+	Context("with hugepages allocated at runtime", func() {
+		It("should add systemd units only for hugepages allocated on boot", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+			profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+				performancev2.HugePage{Size: "1G", Count: 2, Node: pointer.Int32Ptr(0)},
+				performancev2.HugePage{Size: "2M", Count: 128, Node: pointer.Int32Ptr(1)},
+			)
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest := string(y)
+			Expect(manifest).To(ContainSubstring("hugepages-allocation-1048576kB-NUMA0.service"))
+			Expect(manifest).ToNot(ContainSubstring("hugepages-allocation-2048kB-NUMA1.service"))
+		})
+
+		It("should add the service that reports huge pages allocated on NUMA nodes", func() {
+			profile := testutils.NewPerformanceProfile("test")
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(y)).ToNot(ContainSubstring("hugepages-report"))
+
+			profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+			mc, err = New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+			y, err = yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest := string(y)
+			Expect(manifest).To(ContainSubstring("path: /usr/local/bin/hugepages-report.sh"))
+			Expect(manifest).To(ContainSubstring("ExecStart=/usr/local/bin/hugepages-report.sh"))
+			Expect(manifest).To(ContainSubstring("name: hugepages-report.service"))
+		})
+	})
+
+	Context("with the stalld configuration", func() {
+		It("should not add the stalld configuration by default", func() {
+			profile := testutils.NewPerformanceProfile("test")
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(y)).ToNot(ContainSubstring("stalld"))
+		})
+
+		It("should add the stalld configuration file and the systemd drop-in", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			cpus := performancev2.CPUSet("2-3")
+			profile.Spec.Stalld = &performancev2.Stalld{
+				CPUs:              &cpus,
+				BoostRuntime:      &metav1.Duration{Duration: 50 * time.Microsecond},
+				StarvingThreshold: &metav1.Duration{Duration: 10 * time.Second},
+			}
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+
+			config, err := getStalldConfig(profile, filepath.Join(testAssetsDir, "configs", stalld))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(config)).To(ContainSubstring("CLIST=\"-c 2-3\"\nBR=\"-r 50000\"\nTHRESH=\"-t 10\""))
+			Expect(string(config)).ToNot(ContainSubstring("BP="))
+
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest := string(y)
+			Expect(manifest).To(ContainSubstring("/etc/sysconfig/stalld-performance"))
+			Expect(manifest).To(ContainSubstring("name: stalld.service"))
+			Expect(manifest).To(ContainSubstring("EnvironmentFile=/etc/sysconfig/stalld-performance"))
+		})
+
+		It("should not add the stalld configuration when stalld is disabled", func() {
+			profile := testutils.NewPerformanceProfile("test")
+			profile.Spec.Stalld = &performancev2.Stalld{Enabled: pointer.BoolPtr(false)}
+
+			mc, err := New(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+
+			y, err := yaml.Marshal(mc)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(y)).ToNot(ContainSubstring("stalld"))
+		})
+	})
 })
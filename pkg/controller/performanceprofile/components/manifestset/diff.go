@@ -0,0 +1,388 @@
+package manifestset
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vincent-petithory/dataurl"
+)
+
+const (
+	kindMachineConfig = "MachineConfig"
+	kindKubeletConfig = "KubeletConfig"
+	kindTuned         = "Tuned"
+	kindRuntimeClass  = "RuntimeClass"
+
+	tunedBootloaderSection = "bootloader"
+	tunedVariablesSection  = "variables"
+)
+
+// FieldChange describes the change of a single field of a component,
+// Old is nil for added fields and New is nil for removed fields
+type FieldChange struct {
+	Path string
+	Old  *string
+	New  *string
+}
+
+// ComponentDiff contains all field changes of a single component
+type ComponentDiff struct {
+	Kind           string
+	Name           string
+	Changes        []FieldChange
+	RebootRequired bool
+}
+
+// ManifestResultSetDiff contains the differences between two manifest result sets
+type ManifestResultSetDiff struct {
+	Components []ComponentDiff
+}
+
+// RebootRequired returns true when at least one of the changed components will trigger a node reboot
+func (d *ManifestResultSetDiff) RebootRequired() bool {
+	for _, c := range d.Components {
+		if c.RebootRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// HasChanges returns true when at least one component has changed
+func (d *ManifestResultSetDiff) HasChanges() bool {
+	for _, c := range d.Components {
+		if len(c.Changes) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff returns per field differences between the old and the new manifest result sets,
+// the nil components of the old set are considered as not existing
+func Diff(oldSet, newSet *ManifestResultSet) (*ManifestResultSetDiff, error) {
+	if oldSet == nil {
+		oldSet = &ManifestResultSet{}
+	}
+
+	type pair struct {
+		kind     string
+		old, new interface{}
+		oldNil   bool
+		newNil   bool
+	}
+	pairs := []pair{
+		{kindMachineConfig, oldSet.MachineConfig, newSet.MachineConfig, oldSet.MachineConfig == nil, newSet.MachineConfig == nil},
+		{kindKubeletConfig, oldSet.KubeletConfig, newSet.KubeletConfig, oldSet.KubeletConfig == nil, newSet.KubeletConfig == nil},
+		{kindTuned, oldSet.Tuned, newSet.Tuned, oldSet.Tuned == nil, newSet.Tuned == nil},
+		{kindRuntimeClass, oldSet.RuntimeClass, newSet.RuntimeClass, oldSet.RuntimeClass == nil, newSet.RuntimeClass == nil},
+	}
+
+	result := &ManifestResultSetDiff{}
+	for _, p := range pairs {
+		oldFields := map[string]string{}
+		if !p.oldNil {
+			fields, err := flattenComponent(p.kind, p.old)
+			if err != nil {
+				return nil, err
+			}
+			oldFields = fields
+		}
+
+		newFields := map[string]string{}
+		if !p.newNil {
+			fields, err := flattenComponent(p.kind, p.new)
+			if err != nil {
+				return nil, err
+			}
+			newFields = fields
+		}
+
+		name := newFields["metadata.name"]
+		if name == "" {
+			name = oldFields["metadata.name"]
+		}
+
+		componentDiff := ComponentDiff{
+			Kind:    p.kind,
+			Name:    name,
+			Changes: diffFields(oldFields, newFields),
+		}
+		for _, change := range componentDiff.Changes {
+			if isRebootRequired(p.kind, change.Path) {
+				componentDiff.RebootRequired = true
+				break
+			}
+		}
+		result.Components = append(result.Components, componentDiff)
+	}
+
+	return result, nil
+}
+
+// isRebootRequired returns true when the change of the field will lead to a node reboot,
+// the machine config operator reboots nodes for any machine config change, the kubelet config
+// is rendered into a machine config and the tuned bootloader section updates kernel arguments,
+// kernel arguments of the bootloader section are built from variables, like isolated_cores,
+// so the variables section change updates kernel arguments as well
+func isRebootRequired(kind string, path string) bool {
+	switch kind {
+	case kindMachineConfig, kindKubeletConfig:
+		return strings.HasPrefix(path, "spec.")
+	case kindTuned:
+		for _, section := range []string{tunedBootloaderSection, tunedVariablesSection} {
+			if strings.Contains(path, fmt.Sprintf(".data[%s]", section)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func diffFields(oldFields, newFields map[string]string) []FieldChange {
+	paths := map[string]bool{}
+	for path := range oldFields {
+		paths[path] = true
+	}
+	for path := range newFields {
+		paths[path] = true
+	}
+
+	sortedPaths := make([]string, 0, len(paths))
+	for path := range paths {
+		sortedPaths = append(sortedPaths, path)
+	}
+	sort.Strings(sortedPaths)
+
+	var changes []FieldChange
+	for _, path := range sortedPaths {
+		oldValue, oldFound := oldFields[path]
+		newValue, newFound := newFields[path]
+		if oldFound && newFound && oldValue == newValue {
+			continue
+		}
+
+		change := FieldChange{Path: path}
+		if oldFound {
+			v := oldValue
+			change.Old = &v
+		}
+		if newFound {
+			v := newValue
+			change.New = &v
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// flattenComponent converts the component to the map of field paths and values,
+// only the name, labels and the spec are taken into account, because other metadata
+// fields and the status are populated by the cluster
+func flattenComponent(kind string, obj interface{}) (map[string]string, error) {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	content := map[string]interface{}{}
+	if err := json.Unmarshal(b, &content); err != nil {
+		return nil, err
+	}
+
+	normalized := map[string]interface{}{
+		"spec": content["spec"],
+	}
+	if metadata, ok := content["metadata"].(map[string]interface{}); ok {
+		normalized["metadata"] = map[string]interface{}{
+			"name":   metadata["name"],
+			"labels": metadata["labels"],
+		}
+	}
+
+	// runtime class keeps its configuration on the top level
+	if kind == kindRuntimeClass {
+		normalized["spec"] = map[string]interface{}{
+			"handler":    content["handler"],
+			"overhead":   content["overhead"],
+			"scheduling": content["scheduling"],
+		}
+	}
+
+	spec, _ := normalized["spec"].(map[string]interface{})
+	switch kind {
+	case kindMachineConfig:
+		if err := normalizeIgnitionConfig(spec); err != nil {
+			return nil, err
+		}
+	case kindTuned:
+		normalizeTunedProfiles(spec)
+	}
+
+	fields := map[string]string{}
+	flatten("", normalized, fields)
+	return fields, nil
+}
+
+// normalizeIgnitionConfig replaces ignition lists with maps keyed by the file path or unit name
+// and decodes the files content, so the diff will not depend on the order and on the encoding
+func normalizeIgnitionConfig(spec map[string]interface{}) error {
+	config, ok := spec["config"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if storage, ok := config["storage"].(map[string]interface{}); ok {
+		if files, ok := storage["files"].([]interface{}); ok {
+			for _, f := range files {
+				file, ok := f.(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				contents, ok := file["contents"].(map[string]interface{})
+				if !ok {
+					continue
+				}
+
+				source, ok := contents["source"].(string)
+				if !ok || !strings.HasPrefix(source, "data:") {
+					continue
+				}
+
+				decoded, err := dataurl.DecodeString(source)
+				if err != nil {
+					return fmt.Errorf("failed to decode the content of the file %v: %v", file["path"], err)
+				}
+				contents["source"] = string(decoded.Data)
+			}
+			storage["files"] = listToMap(files, "path")
+		}
+	}
+
+	if systemd, ok := config["systemd"].(map[string]interface{}); ok {
+		if units, ok := systemd["units"].([]interface{}); ok {
+			for _, u := range units {
+				if unit, ok := u.(map[string]interface{}); ok {
+					if dropins, ok := unit["dropins"].([]interface{}); ok {
+						unit["dropins"] = listToMap(dropins, "name")
+					}
+				}
+			}
+			systemd["units"] = listToMap(units, "name")
+		}
+	}
+	return nil
+}
+
+// normalizeTunedProfiles replaces the tuned profiles list with the map keyed by the profile name
+// and splits the profile data into sections and options
+func normalizeTunedProfiles(spec map[string]interface{}) {
+	profiles, ok := spec["profile"].([]interface{})
+	if !ok {
+		return
+	}
+
+	for _, p := range profiles {
+		profile, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if data, ok := profile["data"].(string); ok {
+			profile["data"] = parseTunedProfileData(data)
+		}
+	}
+	spec["profile"] = listToMap(profiles, "name")
+}
+
+// parseTunedProfileData parses the tuned profile INI data into sections and options,
+// comments and empty lines are ignored
+func parseTunedProfileData(data string) keyedMap {
+	sections := keyedMap{}
+	section := ""
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSuffix(strings.TrimPrefix(line, "["), "]")
+			if _, ok := sections[section]; !ok {
+				sections[section] = map[string]interface{}{}
+			}
+			continue
+		}
+
+		if _, ok := sections[section]; !ok {
+			sections[section] = map[string]interface{}{}
+		}
+
+		key, value := line, ""
+		if i := strings.Index(line, "="); i >= 0 {
+			key = strings.TrimSpace(line[:i])
+			value = strings.TrimSpace(line[i+1:])
+		}
+		sections[section].(map[string]interface{})[key] = value
+	}
+	return sections
+}
+
+// keyedMap is a map that was converted from a list, its keys are always rendered in brackets
+type keyedMap map[string]interface{}
+
+func listToMap(list []interface{}, key string) keyedMap {
+	result := keyedMap{}
+	for i, item := range list {
+		name := fmt.Sprintf("%d", i)
+		if m, ok := item.(map[string]interface{}); ok {
+			if v, ok := m[key].(string); ok {
+				name = v
+			}
+		}
+		result[name] = item
+	}
+	return result
+}
+
+// flatten converts nested maps and lists to the map of paths and scalar values,
+// where map keys are joined with dots and list indexes and keyed entries are put in brackets
+func flatten(prefix string, value interface{}, fields map[string]string) {
+	switch v := value.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for key, item := range v {
+			path := key
+			if prefix != "" {
+				path = fmt.Sprintf("%s.%s", prefix, key)
+				if strings.ContainsAny(key, "./ ") {
+					path = fmt.Sprintf("%s[%s]", prefix, key)
+				}
+			}
+			flatten(path, item, fields)
+		}
+	case keyedMap:
+		for key, item := range v {
+			flatten(fmt.Sprintf("%s[%s]", prefix, key), item, fields)
+		}
+	case []interface{}:
+		for i, item := range v {
+			flatten(fmt.Sprintf("%s[%d]", prefix, i), item, fields)
+		}
+	case string:
+		fields[prefix] = v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			fields[prefix] = fmt.Sprint(v)
+			return
+		}
+		fields[prefix] = string(b)
+	}
+}
@@ -0,0 +1,144 @@
+package manifestset
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+)
+
+const testAssetsDir = "../../../../../build/assets"
+
+func getComponents(profile *performancev2.PerformanceProfile) *ManifestResultSet {
+	assetsDir := testAssetsDir
+	components, err := GetNewComponents(profile, &assetsDir)
+	Expect(err).ToNot(HaveOccurred())
+	return components
+}
+
+func getComponentDiff(diff *ManifestResultSetDiff, kind string) ComponentDiff {
+	for _, c := range diff.Components {
+		if c.Kind == kind {
+			return c
+		}
+	}
+	Fail("failed to find the component diff for " + kind)
+	return ComponentDiff{}
+}
+
+func getChangedPaths(c ComponentDiff) []string {
+	var paths []string
+	for _, change := range c.Changes {
+		paths = append(paths, change.Path)
+	}
+	return paths
+}
+
+var _ = Describe("Manifest result set diff", func() {
+	var profile *performancev2.PerformanceProfile
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+	})
+
+	It("should not report changes for identical components", func() {
+		diff, err := Diff(getComponents(profile), getComponents(profile))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.HasChanges()).To(BeFalse())
+		Expect(diff.RebootRequired()).To(BeFalse())
+	})
+
+	It("should report all fields as added when old components do not exist", func() {
+		diff, err := Diff(nil, getComponents(profile))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.HasChanges()).To(BeTrue())
+		Expect(diff.RebootRequired()).To(BeTrue())
+
+		rc := getComponentDiff(diff, kindRuntimeClass)
+		Expect(rc.Name).To(Equal("performance-test"))
+		for _, change := range rc.Changes {
+			Expect(change.Old).To(BeNil())
+			Expect(change.New).ToNot(BeNil())
+		}
+	})
+
+	It("should decode ignition files and report the reboot for reserved CPUs change", func() {
+		oldComponents := getComponents(profile)
+
+		reserved := performancev2.CPUSet("0-1")
+		isolated := performancev2.CPUSet("2-7")
+		profile.Spec.CPU.Reserved = &reserved
+		profile.Spec.CPU.Isolated = &isolated
+
+		diff, err := Diff(oldComponents, getComponents(profile))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RebootRequired()).To(BeTrue())
+
+		mc := getComponentDiff(diff, kindMachineConfig)
+		Expect(mc.RebootRequired).To(BeTrue())
+		Expect(getChangedPaths(mc)).To(ContainElement("spec.config.storage.files[/etc/crio/crio.conf.d/99-runtimes.conf].contents.source"))
+		for _, change := range mc.Changes {
+			if change.Path == "spec.config.storage.files[/etc/crio/crio.conf.d/99-runtimes.conf].contents.source" {
+				Expect(*change.Old).To(ContainSubstring(`infra_ctr_cpuset = "0-3"`))
+				Expect(*change.New).To(ContainSubstring(`infra_ctr_cpuset = "0-1"`))
+			}
+		}
+
+		kc := getComponentDiff(diff, kindKubeletConfig)
+		Expect(kc.RebootRequired).To(BeTrue())
+		Expect(getChangedPaths(kc)).To(ConsistOf("spec.kubeletConfig.reservedSystemCPUs"))
+
+		tuned := getComponentDiff(diff, kindTuned)
+		Expect(tuned.RebootRequired).To(BeTrue())
+		Expect(getChangedPaths(tuned)).To(ContainElement("spec.profile[openshift-node-performance-test].data[variables].isolated_cores"))
+	})
+
+	It("should report the reboot for the tuned variables section changes", func() {
+		oldComponents := getComponents(profile)
+
+		isolated := performancev2.CPUSet("4-8")
+		profile.Spec.CPU.Isolated = &isolated
+
+		diff, err := Diff(oldComponents, getComponents(profile))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RebootRequired()).To(BeTrue())
+
+		tuned := getComponentDiff(diff, kindTuned)
+		Expect(tuned.RebootRequired).To(BeTrue())
+		Expect(getChangedPaths(tuned)).To(ConsistOf("spec.profile[openshift-node-performance-test].data[variables].isolated_cores"))
+	})
+
+	It("should not report the reboot for tuned sections that do not affect kernel arguments", func() {
+		oldComponents := getComponents(profile)
+		newComponents := getComponents(profile)
+		newComponents.Tuned.Spec.Profile[0].Data = stringPtr(*newComponents.Tuned.Spec.Profile[0].Data + "\n[sysctl]\nkernel.test=1\n")
+
+		diff, err := Diff(oldComponents, newComponents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RebootRequired()).To(BeFalse())
+
+		tuned := getComponentDiff(diff, kindTuned)
+		Expect(getChangedPaths(tuned)).To(ConsistOf("spec.profile[openshift-node-performance-test].data[sysctl][kernel.test]"))
+
+		newComponents.Tuned.Spec.Profile[0].Data = stringPtr(*newComponents.Tuned.Spec.Profile[0].Data + "\n[bootloader]\ncmdline_test=test\n")
+		diff, err = Diff(oldComponents, newComponents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RebootRequired()).To(BeTrue())
+	})
+
+	It("should not report the reboot for the runtime class changes", func() {
+		oldComponents := getComponents(profile)
+		newComponents := getComponents(profile)
+		newComponents.RuntimeClass.Handler = "test"
+
+		diff, err := Diff(oldComponents, newComponents)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(diff.RebootRequired()).To(BeFalse())
+		Expect(getChangedPaths(getComponentDiff(diff, kindRuntimeClass))).To(ConsistOf("spec.handler"))
+	})
+})
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,94 @@
+package manifestset
+
+import (
+	"fmt"
+	"strings"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+)
+
+// changeImpactSeverity orders change impact types from the least to the most disruptive
+var changeImpactSeverity = map[performancev2.ChangeImpactType]int{
+	performancev2.ChangeImpactNone:                0,
+	performancev2.ChangeImpactTunedReload:         1,
+	performancev2.ChangeImpactTunedReboot:         2,
+	performancev2.ChangeImpactKubeletConfigUpdate: 3,
+	performancev2.ChangeImpactMachineConfigUpdate: 4,
+}
+
+var changeImpactMessages = map[performancev2.ChangeImpactType]string{
+	performancev2.ChangeImpactNone:                "the change does not affect nodes",
+	performancev2.ChangeImpactTunedReload:         "the change updates only the tuned profile, it will be reloaded without a node reboot",
+	performancev2.ChangeImpactTunedReboot:         "the change updates kernel arguments of the tuned profile, every node in the pool will be rebooted to apply new kernel arguments",
+	performancev2.ChangeImpactKubeletConfigUpdate: "the change updates the kubelet config, every node in the pool will be rebooted",
+	performancev2.ChangeImpactMachineConfigUpdate: "the change updates the machine config, every node in the pool will be rebooted",
+}
+
+// GetChangeImpact returns the impact on nodes of the components change described by the diff
+func GetChangeImpact(diff *ManifestResultSetDiff) *performancev2.ChangeImpact {
+	impact := &performancev2.ChangeImpact{
+		Type: performancev2.ChangeImpactNone,
+	}
+
+	for _, c := range diff.Components {
+		if len(c.Changes) == 0 {
+			continue
+		}
+
+		impact.Components = append(impact.Components, c.Kind)
+		impact.RebootRequired = impact.RebootRequired || c.RebootRequired
+
+		componentImpact := getComponentChangeImpact(c)
+		if changeImpactSeverity[componentImpact] > changeImpactSeverity[impact.Type] {
+			impact.Type = componentImpact
+		}
+	}
+
+	impact.Message = changeImpactMessages[impact.Type]
+	if len(impact.Components) > 0 {
+		impact.Message = fmt.Sprintf("%s (changed %s)", impact.Message, strings.Join(impact.Components, ", "))
+	}
+	return impact
+}
+
+func getComponentChangeImpact(c ComponentDiff) performancev2.ChangeImpactType {
+	switch c.Kind {
+	case kindMachineConfig:
+		if c.RebootRequired {
+			return performancev2.ChangeImpactMachineConfigUpdate
+		}
+	case kindKubeletConfig:
+		if c.RebootRequired {
+			return performancev2.ChangeImpactKubeletConfigUpdate
+		}
+	case kindTuned:
+		if c.RebootRequired {
+			return performancev2.ChangeImpactTunedReboot
+		}
+		return performancev2.ChangeImpactTunedReload
+	}
+	return performancev2.ChangeImpactNone
+}
+
+// GetProfileChangeImpact renders components for the old and the new profiles and returns the impact
+// of the change on nodes, the nil old profile means that the profile is created
+func GetProfileChangeImpact(oldProfile, newProfile *performancev2.PerformanceProfile, assetsDir string) (*performancev2.ChangeImpact, error) {
+	var oldComponents *ManifestResultSet
+	if oldProfile != nil {
+		var err error
+		if oldComponents, err = GetNewComponents(oldProfile, &assetsDir); err != nil {
+			return nil, err
+		}
+	}
+
+	newComponents, err := GetNewComponents(newProfile, &assetsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := Diff(oldComponents, newComponents)
+	if err != nil {
+		return nil, err
+	}
+	return GetChangeImpact(diff), nil
+}
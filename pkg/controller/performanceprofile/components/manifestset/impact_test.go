@@ -0,0 +1,81 @@
+package manifestset
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/pointer"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+)
+
+var _ = Describe("Performance profile change impact", func() {
+	var oldProfile *performancev2.PerformanceProfile
+	var newProfile *performancev2.PerformanceProfile
+
+	BeforeEach(func() {
+		oldProfile = testutils.NewPerformanceProfile("test")
+		newProfile = oldProfile.DeepCopy()
+	})
+
+	It("should not have an impact when the profile does not change", func() {
+		impact, err := GetProfileChangeImpact(oldProfile, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactNone))
+		Expect(impact.RebootRequired).To(BeFalse())
+		Expect(impact.Components).To(BeEmpty())
+	})
+
+	It("should report the machine config update when the profile is created", func() {
+		impact, err := GetProfileChangeImpact(nil, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+		Expect(impact.RebootRequired).To(BeTrue())
+		Expect(impact.Components).To(ConsistOf(kindMachineConfig, kindKubeletConfig, kindTuned, kindRuntimeClass))
+	})
+
+	It("should report the machine config update when reserved CPUs change", func() {
+		reserved := performancev2.CPUSet("0-1")
+		isolated := performancev2.CPUSet("2-7")
+		newProfile.Spec.CPU.Reserved = &reserved
+		newProfile.Spec.CPU.Isolated = &isolated
+
+		impact, err := GetProfileChangeImpact(oldProfile, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactMachineConfigUpdate))
+		Expect(impact.RebootRequired).To(BeTrue())
+		Expect(impact.Components).To(ContainElements(kindMachineConfig, kindKubeletConfig, kindTuned))
+	})
+
+	It("should report the tuned reboot when the tuned bootloader section changes", func() {
+		newProfile.Spec.CPU.BalanceIsolated = pointer.BoolPtr(false)
+
+		impact, err := GetProfileChangeImpact(oldProfile, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactTunedReboot))
+		Expect(impact.RebootRequired).To(BeTrue())
+		Expect(impact.Components).To(ConsistOf(kindTuned))
+	})
+
+	It("should report the tuned reboot when isolated CPUs change", func() {
+		isolated := performancev2.CPUSet("4-8")
+		newProfile.Spec.CPU.Isolated = &isolated
+
+		impact, err := GetProfileChangeImpact(oldProfile, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactTunedReboot))
+		Expect(impact.RebootRequired).To(BeTrue())
+		Expect(impact.Components).To(ConsistOf(kindTuned))
+	})
+
+	It("should report the tuned reload when only tuned options change", func() {
+		newProfile.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+
+		impact, err := GetProfileChangeImpact(oldProfile, newProfile, testAssetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(impact.Type).To(Equal(performancev2.ChangeImpactTunedReload))
+		Expect(impact.RebootRequired).To(BeFalse())
+		Expect(impact.Components).To(ConsistOf(kindTuned))
+	})
+})
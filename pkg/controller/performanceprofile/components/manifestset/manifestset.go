@@ -1,6 +1,11 @@
 package manifestset
 
 import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/kubeletconfig"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/machineconfig"
@@ -63,7 +68,7 @@ func GetNewComponents(profile *performancev2.PerformanceProfile, assetDir *strin
 		return nil, err
 	}
 
-	runtimeClass := runtimeclass.New(profile, machineconfig.HighPerformanceRuntime)
+	runtimeClass := runtimeclass.New(profile, machineconfig.HighPerformanceRuntimeAnnotations)
 
 	manifestResultSet := ManifestResultSet{
 		MachineConfig: mc,
@@ -73,3 +78,44 @@ func GetNewComponents(profile *performancev2.PerformanceProfile, assetDir *strin
 	}
 	return &manifestResultSet, nil
 }
+
+// GetHash returns the hash of all components, it identifies the rendered state of the profile
+func (ms *ManifestResultSet) GetHash() (string, error) {
+	b, err := json.Marshal(ms)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))[:16], nil
+}
+
+// GetComponentHashes returns the hash of the rendered content of each component keyed by the component kind,
+// only the name, labels and the spec of components are hashed
+func (ms *ManifestResultSet) GetComponentHashes() (map[string]string, error) {
+	objs := map[string]interface{}{
+		kindMachineConfig: ms.MachineConfig,
+		kindKubeletConfig: ms.KubeletConfig,
+		kindTuned:         ms.Tuned,
+		kindRuntimeClass:  ms.RuntimeClass,
+	}
+
+	hashes := map[string]string{}
+	for kind, obj := range objs {
+		fields, err := flattenComponent(kind, obj)
+		if err != nil {
+			return nil, err
+		}
+
+		paths := make([]string, 0, len(fields))
+		for path := range fields {
+			paths = append(paths, path)
+		}
+		sort.Strings(paths)
+
+		h := sha256.New()
+		for _, path := range paths {
+			fmt.Fprintf(h, "%s=%q\n", path, fields[path])
+		}
+		hashes[kind] = fmt.Sprintf("%x", h.Sum(nil))[:16]
+	}
+	return hashes, nil
+}
@@ -0,0 +1,13 @@
+package manifestset
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestManifestSet(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "ManifestSet Suite")
+}
@@ -0,0 +1,45 @@
+package manifestset
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/utils/pointer"
+
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+)
+
+var _ = Describe("Manifest result set component hashes", func() {
+	It("should change only the hashes of changed components", func() {
+		assetsDir := testAssetsDir
+		profile := testutils.NewPerformanceProfile("test")
+		components, err := GetNewComponents(profile, &assetsDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		hashes, err := components.GetComponentHashes()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(hashes).To(HaveLen(4))
+		Expect(hashes).To(HaveKey(kindMachineConfig))
+
+		profile.Spec.GloballyDisableIrqLoadBalancing = pointer.BoolPtr(true)
+		components, err = GetNewComponents(profile, &assetsDir)
+		Expect(err).ToNot(HaveOccurred())
+
+		newHashes, err := components.GetComponentHashes()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(newHashes[kindTuned]).ToNot(Equal(hashes[kindTuned]))
+		Expect(newHashes[kindMachineConfig]).To(Equal(hashes[kindMachineConfig]))
+		Expect(newHashes[kindKubeletConfig]).To(Equal(hashes[kindKubeletConfig]))
+		Expect(newHashes[kindRuntimeClass]).To(Equal(hashes[kindRuntimeClass]))
+	})
+
+	It("should render components of the profile without CPUs", func() {
+		assetsDir := testAssetsDir
+		profile := testutils.NewPerformanceProfile("test")
+		profile.Spec.CPU = nil
+
+		components, err := GetNewComponents(profile, &assetsDir)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(components.KubeletConfig.Spec.KubeletConfig).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,63 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/jaypipes/ghw/pkg/topology"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+
+	cpuset "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+)
+
+// IsCPUsComputed returns whether or not reserved and isolated CPUs of the profile are computed from the node topology
+func IsCPUsComputed(profile *performancev2.PerformanceProfile) bool {
+	return profile.Spec.CPU != nil && profile.Spec.CPU.ReservedCount != nil
+}
+
+// GetCPUsFromTopology returns reserved and isolated CPUs computed from the reserved CPUs count of the profile
+func GetCPUsFromTopology(profile *performancev2.PerformanceProfile, topologyInfo *topology.Info) (*performancev2.CPUStatus, error) {
+	reservedCount := int(*profile.Spec.CPU.ReservedCount)
+	totalCount := cpuallocation.TotalCPUSet(topologyInfo.Nodes).Size()
+	if reservedCount >= totalCount {
+		return nil, fmt.Errorf("can not reserve %d CPUs out of %d CPUs", reservedCount, totalCount)
+	}
+
+	policy := performancev2.CPUPlacementPolicySequential
+	if profile.Spec.CPU.PlacementPolicy != nil {
+		policy = *profile.Spec.CPU.PlacementPolicy
+	}
+
+	htEnabled := cpuallocation.IsHyperthreadingEnabled(topologyInfo.Nodes)
+
+	var reserved, isolated cpuset.CPUSet
+	var err error
+	switch policy {
+	case performancev2.CPUPlacementPolicySequential:
+		reserved, isolated, err = cpuallocation.Sequential(reservedCount, htEnabled, topologyInfo.Nodes)
+	case performancev2.CPUPlacementPolicySplitAcrossNUMA:
+		reserved, isolated, err = cpuallocation.SplitAcrossNUMA(reservedCount, htEnabled, topologyInfo.Nodes)
+	case performancev2.CPUPlacementPolicyAvoidHTSiblings:
+		reserved, isolated, err = cpuallocation.WholeCores(reservedCount, topologyInfo.Nodes)
+	default:
+		return nil, fmt.Errorf("unknown CPU placement policy %q", policy)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &performancev2.CPUStatus{
+		Reserved: performancev2.CPUSet(reserved.String()),
+		Isolated: performancev2.CPUSet(isolated.String()),
+	}, nil
+}
+
+// SetComputedCPUs sets reserved and isolated CPUs computed by the operator under the profile spec
+func SetComputedCPUs(profile *performancev2.PerformanceProfile, cpus *performancev2.CPUStatus) {
+	reserved := cpus.Reserved
+	isolated := cpus.Isolated
+	profile.Spec.CPU.Reserved = &reserved
+	profile.Spec.CPU.Isolated = &isolated
+}
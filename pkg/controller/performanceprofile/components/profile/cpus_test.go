@@ -0,0 +1,72 @@
+package profile
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+const testCPUTopology = `{"nodes":[` +
+	`{"id":1,"cores":[{"id":0,"logical_processors":[5,1]},{"id":1,"logical_processors":[3,7]}]},` +
+	`{"id":0,"cores":[{"id":1,"logical_processors":[2,6]},{"id":0,"logical_processors":[0,4]}]}]}`
+
+var _ = Describe("Computed CPUs", func() {
+	var profile *performancev2.PerformanceProfile
+	var node *corev1.Node
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+		profile.Spec.CPU = &performancev2.CPU{
+			ReservedCount: pointer.Int32Ptr(2),
+		}
+
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node",
+				Annotations: map[string]string{
+					performancev2.CPUTopologyAnnotation: testCPUTopology,
+				},
+			},
+		}
+	})
+
+	It("should compute CPUs with the sorted topology", func() {
+		topologyInfo, err := performancev2.GetNodeCPUTopology(node)
+		Expect(err).ToNot(HaveOccurred())
+
+		cpus, err := GetCPUsFromTopology(profile, topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cpus.Reserved).To(Equal(performancev2.CPUSet("0,4")))
+		Expect(cpus.Isolated).To(Equal(performancev2.CPUSet("1-3,5-7")))
+	})
+
+	It("should compute CPUs with the placement policy", func() {
+		policy := performancev2.CPUPlacementPolicySplitAcrossNUMA
+		profile.Spec.CPU.ReservedCount = pointer.Int32Ptr(4)
+		profile.Spec.CPU.PlacementPolicy = &policy
+
+		topologyInfo, err := performancev2.GetNodeCPUTopology(node)
+		Expect(err).ToNot(HaveOccurred())
+
+		cpus, err := GetCPUsFromTopology(profile, topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cpus.Reserved).To(Equal(performancev2.CPUSet("0-1,4-5")))
+		Expect(cpus.Isolated).To(Equal(performancev2.CPUSet("2-3,6-7")))
+	})
+
+	It("should reject reserving all CPUs", func() {
+		profile.Spec.CPU.ReservedCount = pointer.Int32Ptr(8)
+
+		topologyInfo, err := performancev2.GetNodeCPUTopology(node)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = GetCPUsFromTopology(profile, topologyInfo)
+		Expect(err).To(HaveOccurred())
+	})
+})
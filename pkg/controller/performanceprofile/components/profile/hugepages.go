@@ -0,0 +1,112 @@
+package profile
+
+import (
+	"encoding/json"
+	"reflect"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HugePagesAllocation describes huge pages allocated on NUMA nodes reported by the hugepages-report service
+// of the generated machine config under the HugePagesAllocationAnnotation node annotation
+type HugePagesAllocation struct {
+	// Timestamp is the time when huge pages were read from the node
+	Timestamp metav1.Time `json:"timestamp"`
+	// Pages contains the number of huge pages of each size allocated on each NUMA node
+	Pages []performancev2.HugePage `json:"pages"`
+}
+
+// IsHugePagesRuntimeAllocation returns whether or not huge pages of the profile are allocated at runtime
+func IsHugePagesRuntimeAllocation(profile *performancev2.PerformanceProfile) bool {
+	return profile.Spec.HugePages != nil &&
+		profile.Spec.HugePages.RuntimeAllocation != nil &&
+		*profile.Spec.HugePages.RuntimeAllocation
+}
+
+// GetRuntimeHugePages returns huge pages allocated at runtime, only huge pages allocated on specific NUMA nodes
+// with a size other than the default huge pages size can be allocated at runtime
+func GetRuntimeHugePages(profile *performancev2.PerformanceProfile) []performancev2.HugePage {
+	if !IsHugePagesRuntimeAllocation(profile) {
+		return nil
+	}
+
+	var pages []performancev2.HugePage
+	for _, page := range profile.Spec.HugePages.Pages {
+		if page.Node == nil {
+			continue
+		}
+
+		if profile.Spec.HugePages.DefaultHugePagesSize != nil && *profile.Spec.HugePages.DefaultHugePagesSize == page.Size {
+			continue
+		}
+		pages = append(pages, *page.DeepCopy())
+	}
+	return pages
+}
+
+// IsRuntimeHugePage returns whether or not the huge page is allocated at runtime
+func IsRuntimeHugePage(profile *performancev2.PerformanceProfile, page *performancev2.HugePage) bool {
+	return performancev2.FindHugePage(GetRuntimeHugePages(profile), *page) != -1
+}
+
+// DisableHugePagesRuntimeAllocation makes huge pages of the profile to be allocated on boot
+func DisableHugePagesRuntimeAllocation(profile *performancev2.PerformanceProfile) {
+	if profile.Spec.HugePages == nil {
+		return
+	}
+
+	disabled := false
+	profile.Spec.HugePages.RuntimeAllocation = &disabled
+}
+
+// IsHugePagesFallbackToBoot returns whether or not the profile status reports that nodes failed to allocate
+// current huge pages of the profile at runtime
+func IsHugePagesFallbackToBoot(profile *performancev2.PerformanceProfile) bool {
+	if profile.Status.HugePages == nil || profile.Status.HugePages.RuntimeAllocation == nil {
+		return false
+	}
+
+	allocation := profile.Status.HugePages.RuntimeAllocation
+	return allocation.FallbackToBoot && reflect.DeepEqual(allocation.Pages, GetRuntimeHugePages(profile))
+}
+
+// GetNodeHugePagesAllocation returns huge pages allocated on the node from the node annotation,
+// the nil value means that the node does not have the annotation
+func GetNodeHugePagesAllocation(node *corev1.Node) (*HugePagesAllocation, error) {
+	data, ok := node.Annotations[performancev2.HugePagesAllocationAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	allocation := &HugePagesAllocation{}
+	if err := json.Unmarshal([]byte(data), allocation); err != nil {
+		return nil, err
+	}
+	return allocation, nil
+}
+
+// IsNodeHugePagesAllocationChanged returns whether or not huge pages reported by the node changed, nodes repeat
+// the report periodically with a new timestamp, so only reported huge pages are compared
+func IsNodeHugePagesAllocationChanged(old *corev1.Node, new *corev1.Node) bool {
+	oldAllocation, oldErr := GetNodeHugePagesAllocation(old)
+	newAllocation, newErr := GetNodeHugePagesAllocation(new)
+	if oldErr != nil || newErr != nil {
+		return old.Annotations[performancev2.HugePagesAllocationAnnotation] != new.Annotations[performancev2.HugePagesAllocationAnnotation]
+	}
+
+	if oldAllocation == nil || newAllocation == nil {
+		return oldAllocation != newAllocation
+	}
+	return !reflect.DeepEqual(oldAllocation.Pages, newAllocation.Pages)
+}
+
+// GetAllocatedHugePagesCount returns the number of huge pages of the same size allocated on the same NUMA node
+func (a *HugePagesAllocation) GetAllocatedHugePagesCount(page *performancev2.HugePage) int32 {
+	if i := performancev2.FindHugePage(a.Pages, *page); i != -1 {
+		return a.Pages[i].Count
+	}
+	return 0
+}
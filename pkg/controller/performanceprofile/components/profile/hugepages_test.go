@@ -0,0 +1,80 @@
+package profile
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Huge pages runtime allocation", func() {
+	var profile *performancev2.PerformanceProfile
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+		profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+		profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+			performancev2.HugePage{Size: "1G", Count: 2, Node: pointer.Int32Ptr(0)},
+			performancev2.HugePage{Size: "2M", Count: 128, Node: pointer.Int32Ptr(1)},
+		)
+	})
+
+	It("should return only NUMA pinned huge pages of non default sizes", func() {
+		pages := GetRuntimeHugePages(profile)
+		Expect(pages).To(Equal([]performancev2.HugePage{
+			{Size: "2M", Count: 128, Node: pointer.Int32Ptr(1)},
+		}))
+		Expect(IsRuntimeHugePage(profile, &profile.Spec.HugePages.Pages[2])).To(BeTrue())
+		Expect(IsRuntimeHugePage(profile, &profile.Spec.HugePages.Pages[1])).To(BeFalse())
+	})
+
+	It("should not return huge pages when the runtime allocation is disabled", func() {
+		DisableHugePagesRuntimeAllocation(profile)
+		Expect(GetRuntimeHugePages(profile)).To(BeEmpty())
+	})
+
+	It("should parse huge pages allocated on the node", func() {
+		node := &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node",
+				Annotations: map[string]string{
+					performancev2.HugePagesAllocationAnnotation: `{"timestamp":"2021-06-01T10:00:00Z","pages":[{"size":"2M","node":1,"count":100}]}`,
+				},
+			},
+		}
+
+		allocation, err := GetNodeHugePagesAllocation(node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocation.GetAllocatedHugePagesCount(&profile.Spec.HugePages.Pages[2])).To(Equal(int32(100)))
+		Expect(allocation.GetAllocatedHugePagesCount(&profile.Spec.HugePages.Pages[1])).To(Equal(int32(0)))
+
+		node.Annotations = nil
+		allocation, err = GetNodeHugePagesAllocation(node)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allocation).To(BeNil())
+	})
+
+	It("should ignore reports of the node that repeat the same huge pages", func() {
+		newNode := func(report string) *corev1.Node {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}}
+			if report != "" {
+				node.Annotations = map[string]string{performancev2.HugePagesAllocationAnnotation: report}
+			}
+			return node
+		}
+
+		old := newNode(`{"timestamp":"2021-06-01T10:00:00Z","pages":[{"size":"2M","node":1,"count":100}]}`)
+		heartbeat := newNode(`{"timestamp":"2021-06-01T10:05:00Z","pages":[{"size":"2M","node":1,"count":100}]}`)
+		changed := newNode(`{"timestamp":"2021-06-01T10:05:00Z","pages":[{"size":"2M","node":1,"count":128}]}`)
+
+		Expect(IsNodeHugePagesAllocationChanged(old, heartbeat)).To(BeFalse())
+		Expect(IsNodeHugePagesAllocationChanged(old, changed)).To(BeTrue())
+		Expect(IsNodeHugePagesAllocationChanged(newNode(""), old)).To(BeTrue())
+		Expect(IsNodeHugePagesAllocationChanged(old, newNode("invalid"))).To(BeTrue())
+	})
+})
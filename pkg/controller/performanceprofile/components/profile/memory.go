@@ -0,0 +1,191 @@
+package profile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/topology"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	cpuset "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+)
+
+// reservedMemoryUnit is the unit of the memory reserved on each NUMA node when the reservation is split
+// between NUMA nodes, the remainder is reserved on the first NUMA node
+var reservedMemoryUnit = resource.MustParse("1Mi")
+
+// IsReservedMemoryDerived returns whether or not the memory reserved on NUMA nodes for the Static memory manager
+// policy is derived from the kubelet reservation
+func IsReservedMemoryDerived(profile *performancev2.PerformanceProfile) bool {
+	return performancev2.GetMemoryManagerPolicy(&profile.Spec) == performancev2.MemoryManagerPolicyStatic &&
+		len(profile.Spec.Kubelet.MemoryManager.ReservedMemory) == 0
+}
+
+// GetReservedMemory returns the memory reserved on NUMA nodes for the Static memory manager policy, when the profile
+// does not specify it, the memory reserved by the kubelet is split evenly between NUMA nodes of reserved CPUs, or
+// reserved on the NUMA node 0 when the CPU topology is not known, huge pages are split between NUMA nodes with huge
+// pages of the size when the profile allocates them on specific NUMA nodes only
+func GetReservedMemory(profile *performancev2.PerformanceProfile, topologyInfo *topology.Info) ([]performancev2.NUMAReservedMemory, error) {
+	if performancev2.GetMemoryManagerPolicy(&profile.Spec) != performancev2.MemoryManagerPolicyStatic {
+		return nil, nil
+	}
+
+	if !IsReservedMemoryDerived(profile) {
+		var reservedMemory []performancev2.NUMAReservedMemory
+		for _, numaReserved := range profile.Spec.Kubelet.MemoryManager.ReservedMemory {
+			reservedMemory = append(reservedMemory, *numaReserved.DeepCopy())
+		}
+		return reservedMemory, nil
+	}
+
+	required, err := performancev2.GetRequiredReservedMemory(&profile.Spec)
+	if err != nil {
+		return nil, err
+	}
+
+	numaNodes := []int32{0}
+	if topologyInfo != nil && profile.Spec.CPU != nil && profile.Spec.CPU.Reserved != nil {
+		reserved, err := cpuset.Parse(string(*profile.Spec.CPU.Reserved))
+		if err != nil {
+			return nil, err
+		}
+
+		if ids := getNUMANodes(reserved, topologyInfo); len(ids) != 0 {
+			numaNodes = ids
+		}
+	}
+
+	limits := map[int32]corev1.ResourceList{}
+	for name, quantity := range required {
+		resourceNUMANodes := numaNodes
+		unit := reservedMemoryUnit.Value()
+
+		// huge pages are reserved in whole pages on NUMA nodes that have them
+		if strings.HasPrefix(string(name), corev1.ResourceHugePagesPrefix) {
+			size, err := resource.ParseQuantity(strings.TrimPrefix(string(name), corev1.ResourceHugePagesPrefix))
+			if err != nil {
+				return nil, err
+			}
+			unit = size.Value()
+
+			if ids, err := getHugePagesNUMANodes(profile, unit); err != nil {
+				return nil, err
+			} else if len(ids) != 0 {
+				resourceNUMANodes = ids
+			}
+		}
+
+		for i, part := range splitQuantity(quantity, len(resourceNUMANodes), unit) {
+			if part.IsZero() {
+				continue
+			}
+
+			numaNode := resourceNUMANodes[i]
+			if limits[numaNode] == nil {
+				limits[numaNode] = corev1.ResourceList{}
+			}
+			limits[numaNode][name] = part
+		}
+	}
+
+	var reservedMemory []performancev2.NUMAReservedMemory
+	for numaNode, numaLimits := range limits {
+		reservedMemory = append(reservedMemory, performancev2.NUMAReservedMemory{
+			NUMANode: numaNode,
+			Limits:   numaLimits,
+		})
+	}
+	sort.Slice(reservedMemory, func(i, j int) bool {
+		return reservedMemory[i].NUMANode < reservedMemory[j].NUMANode
+	})
+
+	return reservedMemory, nil
+}
+
+// SetReservedMemory sets the memory reserved on NUMA nodes under the profile spec
+func SetReservedMemory(profile *performancev2.PerformanceProfile, reservedMemory []performancev2.NUMAReservedMemory) {
+	profile.Spec.Kubelet.MemoryManager.ReservedMemory = reservedMemory
+}
+
+// getNUMANodes returns sorted IDs of NUMA nodes of CPUs, CPUs that do not exist in the topology are skipped
+func getNUMANodes(cpus cpuset.CPUSet, topologyInfo *topology.Info) []int32 {
+	var ids []int32
+	for _, node := range topologyInfo.Nodes {
+		found := false
+		for _, core := range node.Cores {
+			for _, processor := range core.LogicalProcessors {
+				if cpus.Contains(processor) {
+					found = true
+				}
+			}
+		}
+
+		if found {
+			ids = append(ids, int32(node.ID))
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids
+}
+
+// getHugePagesNUMANodes returns sorted IDs of NUMA nodes with huge pages of the size allocated by the profile,
+// the nil value means that the profile does not allocate huge pages of the size or allocates them on all NUMA nodes
+func getHugePagesNUMANodes(profile *performancev2.PerformanceProfile, size int64) ([]int32, error) {
+	if profile.Spec.HugePages == nil {
+		return nil, nil
+	}
+
+	numaNodes := map[int32]bool{}
+	for _, page := range profile.Spec.HugePages.Pages {
+		pageSize, err := performancev2.GetHugePageSizeBytes(page.Size)
+		if err != nil {
+			return nil, err
+		}
+
+		if pageSize != size || page.Count == 0 {
+			continue
+		}
+
+		// the kernel distributes huge pages allocated without the NUMA node between all NUMA nodes
+		if page.Node == nil {
+			return nil, nil
+		}
+		numaNodes[*page.Node] = true
+	}
+
+	var ids []int32
+	for id := range numaNodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+	return ids, nil
+}
+
+// splitQuantity splits the quantity into the number of parts that are multiples of the unit, the remainder
+// is added to the first part
+func splitQuantity(quantity resource.Quantity, parts int, unit int64) []resource.Quantity {
+	if parts == 1 {
+		return []resource.Quantity{quantity.DeepCopy()}
+	}
+
+	total := quantity.Value()
+	part := total / int64(parts) / unit * unit
+
+	quantities := make([]resource.Quantity, parts)
+	for i := range quantities {
+		value := part
+		if i == 0 {
+			value = total - part*int64(parts-1)
+		}
+		quantities[i] = *resource.NewQuantity(value, resource.BinarySI)
+	}
+	return quantities
+}
@@ -0,0 +1,110 @@
+package profile
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/pointer"
+)
+
+var _ = Describe("Memory manager reserved memory", func() {
+	var profile *performancev2.PerformanceProfile
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+		policy := performancev2.MemoryManagerPolicyStatic
+		profile.Spec.Kubelet = &performancev2.Kubelet{
+			SystemReserved: corev1.ResourceList{
+				corev1.ResourceMemory:                resource.MustParse("1Gi"),
+				corev1.ResourceName("hugepages-2Mi"): resource.MustParse("64Mi"),
+			},
+			MemoryManager: &performancev2.MemoryManager{Policy: &policy},
+		}
+	})
+
+	It("should reserve memory and huge pages on the NUMA node of reserved CPUs", func() {
+		reserved := performancev2.CPUSet("1,5")
+		profile.Spec.CPU.Reserved = &reserved
+
+		topologyInfo, err := cpuallocation.ParseTopology(testCPUTopology)
+		Expect(err).ToNot(HaveOccurred())
+
+		reservedMemory, err := GetReservedMemory(profile, topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservedMemory).To(HaveLen(1))
+		Expect(reservedMemory[0].NUMANode).To(Equal(int32(1)))
+
+		memory := reservedMemory[0].Limits[corev1.ResourceMemory]
+		Expect(memory.String()).To(Equal("1624Mi"))
+		hugepages := reservedMemory[0].Limits[corev1.ResourceName("hugepages-2Mi")]
+		Expect(hugepages.String()).To(Equal("64Mi"))
+	})
+
+	It("should split memory and huge pages between NUMA nodes of reserved CPUs", func() {
+		reserved := performancev2.CPUSet("0-1")
+		profile.Spec.CPU.Reserved = &reserved
+		profile.Spec.Kubelet.SystemReserved[corev1.ResourceName("hugepages-1Gi")] = resource.MustParse("1Gi")
+
+		topologyInfo, err := cpuallocation.ParseTopology(testCPUTopology)
+		Expect(err).ToNot(HaveOccurred())
+
+		reservedMemory, err := GetReservedMemory(profile, topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservedMemory).To(HaveLen(2))
+		Expect(reservedMemory[0].NUMANode).To(Equal(int32(0)))
+		Expect(reservedMemory[1].NUMANode).To(Equal(int32(1)))
+
+		// 1624Mi of memory and 64Mi of 2M huge pages are split evenly, 1G huge pages can not be split
+		for _, numaReserved := range reservedMemory {
+			memory := numaReserved.Limits[corev1.ResourceMemory]
+			Expect(memory.String()).To(Equal("812Mi"))
+			hugepages := numaReserved.Limits[corev1.ResourceName("hugepages-2Mi")]
+			Expect(hugepages.String()).To(Equal("32Mi"))
+		}
+		hugepages := reservedMemory[0].Limits[corev1.ResourceName("hugepages-1Gi")]
+		Expect(hugepages.String()).To(Equal("1Gi"))
+		Expect(reservedMemory[1].Limits).ToNot(HaveKey(corev1.ResourceName("hugepages-1Gi")))
+	})
+
+	It("should reserve huge pages on NUMA nodes with huge pages of the size", func() {
+		reserved := performancev2.CPUSet("0-1")
+		profile.Spec.CPU.Reserved = &reserved
+		profile.Spec.HugePages.Pages = []performancev2.HugePage{
+			{Size: "2M", Count: 512, Node: pointer.Int32Ptr(1)},
+		}
+
+		topologyInfo, err := cpuallocation.ParseTopology(testCPUTopology)
+		Expect(err).ToNot(HaveOccurred())
+
+		reservedMemory, err := GetReservedMemory(profile, topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservedMemory).To(HaveLen(2))
+		Expect(reservedMemory[0].Limits).ToNot(HaveKey(corev1.ResourceName("hugepages-2Mi")))
+		hugepages := reservedMemory[1].Limits[corev1.ResourceName("hugepages-2Mi")]
+		Expect(hugepages.String()).To(Equal("64Mi"))
+	})
+
+	It("should reserve memory on the NUMA node 0 without the CPU topology", func() {
+		reservedMemory, err := GetReservedMemory(profile, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservedMemory).To(HaveLen(1))
+		Expect(reservedMemory[0].NUMANode).To(Equal(int32(0)))
+	})
+
+	It("should return the reserved memory of the profile", func() {
+		profile.Spec.Kubelet.MemoryManager.ReservedMemory = []performancev2.NUMAReservedMemory{
+			{NUMANode: 1, Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1624Mi")}},
+		}
+		Expect(IsReservedMemoryDerived(profile)).To(BeFalse())
+
+		reservedMemory, err := GetReservedMemory(profile, nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reservedMemory).To(Equal(profile.Spec.Kubelet.MemoryManager.ReservedMemory))
+	})
+})
@@ -0,0 +1,28 @@
+package profile
+
+import (
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+)
+
+// GetNodeOverrideProfile returns the copy of the profile with isolated CPUs of the node override
+func GetNodeOverrideProfile(profile *performancev2.PerformanceProfile, override *performancev2.CPUNodeOverride) *performancev2.PerformanceProfile {
+	overrideProfile := profile.DeepCopy()
+	overrideProfile.Spec.CPU.NodeOverrides = nil
+
+	isolated := *override.Isolated
+	overrideProfile.Spec.CPU.Isolated = &isolated
+	return overrideProfile
+}
+
+// GetNodeOverrideSelector returns labels of nodes that use the node override, the override applies only
+// to nodes selected by the profile
+func GetNodeOverrideSelector(profile *performancev2.PerformanceProfile, override *performancev2.CPUNodeOverride) map[string]string {
+	selector := map[string]string{}
+	for key, value := range profile.Spec.NodeSelector {
+		selector[key] = value
+	}
+	for key, value := range override.NodeSelector {
+		selector[key] = value
+	}
+	return selector
+}
@@ -0,0 +1,43 @@
+package profile
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+)
+
+var _ = Describe("CPU node overrides", func() {
+	var profile *performancev2.PerformanceProfile
+	var override performancev2.CPUNodeOverride
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+
+		isolatedCPUs := performancev2.CPUSet("4-5")
+		override = performancev2.CPUNodeOverride{
+			Name:         "small",
+			NodeSelector: map[string]string{"feature.node.kubernetes.io/cpu-cores": "6"},
+			Isolated:     &isolatedCPUs,
+		}
+	})
+
+	It("should return the profile with override CPUs", func() {
+		profile.Spec.CPU.NodeOverrides = []performancev2.CPUNodeOverride{override}
+
+		overrideProfile := GetNodeOverrideProfile(profile, &override)
+		Expect(*overrideProfile.Spec.CPU.Isolated).To(Equal(performancev2.CPUSet("4-5")))
+		Expect(*overrideProfile.Spec.CPU.Reserved).To(Equal(testutils.ReservedCPUs))
+		Expect(overrideProfile.Spec.CPU.NodeOverrides).To(BeEmpty())
+		Expect(profile.Spec.CPU.NodeOverrides).To(HaveLen(1))
+	})
+
+	It("should select only nodes of the profile", func() {
+		selector := GetNodeOverrideSelector(profile, &override)
+		Expect(selector).To(HaveKeyWithValue("feature.node.kubernetes.io/cpu-cores", "6"))
+		for key, value := range profile.Spec.NodeSelector {
+			Expect(selector).To(HaveKeyWithValue(key, value))
+		}
+	})
+})
@@ -0,0 +1,10 @@
+package profile
+
+import (
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+)
+
+// IsOverlay returns whether or not the profile is an overlay of another profile
+func IsOverlay(profile *performancev2.PerformanceProfile) bool {
+	return profile.Spec.Overlay != nil
+}
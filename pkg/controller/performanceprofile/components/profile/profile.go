@@ -47,3 +47,8 @@ func IsPaused(profile *performancev2.PerformanceProfile) bool {
 
 	return false
 }
+
+// IsStalldEnabled returns whether or not the stalld service runs on nodes of the profile
+func IsStalldEnabled(profile *performancev2.PerformanceProfile) bool {
+	return profile.Spec.Stalld == nil || profile.Spec.Stalld.Enabled == nil || *profile.Spec.Stalld.Enabled
+}
@@ -0,0 +1,105 @@
+package profile
+
+import (
+	"time"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+)
+
+// maintenanceWindowTimeFormat is the format of the maintenance window start time
+const maintenanceWindowTimeFormat = "15:04"
+
+// GetRolloutStrategy returns the rollout strategy of the profile, defaults to the immediate strategy
+func GetRolloutStrategy(profile *performancev2.PerformanceProfile) performancev2.RolloutStrategy {
+	if profile.Spec.Rollout == nil || profile.Spec.Rollout.Strategy == nil {
+		return performancev2.RolloutStrategyImmediate
+	}
+	return *profile.Spec.Rollout.Strategy
+}
+
+// IsChangeHeld returns whether or not the change with the specified impact should be held by the rollout policy
+func IsChangeHeld(profile *performancev2.PerformanceProfile, impact *performancev2.ChangeImpact) bool {
+	if GetRolloutStrategy(profile) == performancev2.RolloutStrategyImmediate {
+		return false
+	}
+
+	if impact.RebootRequired {
+		return true
+	}
+
+	return profile.Spec.Rollout.HoldAllChanges != nil && *profile.Spec.Rollout.HoldAllChanges
+}
+
+// IsRolloutApproved returns whether or not the change with the specified ID was approved
+func IsRolloutApproved(profile *performancev2.PerformanceProfile, changeID string) bool {
+	if profile.Annotations == nil {
+		return false
+	}
+
+	approvedID, ok := profile.Annotations[performancev2.PerformanceProfileApproveRolloutAnnotation]
+	return ok && approvedID == changeID
+}
+
+// IsInMaintenanceWindow returns whether or not the time is inside one of the profile maintenance windows
+func IsInMaintenanceWindow(profile *performancev2.PerformanceProfile, t time.Time) bool {
+	if profile.Spec.Rollout == nil {
+		return false
+	}
+
+	for _, window := range profile.Spec.Rollout.MaintenanceWindows {
+		for _, start := range getMaintenanceWindowStarts(window, t) {
+			if !t.Before(start) && t.Before(start.Add(window.Duration.Duration)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetNextMaintenanceWindow returns the start time of the next profile maintenance window after the time,
+// or nil when the profile does not have maintenance windows
+func GetNextMaintenanceWindow(profile *performancev2.PerformanceProfile, t time.Time) *time.Time {
+	if profile.Spec.Rollout == nil {
+		return nil
+	}
+
+	var next *time.Time
+	for _, window := range profile.Spec.Rollout.MaintenanceWindows {
+		for _, start := range getMaintenanceWindowStarts(window, t) {
+			if !start.After(t) {
+				continue
+			}
+
+			if next == nil || start.Before(*next) {
+				s := start
+				next = &s
+			}
+		}
+	}
+	return next
+}
+
+// getMaintenanceWindowStarts returns window start times during the week before and the week after the time
+func getMaintenanceWindowStarts(window performancev2.MaintenanceWindow, t time.Time) []time.Time {
+	startTime, err := time.Parse(maintenanceWindowTimeFormat, window.StartTime)
+	if err != nil {
+		// no error handling needed, it's validated already
+		return nil
+	}
+
+	days := map[string]bool{}
+	for _, day := range window.Days {
+		days[string(day)] = true
+	}
+
+	t = t.UTC()
+	var starts []time.Time
+	for offset := -7; offset <= 7; offset++ {
+		start := time.Date(t.Year(), t.Month(), t.Day()+offset, startTime.Hour(), startTime.Minute(), 0, 0, time.UTC)
+		if len(days) > 0 && !days[start.Weekday().String()] {
+			continue
+		}
+		starts = append(starts, start)
+	}
+	return starts
+}
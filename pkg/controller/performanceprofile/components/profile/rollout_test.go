@@ -0,0 +1,96 @@
+package profile
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
+)
+
+var _ = Describe("Rollout", func() {
+	var profile *performancev2.PerformanceProfile
+
+	BeforeEach(func() {
+		profile = testutils.NewPerformanceProfile("test")
+	})
+
+	Context("change holding", func() {
+		rebootImpact := &performancev2.ChangeImpact{Type: performancev2.ChangeImpactMachineConfigUpdate, RebootRequired: true}
+		reloadImpact := &performancev2.ChangeImpact{Type: performancev2.ChangeImpactTunedReload}
+
+		It("should not hold changes without the rollout policy", func() {
+			Expect(GetRolloutStrategy(profile)).To(Equal(performancev2.RolloutStrategyImmediate))
+			Expect(IsChangeHeld(profile, rebootImpact)).To(BeFalse())
+		})
+
+		It("should hold only changes that reboot nodes by default", func() {
+			strategy := performancev2.RolloutStrategyApproval
+			profile.Spec.Rollout = &performancev2.Rollout{Strategy: &strategy}
+			Expect(IsChangeHeld(profile, rebootImpact)).To(BeTrue())
+			Expect(IsChangeHeld(profile, reloadImpact)).To(BeFalse())
+
+			profile.Spec.Rollout.HoldAllChanges = pointer.BoolPtr(true)
+			Expect(IsChangeHeld(profile, reloadImpact)).To(BeTrue())
+		})
+
+		It("should approve only the change with the annotated ID", func() {
+			Expect(IsRolloutApproved(profile, "1234")).To(BeFalse())
+
+			profile.Annotations = map[string]string{performancev2.PerformanceProfileApproveRolloutAnnotation: "1234"}
+			Expect(IsRolloutApproved(profile, "1234")).To(BeTrue())
+			Expect(IsRolloutApproved(profile, "5678")).To(BeFalse())
+		})
+	})
+
+	Context("maintenance windows", func() {
+		// 2021-06-07 is Monday
+		monday := time.Date(2021, time.June, 7, 12, 0, 0, 0, time.UTC)
+
+		BeforeEach(func() {
+			strategy := performancev2.RolloutStrategyMaintenanceWindow
+			profile.Spec.Rollout = &performancev2.Rollout{
+				Strategy: &strategy,
+				MaintenanceWindows: []performancev2.MaintenanceWindow{
+					{
+						Days:      []performancev2.Weekday{"Saturday", "Sunday"},
+						StartTime: "22:00",
+						Duration:  metav1.Duration{Duration: 4 * time.Hour},
+					},
+				},
+			}
+		})
+
+		It("should detect the time inside the maintenance window", func() {
+			Expect(IsInMaintenanceWindow(profile, monday)).To(BeFalse())
+			Expect(IsInMaintenanceWindow(profile, time.Date(2021, time.June, 12, 23, 0, 0, 0, time.UTC))).To(BeTrue())
+			// the window started on Sunday and ends on Monday
+			Expect(IsInMaintenanceWindow(profile, time.Date(2021, time.June, 7, 1, 59, 0, 0, time.UTC))).To(BeTrue())
+			Expect(IsInMaintenanceWindow(profile, time.Date(2021, time.June, 7, 2, 0, 0, 0, time.UTC))).To(BeFalse())
+		})
+
+		It("should return the start of the next maintenance window", func() {
+			next := GetNextMaintenanceWindow(profile, monday)
+			Expect(next).ToNot(BeNil())
+			Expect(*next).To(Equal(time.Date(2021, time.June, 12, 22, 0, 0, 0, time.UTC)))
+		})
+
+		It("should start the window every day when days are not specified", func() {
+			profile.Spec.Rollout.MaintenanceWindows[0].Days = nil
+			next := GetNextMaintenanceWindow(profile, monday)
+			Expect(next).ToNot(BeNil())
+			Expect(*next).To(Equal(time.Date(2021, time.June, 7, 22, 0, 0, 0, time.UTC)))
+		})
+
+		It("should not return the next window without maintenance windows", func() {
+			profile.Spec.Rollout = nil
+			Expect(GetNextMaintenanceWindow(profile, monday)).To(BeNil())
+			Expect(IsInMaintenanceWindow(profile, monday)).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,40 @@
+package profile
+
+import (
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+)
+
+// IsTunedProfilesReferenced returns whether or not user tuned profiles of the profile are read from ConfigMaps
+func IsTunedProfilesReferenced(profile *performancev2.PerformanceProfile) bool {
+	for _, fragment := range profile.Spec.TunedProfiles {
+		if fragment.ConfigMapRef != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTunedProfilesConfigMap returns whether or not the profile reads user tuned profiles from the ConfigMap
+func IsTunedProfilesConfigMap(profile *performancev2.PerformanceProfile, namespace string, name string) bool {
+	for _, fragment := range profile.Spec.TunedProfiles {
+		if fragment.ConfigMapRef != nil && fragment.ConfigMapRef.Namespace == namespace && fragment.ConfigMapRef.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetTunedProfilesData replaces ConfigMap references of user tuned profiles with the data read from ConfigMaps,
+// the data is keyed by the name of the user tuned profile
+func SetTunedProfilesData(profile *performancev2.PerformanceProfile, data map[string]string) {
+	for i := range profile.Spec.TunedProfiles {
+		fragment := &profile.Spec.TunedProfiles[i]
+		fragmentData, ok := data[fragment.Name]
+		if !ok {
+			continue
+		}
+
+		fragment.Data = &fragmentData
+		fragment.ConfigMapRef = nil
+	}
+}
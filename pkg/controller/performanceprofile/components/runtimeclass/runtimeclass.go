@@ -1,27 +1,52 @@
 package runtimeclass
 
 import (
+	"strings"
+
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
-	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 
 	nodev1beta1 "k8s.io/api/node/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+// AllowedAnnotationsAnnotation lists pod annotations allowed under the runtime class handler,
+// so users can discover capabilities of the runtime class
+const AllowedAnnotationsAnnotation = "performance.openshift.io/allowed-annotations"
+
 // New returns a new RuntimeClass object
-func New(profile *performancev2.PerformanceProfile, handler string) *nodev1beta1.RuntimeClass {
-	name := components.GetComponentName(profile.Name, components.ComponentNamePrefix)
-	return &nodev1beta1.RuntimeClass{
+func New(profile *performancev2.PerformanceProfile, allowedAnnotations []string) *nodev1beta1.RuntimeClass {
+	runtimeClass := &nodev1beta1.RuntimeClass{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "RuntimeClass",
 			APIVersion: "node.k8s.io/v1beta1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: name,
+			Name: performancev2.GetRuntimeClassName(profile),
 		},
-		Handler: handler,
+		Handler: performancev2.GetRuntimeClassHandler(&profile.Spec),
 		Scheduling: &nodev1beta1.Scheduling{
 			NodeSelector: profile.Spec.NodeSelector,
 		},
 	}
+
+	if len(allowedAnnotations) != 0 {
+		runtimeClass.Annotations = map[string]string{
+			AllowedAnnotationsAnnotation: strings.Join(allowedAnnotations, ","),
+		}
+	}
+
+	if profile.Spec.RuntimeClass == nil {
+		return runtimeClass
+	}
+
+	if len(profile.Spec.RuntimeClass.Overhead) != 0 {
+		runtimeClass.Overhead = &nodev1beta1.Overhead{
+			PodFixed: profile.Spec.RuntimeClass.Overhead.DeepCopy(),
+		}
+	}
+
+	for _, toleration := range profile.Spec.RuntimeClass.Tolerations {
+		runtimeClass.Scheduling.Tolerations = append(runtimeClass.Scheduling.Tolerations, *toleration.DeepCopy())
+	}
+	return runtimeClass
 }
@@ -4,19 +4,20 @@ import (
 	"bytes"
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
-	"k8s.io/utils/pointer"
-
 	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
+	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/machineconfig"
 	componentsprofile "github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components/profile"
 	tunedv1 "github.com/openshift/cluster-node-tuning-operator/pkg/apis/tuned/v1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	cpuset "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+	"k8s.io/utils/pointer"
 )
 
 const (
@@ -28,9 +29,75 @@ const (
 	templateAdditionalArgs                  = "AdditionalArgs"
 	templateGloballyDisableIrqLoadBalancing = "GloballyDisableIrqLoadBalancing"
 	templateNetDevices                      = "NetDevices"
+	templateSysfs                           = "Sysfs"
+	templatePstateArgs                      = "PstateArgs"
+	templateRealtimeArgs                    = "RealtimeArgs"
+	templateCpuPowerPolicy                  = "CpuPowerPolicy"
+	templatePowerArgs                       = "PowerArgs"
+	templateStalldService                   = "StalldService"
+	templateSchedulerGroups                 = "SchedulerGroups"
+	templateRcuArgs                         = "RcuArgs"
 	nfConntrackHashsize                     = "nf_conntrack_hashsize=131072"
+	sectionMain                             = "main"
+	optionInclude                           = "include"
+	sectionSysctl                           = "sysctl"
+	sectionSysfs                            = "sysfs"
+	nodeOverridePriority                    = 10
+	// defaultIsolatedMaxCState is the deepest cpuidle state of isolated CPUs when the power policy does not specify it
+	defaultIsolatedMaxCState = 1
 )
 
+// vendorKernelArgs contains kernel arguments specific to the CPU vendor
+type vendorKernelArgs struct {
+	// pstate disables the vendor specific CPU frequency scaling driver
+	pstate string
+	// realtime contains the timer and the IOMMU passthrough arguments
+	realtime string
+	// lowLatency disables the watchdog, the audit and the machine check exceptions for the low-latency power mode
+	lowLatency string
+	// ultraLowLatency keeps CPUs in the C0 state for the ultra-low-latency power mode
+	ultraLowLatency string
+}
+
+var vendorsKernelArgs = map[performancev2.CPUVendor]vendorKernelArgs{
+	performancev2.CPUVendorIntel: {
+		pstate:          "intel_pstate=disable",
+		realtime:        "tsc=nowatchdog intel_iommu=on iommu=pt",
+		lowLatency:      "nmi_watchdog=0 audit=0 mce=off",
+		ultraLowLatency: "processor.max_cstate=1 intel_idle.max_cstate=0 idle=poll",
+	},
+	// the AMD IOMMU is enabled by default, amd_iommu does not have the "on" value
+	performancev2.CPUVendorAMD: {
+		pstate:          "amd_pstate=disable",
+		realtime:        "tsc=nowatchdog iommu=pt",
+		lowLatency:      "nmi_watchdog=0 audit=0 mce=off",
+		ultraLowLatency: "processor.max_cstate=1 idle=poll",
+	},
+	// arm64 does not have the TSC clocksource and CPU frequency scaling is controlled by the governor
+	performancev2.CPUVendorARM: {
+		realtime:        "iommu.passthrough=1",
+		lowLatency:      "nmi_watchdog=0 audit=0",
+		ultraLowLatency: "cpuidle.off=1",
+	},
+}
+
+// schedulingPolicies maps scheduling policies to the policy letters of the tuned scheduler plugin
+var schedulingPolicies = map[performancev2.SchedulingPolicy]string{
+	performancev2.SchedulingPolicyFIFO:       "f",
+	performancev2.SchedulingPolicyRoundRobin: "r",
+	performancev2.SchedulingPolicyOther:      "o",
+	performancev2.SchedulingPolicyBatch:      "b",
+	performancev2.SchedulingPolicyIdle:       "i",
+}
+
+// kernelThreadsAffinities maps affinities of kernel threads to CPU masks of the tuned scheduler plugin,
+// masks of isolated and not isolated CPUs are defined by the cpu-partitioning profile
+var kernelThreadsAffinities = map[performancev2.KernelThreadsAffinity]string{
+	performancev2.KernelThreadsAffinityAll:      "*",
+	performancev2.KernelThreadsAffinityReserved: "${not_isolated_cpumask}",
+	performancev2.KernelThreadsAffinityIsolated: "${isolated_cpumask}",
+}
+
 func new(name string, profiles []tunedv1.TunedProfile, recommends []tunedv1.TunedRecommend) *tunedv1.Tuned {
 	return &tunedv1.Tuned{
 		TypeMeta: metav1.TypeMeta{
@@ -50,10 +117,188 @@ func new(name string, profiles []tunedv1.TunedProfile, recommends []tunedv1.Tune
 
 // NewNodePerformance returns tuned profile for performance sensitive workflows
 func NewNodePerformance(assetsDir string, profile *performancev2.PerformanceProfile) (*tunedv1.Tuned, error) {
+	profileData, err := getNodePerformanceProfileData(assetsDir, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	name := components.GetComponentName(profile.Name, components.ProfileNamePerformance)
+	profiles := []tunedv1.TunedProfile{
+		{
+			Name: &name,
+			Data: &profileData,
+		},
+	}
+
+	profiles, recommended, err := appendChildProfiles(profiles, name, profile.Spec.TunedProfiles)
+	if err != nil {
+		return nil, err
+	}
+
+	priority := uint64(20)
+	recommends := []tunedv1.TunedRecommend{
+		{
+			Profile:             &recommended,
+			Priority:            &priority,
+			MachineConfigLabels: componentsprofile.GetMachineConfigLabel(profile),
+		},
+	}
+
+	// nodes with a different CPU layout get node specific profiles, matched by labels of the override and
+	// of the profile with a higher priority than the profile for the whole pool, the tuned operator applies
+	// the bootloader section only for the profile recommended by machine config labels, so the kernel
+	// arguments of all nodes of the pool come from the profile for the whole pool
+	var nodeOverrides []performancev2.CPUNodeOverride
+	if profile.Spec.CPU != nil {
+		nodeOverrides = profile.Spec.CPU.NodeOverrides
+	}
+	for i := range nodeOverrides {
+		override := &nodeOverrides[i]
+		overrideData, err := getNodePerformanceProfileData(assetsDir, componentsprofile.GetNodeOverrideProfile(profile, override))
+		if err != nil {
+			return nil, err
+		}
+
+		overrideName := fmt.Sprintf("%s-%s", name, override.Name)
+		profiles = append(profiles, tunedv1.TunedProfile{
+			Name: &overrideName,
+			Data: &overrideData,
+		})
+
+		// user profiles are chained from the node specific profile as well
+		var overrideRecommended string
+		profiles, overrideRecommended, err = appendChildProfiles(profiles, overrideName, profile.Spec.TunedProfiles)
+		if err != nil {
+			return nil, err
+		}
+
+		overridePriority := uint64(nodeOverridePriority + i)
+		recommends = append(recommends, tunedv1.TunedRecommend{
+			Profile:  &overrideRecommended,
+			Priority: &overridePriority,
+			Match:    getNodeLabelsMatch(componentsprofile.GetNodeOverrideSelector(profile, override)),
+		})
+	}
+	return new(name, profiles, recommends), nil
+}
+
+// appendChildProfiles appends user tuned profiles as child profiles of the parent profile, each child profile includes
+// the previous one, and returns the name of the last child profile that should be recommended instead of the parent
+func appendChildProfiles(profiles []tunedv1.TunedProfile, parent string, fragments []performancev2.TunedProfileFragment) ([]tunedv1.TunedProfile, string, error) {
+	included := parent
+	for i := range fragments {
+		fragment := &fragments[i]
+
+		// the controller resolves ConfigMap references into the profile data
+		if fragment.Data == nil {
+			return nil, "", fmt.Errorf("the tuned profile %q does not have data", fragment.Name)
+		}
+
+		if err := performancev2.ValidateTunedProfileData(*fragment.Data); err != nil {
+			return nil, "", fmt.Errorf("invalid tuned profile %q: %v", fragment.Name, err)
+		}
+
+		name := fmt.Sprintf("%s-%s", parent, fragment.Name)
+		data := getChildProfileData(*fragment.Data, included)
+		profiles = append(profiles, tunedv1.TunedProfile{
+			Name: &name,
+			Data: &data,
+		})
+		included = name
+	}
+	return profiles, included, nil
+}
+
+// getChildProfileData returns the profile data with the include option of the parent profile
+// added to the [main] section
+func getChildProfileData(data string, parent string) string {
+	include := fmt.Sprintf("%s=%s", optionInclude, parent)
 
+	lines := strings.Split(data, "\n")
+	for i, line := range lines {
+		if name, ok := getSectionName(line); !ok || name != sectionMain {
+			continue
+		}
+
+		var result []string
+		result = append(result, lines[:i+1]...)
+		result = append(result, include)
+		result = append(result, lines[i+1:]...)
+		return strings.Join(result, "\n")
+	}
+
+	return fmt.Sprintf("[%s]\n%s\n\n%s", sectionMain, include, data)
+}
+
+// getNodeLabelsMatch returns the tuned match rule for nodes with all labels
+func getNodeLabelsMatch(labels map[string]string) []tunedv1.TunedMatch {
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// nested match rules are connected by the logical AND operator
+	var match []tunedv1.TunedMatch
+	for i := len(keys) - 1; i >= 0; i-- {
+		label := keys[i]
+		value := labels[label]
+		match = []tunedv1.TunedMatch{
+			{
+				Label: &label,
+				Value: &value,
+				Match: match,
+			},
+		}
+	}
+	return match
+}
+
+func getNodePerformanceProfileData(assetsDir string, profile *performancev2.PerformanceProfile) (string, error) {
 	templateArgs := make(map[string]string)
 
-	if profile.Spec.CPU.Isolated != nil {
+	vendor := performancev2.GetCPUVendor(profile)
+	vendorArgs, ok := vendorsKernelArgs[vendor]
+	if !ok {
+		return "", fmt.Errorf("unsupported CPU vendor %q", vendor)
+	}
+	templateArgs[templatePstateArgs] = vendorArgs.pstate
+	templateArgs[templateRealtimeArgs] = vendorArgs.realtime
+
+	templateArgs[templateStalldService] = "start,enable"
+	if !componentsprofile.IsStalldEnabled(profile) {
+		templateArgs[templateStalldService] = "stop,disable"
+	}
+
+	templateArgs[templateSchedulerGroups] = getSchedulerGroups(profile)
+
+	switch performancev2.GetRCUOffloadMode(&profile.Spec) {
+	case performancev2.RCUOffloadNoCBs:
+		templateArgs[templateRcuArgs] = "rcu_nocbs=${isolated_cores}"
+	case performancev2.RCUOffloadNoCBsPoll:
+		templateArgs[templateRcuArgs] = "rcu_nocbs=${isolated_cores} rcu_nocb_poll"
+	}
+
+	var sysfs []string
+	if profile.Spec.PowerPolicy != nil {
+		cpuPowerPolicy, cstates, err := getCPUPowerPolicy(profile)
+		if err != nil {
+			return "", err
+		}
+		templateArgs[templateCpuPowerPolicy] = cpuPowerPolicy
+		sysfs = append(sysfs, cstates...)
+
+		if mode := profile.Spec.PowerPolicy.Mode; mode != nil {
+			switch *mode {
+			case performancev2.PowerModeLowLatency:
+				templateArgs[templatePowerArgs] = vendorArgs.lowLatency
+			case performancev2.PowerModeUltraLowLatency:
+				templateArgs[templatePowerArgs] = strings.Join([]string{vendorArgs.lowLatency, vendorArgs.ultraLowLatency}, cmdlineDelimiter)
+			}
+		}
+	}
+
+	if profile.Spec.CPU != nil && profile.Spec.CPU.Isolated != nil {
 		templateArgs[templateIsolatedCpus] = string(*profile.Spec.CPU.Isolated)
 		if profile.Spec.CPU.BalanceIsolated != nil && *profile.Spec.CPU.BalanceIsolated == false {
 			templateArgs[templateStaticIsolation] = strconv.FormatBool(true)
@@ -61,47 +306,74 @@ func NewNodePerformance(assetsDir string, profile *performancev2.PerformanceProf
 	}
 
 	if profile.Spec.HugePages != nil {
+		hugePagesArch, err := performancev2.GetHugePagesArchitecture(profile)
+		if err != nil {
+			return "", err
+		}
+
 		var defaultHugepageSize performancev2.HugePageSize
 		if profile.Spec.HugePages.DefaultHugePagesSize != nil {
 			defaultHugepageSize = *profile.Spec.HugePages.DefaultHugePagesSize
 			templateArgs[templateDefaultHugepagesSize] = string(defaultHugepageSize)
 		}
 
-		var is2MHugepagesRequested *bool
 		var hugepages []string
+		var numaHugepagesSizes []performancev2.HugePageSize
+		bootHugepagesSizes := map[performancev2.HugePageSize]bool{}
 		for _, page := range profile.Spec.HugePages.Pages {
+			if !hugePagesArch.IsSupportedSize(page.Size) {
+				return "", fmt.Errorf("the huge page size %q is not supported on the %s", page.Size, hugePagesArch)
+			}
+
 			// we can not allocate huge pages on the specific NUMA node via kernel boot arguments
 			if page.Node != nil {
-				// a user requested to allocate 2M huge pages on the specific NUMA node,
-				// append dummy kernel arguments
-				if page.Size == components.HugepagesSize2M && is2MHugepagesRequested == nil {
-					is2MHugepagesRequested = pointer.BoolPtr(true)
+				if !performancev2.ContainsHugePageSize(numaHugepagesSizes, page.Size) {
+					numaHugepagesSizes = append(numaHugepagesSizes, page.Size)
 				}
 				continue
 			}
 
-			// a user requested to allocated 2M huge pages without specifying the node
-			// we need to append 2M hugepages kernel arguments anyway, no need to add dummy
-			// kernel arguments
-			if page.Size == components.HugepagesSize2M {
-				is2MHugepagesRequested = pointer.BoolPtr(false)
-			}
-
+			bootHugepagesSizes[page.Size] = true
 			hugepages = append(hugepages, fmt.Sprintf("hugepagesz=%s", string(page.Size)))
 			hugepages = append(hugepages, fmt.Sprintf("hugepages=%d", page.Count))
 		}
 
-		// append dummy 2M huge pages kernel arguments to guarantee that the kernel will create 2M related files
-		// and directories under the filesystem
-		if is2MHugepagesRequested != nil && *is2MHugepagesRequested {
-			if defaultHugepageSize == components.HugepagesSize1G {
-				hugepages = append(hugepages, fmt.Sprintf("hugepagesz=%s", components.HugepagesSize2M))
-				hugepages = append(hugepages, fmt.Sprintf("hugepages=%d", 0))
+		// a user requested to allocate huge pages on the specific NUMA node, append dummy huge pages kernel arguments
+		// to guarantee that the kernel will create related files and directories under the filesystem, for example
+		// 2M huge pages are not registered on amd64 nodes with the 1G default huge pages size
+		for _, size := range numaHugepagesSizes {
+			if bootHugepagesSizes[size] || hugePagesArch.IsRegisteredSize(size, defaultHugepageSize) {
+				continue
 			}
+
+			hugepages = append(hugepages, fmt.Sprintf("hugepagesz=%s", size))
+			hugepages = append(hugepages, fmt.Sprintf("hugepages=%d", 0))
 		}
 
 		hugepagesArgs := strings.Join(hugepages, cmdlineDelimiter)
 		templateArgs[templateHugepages] = hugepagesArgs
+
+		// huge pages allocated at runtime are written to the sysfs of NUMA nodes, so changes do not require a reboot
+		for _, page := range componentsprofile.GetRuntimeHugePages(profile) {
+			size, err := machineconfig.GetHugepagesSizeKilobytes(page.Size)
+			if err != nil {
+				return "", err
+			}
+			sysfs = append(sysfs, fmt.Sprintf("/sys/devices/system/node/node%d/hugepages/hugepages-%skB/nr_hugepages=%d", *page.Node, size, page.Count))
+		}
+	}
+
+	if profile.Spec.Tuning != nil {
+		// sysctl and sysfs settings owned by the operator are checked again, because profiles rendered from
+		// files or created before the webhook was deployed were never admitted
+		if conflicts := performancev2.GetTuningConflicts(&profile.Spec); len(conflicts) != 0 {
+			return "", fmt.Errorf("%s", strings.Join(conflicts, ", "))
+		}
+		sysfs = overrideOptions(sysfs, profile.Spec.Tuning.Sysfs)
+	}
+
+	if len(sysfs) != 0 {
+		templateArgs[templateSysfs] = strings.Join(sysfs, "\n")
 	}
 
 	if profile.Spec.AdditionalKernelArgs != nil {
@@ -115,11 +387,11 @@ func NewNodePerformance(assetsDir string, profile *performancev2.PerformanceProf
 
 	//set default [net] field first, override if needed.
 	templateArgs[templateNetDevices] = fmt.Sprintf("[net]\n%s", nfConntrackHashsize)
-	if profile.Spec.Net != nil && *profile.Spec.Net.UserLevelNetworking && profile.Spec.CPU.Reserved != nil {
+	if profile.Spec.Net != nil && *profile.Spec.Net.UserLevelNetworking && profile.Spec.CPU != nil && profile.Spec.CPU.Reserved != nil {
 
 		reservedSet, err := cpuset.Parse(string(*profile.Spec.CPU.Reserved))
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		reserveCPUcount := reservedSet.Size()
 
@@ -169,26 +441,370 @@ func NewNodePerformance(assetsDir string, profile *performancev2.PerformanceProf
 
 	profileData, err := getProfileData(getProfilePath(components.ProfileNamePerformance, assetsDir), templateArgs)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 
-	name := components.GetComponentName(profile.Name, components.ProfileNamePerformance)
-	profiles := []tunedv1.TunedProfile{
-		{
-			Name: &name,
-			Data: &profileData,
-		},
+	// sysctl options of the profile are static, so overrides are applied to the rendered section
+	if profile.Spec.Tuning != nil && len(profile.Spec.Tuning.Sysctl) != 0 {
+		profileData = overrideSectionOptions(profileData, sectionSysctl, profile.Spec.Tuning.Sysctl)
 	}
+	return profileData, nil
+}
 
-	priority := uint64(20)
-	recommends := []tunedv1.TunedRecommend{
-		{
-			Profile:             &name,
-			Priority:            &priority,
-			MachineConfigLabels: componentsprofile.GetMachineConfigLabel(profile),
-		},
+// getCPUPowerPolicy returns options of the [cpu] section for reserved CPUs followed by the instance of the cpu plugin
+// for isolated CPUs, and sysfs settings that disable cpuidle states deeper than the maximum C-state of each CPU
+func getCPUPowerPolicy(profile *performancev2.PerformanceProfile) (string, []string, error) {
+	reserved := getCPUPowerSettings(profile.Spec.PowerPolicy.Reserved)
+	isolated := getCPUPowerSettings(profile.Spec.PowerPolicy.Isolated)
+
+	// the global latency is not forced anymore, so isolated CPUs keep the C1 limit of profiles without
+	// the power policy unless the profile specifies another one, the ultra-low-latency mode disables
+	// C-states with kernel arguments
+	mode := profile.Spec.PowerPolicy.Mode
+	if isolated.MaxCState == nil && (mode == nil || *mode != performancev2.PowerModeUltraLowLatency) {
+		isolated.MaxCState = pointer.Int32Ptr(defaultIsolatedMaxCState)
 	}
-	return new(name, profiles, recommends), nil
+
+	// devices of the default instance are limited to reserved CPUs, otherwise it would claim isolated CPUs as well,
+	// the global latency is not forced, so C-states are limited per CPU
+	options := []string{
+		"devices=${f:cpulist2devs:${not_isolated_cores_expanded}}",
+		"force_latency=",
+		fmt.Sprintf("governor=%s", *reserved.Governor),
+		fmt.Sprintf("energy_perf_bias=%s", getEnergyPerfBias(*reserved.Governor)),
+		"",
+		"[cpu_isolated]",
+		"type=cpu",
+		"devices=${f:cpulist2devs:${isolated_cores}}",
+		fmt.Sprintf("governor=%s", *isolated.Governor),
+		fmt.Sprintf("energy_perf_bias=%s", getEnergyPerfBias(*isolated.Governor)),
+	}
+
+	var reservedSet, isolatedSet *performancev2.CPUSet
+	if profile.Spec.CPU != nil {
+		reservedSet, isolatedSet = profile.Spec.CPU.Reserved, profile.Spec.CPU.Isolated
+	}
+
+	var cstates []string
+	for _, cpus := range []struct {
+		set      *performancev2.CPUSet
+		settings *performancev2.CPUPowerSettings
+	}{
+		{set: reservedSet, settings: reserved},
+		{set: isolatedSet, settings: isolated},
+	} {
+		if cpus.set == nil || cpus.settings.MaxCState == nil {
+			continue
+		}
+
+		set, err := cpuset.Parse(string(*cpus.set))
+		if err != nil {
+			return "", nil, err
+		}
+
+		// the maximum C-state is the index of the deepest cpuidle state, the state0 is the polling or the C0 state,
+		// the index matches the C-state for the acpi_idle driver, but the intel_idle driver lists additional states
+		// like C1E, so the state names of the intel_idle driver do not match indexes
+		for _, glob := range getCPUListGlobs(set) {
+			cstates = append(cstates, fmt.Sprintf("/sys/devices/system/cpu/cpu%s/cpuidle/state[%d-9]/disable=1", glob, *cpus.settings.MaxCState+1))
+		}
+	}
+	return strings.Join(options, "\n"), cstates, nil
+}
+
+// getCPUListGlobs returns glob patterns that match the decimal IDs of the CPU set, each contiguous range
+// of CPUs is split into blocks of IDs with the same number of digits and the same leading digits,
+// for example the range 4-27 is matched by the patterns [4-9], 1[0-9] and 2[0-7]
+func getCPUListGlobs(set cpuset.CPUSet) []string {
+	var globs []string
+
+	cpus := set.ToSlice()
+	for i := 0; i < len(cpus); {
+		// the contiguous range of CPUs that starts with the CPU i
+		j := i
+		for j+1 < len(cpus) && cpus[j+1] == cpus[j]+1 {
+			j++
+		}
+
+		start, end := cpus[i], cpus[j]
+		for start <= end {
+			// IDs with more digits can not be matched by the same pattern, CPU IDs do not have leading zeros
+			lowest := 1
+			for lowest*10 <= start {
+				lowest *= 10
+			}
+			last := lowest*10 - 1
+			if last > end {
+				last = end
+			}
+
+			// the largest block of IDs aligned to a power of ten that fits into the range
+			size := 1
+			for size*10 <= lowest && start%(size*10) == 0 && start+size*10-1 <= last {
+				size *= 10
+			}
+
+			digit := start / size % 10
+			count := (last - start + 1) / size
+			if count > 10-digit {
+				count = 10 - digit
+			}
+
+			glob := ""
+			if prefix := start / size / 10; prefix != 0 {
+				glob = strconv.Itoa(prefix)
+			}
+			if count == 1 {
+				glob += strconv.Itoa(digit)
+			} else {
+				glob += fmt.Sprintf("[%d-%d]", digit, digit+count-1)
+			}
+			glob += strings.Repeat("[0-9]", len(strconv.Itoa(size))-1)
+
+			globs = append(globs, glob)
+			start += count * size
+		}
+		i = j + 1
+	}
+	return globs
+}
+
+// getCPUPowerSettings returns power settings with defaults applied
+func getCPUPowerSettings(settings *performancev2.CPUPowerSettings) *performancev2.CPUPowerSettings {
+	result := &performancev2.CPUPowerSettings{}
+	if settings != nil {
+		result = settings.DeepCopy()
+	}
+
+	if result.Governor == nil {
+		governor := performancev2.CPUGovernorPerformance
+		result.Governor = &governor
+	}
+	return result
+}
+
+func getEnergyPerfBias(governor performancev2.CPUGovernor) string {
+	if governor == performancev2.CPUGovernorPerformance {
+		return "performance"
+	}
+	return "normal"
+}
+
+// getSchedulerGroups returns options of the tuned scheduler plugin for groups of kernel threads,
+// groups of the profile override default groups with the same name
+func getSchedulerGroups(profile *performancev2.PerformanceProfile) string {
+	var groups []performancev2.KernelThreadsGroup
+	for _, name := range performancev2.DefaultKernelThreadsGroups {
+		groups = append(groups, performancev2.KernelThreadsGroup{Name: name})
+	}
+
+	if profile.Spec.KernelThreads != nil {
+		for _, group := range profile.Spec.KernelThreads.Groups {
+			overridden := false
+			for i := range groups {
+				if groups[i].Name == group.Name {
+					groups[i] = group
+					overridden = true
+				}
+			}
+
+			if !overridden {
+				groups = append(groups, group)
+			}
+		}
+	}
+
+	var options []string
+	for i := range groups {
+		affinity := performancev2.KernelThreadsAffinityAll
+		if groups[i].Affinity != nil {
+			affinity = *groups[i].Affinity
+		}
+
+		// group.<name>=<rule priority>:<policy>:<priority>:<affinity>:<regex>
+		options = append(options, fmt.Sprintf("group.%s=0:%s:%d:%s:%s",
+			groups[i].Name,
+			schedulingPolicies[performancev2.GetKernelThreadsGroupPolicy(&groups[i])],
+			performancev2.GetKernelThreadsGroupPriority(&groups[i]),
+			kernelThreadsAffinities[affinity],
+			performancev2.GetKernelThreadsGroupRegex(&groups[i]),
+		))
+	}
+	return strings.Join(options, "\n")
+}
+
+// GetTuningStatus returns sysctl and sysfs options of the tuned profile recommended for the whole pool,
+// options of the recommended profile override options of profiles it includes
+func GetTuningStatus(tuned *tunedv1.Tuned) *performancev2.TuningStatus {
+	status := &performancev2.TuningStatus{}
+
+	profiles := map[string]string{}
+	for _, profile := range tuned.Spec.Profile {
+		if profile.Name != nil && profile.Data != nil {
+			profiles[*profile.Name] = *profile.Data
+		}
+	}
+
+	var recommended string
+	for _, recommend := range tuned.Spec.Recommend {
+		if recommend.Profile != nil && recommend.MachineConfigLabels != nil {
+			recommended = *recommend.Profile
+			break
+		}
+	}
+
+	// the chain of included profiles starts with the generated tuned profile and ends with the recommended one,
+	// profiles that do not belong to the Tuned, like the base openshift-node-performance profile, are skipped
+	var chain []string
+	visited := map[string]bool{}
+	for name := recommended; name != ""; name = getIncludedProfile(profiles[name]) {
+		if _, ok := profiles[name]; !ok || visited[name] {
+			break
+		}
+		visited[name] = true
+		chain = append([]string{name}, chain...)
+	}
+
+	for _, name := range chain {
+		section := ""
+		for _, line := range strings.Split(profiles[name], "\n") {
+			if sectionName, ok := getSectionName(line); ok {
+				section = sectionName
+				continue
+			}
+
+			option, value, ok := getOption(line)
+			if !ok {
+				continue
+			}
+
+			switch section {
+			case sectionSysctl:
+				if status.Sysctl == nil {
+					status.Sysctl = map[string]string{}
+				}
+				status.Sysctl[option] = value
+			case sectionSysfs:
+				if status.Sysfs == nil {
+					status.Sysfs = map[string]string{}
+				}
+				status.Sysfs[option] = value
+			}
+		}
+	}
+	return status
+}
+
+// getIncludedProfile returns the name of the profile included by the [main] section of the profile data
+func getIncludedProfile(data string) string {
+	section := ""
+	for _, line := range strings.Split(data, "\n") {
+		if name, ok := getSectionName(line); ok {
+			section = name
+			continue
+		}
+
+		if option, value, ok := getOption(line); ok && section == sectionMain && option == optionInclude {
+			return value
+		}
+	}
+	return ""
+}
+
+// overrideSectionOptions applies overrides to options of the section of the profile data,
+// the section is appended when the profile data does not contain it
+func overrideSectionOptions(data string, section string, overrides map[string]string) string {
+	lines := strings.Split(data, "\n")
+
+	start, end := -1, len(lines)
+	for i, line := range lines {
+		name, ok := getSectionName(line)
+		if !ok {
+			continue
+		}
+
+		if start >= 0 {
+			end = i
+			break
+		}
+
+		if name == section {
+			start = i + 1
+		}
+	}
+
+	if start < 0 {
+		lines = append(lines, fmt.Sprintf("[%s]", section))
+		start, end = len(lines), len(lines)
+	}
+
+	var result []string
+	result = append(result, lines[:start]...)
+	result = append(result, overrideOptions(lines[start:end], overrides)...)
+	result = append(result, lines[end:]...)
+	return strings.Join(result, "\n")
+}
+
+// overrideOptions replaces options with overrides of the same name, other overrides are sorted by the name
+// and inserted after the last option, so comments and empty lines that follow options stay in place
+func overrideOptions(lines []string, overrides map[string]string) []string {
+	if len(overrides) == 0 {
+		return lines
+	}
+
+	var result []string
+	overridden := map[string]bool{}
+	lastOption := -1
+	for _, line := range lines {
+		name, _, ok := getOption(line)
+		if ok {
+			if value, found := overrides[name]; found {
+				line = fmt.Sprintf("%s=%s", name, value)
+				overridden[name] = true
+			}
+			lastOption = len(result)
+		}
+		result = append(result, line)
+	}
+
+	var added []string
+	for name, value := range overrides {
+		if !overridden[name] {
+			added = append(added, fmt.Sprintf("%s=%s", name, value))
+		}
+	}
+	sort.Strings(added)
+
+	added = append(added, result[lastOption+1:]...)
+	return append(result[:lastOption+1], added...)
+}
+
+// getSectionName returns the name of the section started by the line
+func getSectionName(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+		return "", false
+	}
+	return strings.TrimSpace(line[1 : len(line)-1]), true
+}
+
+// getOption returns the name and the value of the option defined by the line without the inline comment
+func getOption(line string) (string, string, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false
+	}
+
+	i := strings.Index(line, "=")
+	if i <= 0 {
+		return "", "", false
+	}
+
+	value := line[i+1:]
+	if j := strings.Index(value, "#"); j >= 0 {
+		value = value[:j]
+	}
+	return strings.TrimSpace(line[:i]), strings.TrimSpace(value), true
 }
 
 func getProfilePath(name string, assetsDir string) string {
@@ -2,6 +2,8 @@ package tuned
 
 import (
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -13,6 +15,7 @@ import (
 	"github.com/openshift-kni/performance-addon-operators/pkg/controller/performanceprofile/components"
 	testutils "github.com/openshift-kni/performance-addon-operators/pkg/utils/testing"
 
+	corev1 "k8s.io/api/core/v1"
 	cpuset "k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 	"k8s.io/utils/pointer"
 )
@@ -50,6 +53,12 @@ var _ = Describe("Tuned", func() {
 		return string(y)
 	}
 
+	getTunedProfileData := func(profile *performancev2.PerformanceProfile) string {
+		tuned, err := NewNodePerformance(testAssetsDir, profile)
+		Expect(err).ToNot(HaveOccurred())
+		return *tuned.Spec.Profile[0].Data
+	}
+
 	Context("with worker performance profile", func() {
 		It("should generate yaml with expected parameters", func() {
 			manifest := getTunedManifest(profile)
@@ -92,6 +101,448 @@ var _ = Describe("Tuned", func() {
 			Expect(strings.Count(manifest, "hugepages=")).Should(BeNumerically("==", 2))
 		})
 
+		It("should generate node specific profiles for CPU node overrides", func() {
+			isolatedCPUs := performancev2.CPUSet("4-5")
+			profile.Spec.CPU.NodeOverrides = []performancev2.CPUNodeOverride{
+				{
+					Name:         "small",
+					NodeSelector: map[string]string{"feature.node.kubernetes.io/cpu-cores": "6"},
+					Isolated:     &isolatedCPUs,
+				},
+			}
+
+			tuned, err := NewNodePerformance(testAssetsDir, profile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tuned.Spec.Profile).To(HaveLen(2))
+			Expect(*tuned.Spec.Profile[0].Data).To(ContainSubstring("isolated_cores=4-7"))
+			Expect(*tuned.Spec.Profile[1].Name).To(Equal("openshift-node-performance-test-small"))
+			Expect(*tuned.Spec.Profile[1].Data).To(ContainSubstring("isolated_cores=4-5"))
+
+			Expect(tuned.Spec.Recommend).To(HaveLen(2))
+			recommend := tuned.Spec.Recommend[1]
+			Expect(*recommend.Profile).To(Equal("openshift-node-performance-test-small"))
+			Expect(*recommend.Priority).To(BeNumerically("<", *tuned.Spec.Recommend[0].Priority))
+			Expect(recommend.MachineConfigLabels).To(BeEmpty())
+
+			// the override applies only to nodes matching labels of the override and of the profile
+			Expect(recommend.Match).To(HaveLen(1))
+			Expect(*recommend.Match[0].Label).To(Equal("feature.node.kubernetes.io/cpu-cores"))
+			Expect(*recommend.Match[0].Value).To(Equal("6"))
+			Expect(recommend.Match[0].Match).To(HaveLen(1))
+			Expect(*recommend.Match[0].Match[0].Label).To(Equal("nodekey"))
+			Expect(*recommend.Match[0].Match[0].Value).To(Equal("nodeValue"))
+		})
+
+		Context("with user tuned profiles", func() {
+			BeforeEach(func() {
+				profile.Spec.TunedProfiles = []performancev2.TunedProfileFragment{
+					{
+						Name: "sysctl",
+						Data: pointer.StringPtr("[sysctl]\nkernel.panic=10\n"),
+					},
+					{
+						Name: "ksm",
+						Data: pointer.StringPtr("[main]\nsummary=Disable KSM\n\n[sysfs]\n/sys/kernel/mm/ksm/run=0\n"),
+					},
+				}
+			})
+
+			It("should chain child profiles from the generated profile and recommend the last one", func() {
+				tuned, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(tuned.Spec.Profile).To(HaveLen(3))
+
+				Expect(*tuned.Spec.Profile[1].Name).To(Equal("openshift-node-performance-test-sysctl"))
+				Expect(*tuned.Spec.Profile[1].Data).To(Equal("[main]\ninclude=openshift-node-performance-test\n\n[sysctl]\nkernel.panic=10\n"))
+
+				Expect(*tuned.Spec.Profile[2].Name).To(Equal("openshift-node-performance-test-ksm"))
+				Expect(*tuned.Spec.Profile[2].Data).To(Equal("[main]\ninclude=openshift-node-performance-test-sysctl\nsummary=Disable KSM\n\n[sysfs]\n/sys/kernel/mm/ksm/run=0\n"))
+
+				Expect(tuned.Spec.Recommend).To(HaveLen(1))
+				Expect(*tuned.Spec.Recommend[0].Profile).To(Equal("openshift-node-performance-test-ksm"))
+				Expect(*tuned.Spec.Recommend[0].Priority).To(Equal(uint64(20)))
+			})
+
+			It("should chain child profiles from node specific profiles", func() {
+				isolatedCPUs := performancev2.CPUSet("4-5")
+				profile.Spec.CPU.NodeOverrides = []performancev2.CPUNodeOverride{
+					{
+						Name:         "small",
+						NodeSelector: map[string]string{"feature.node.kubernetes.io/cpu-cores": "6"},
+						Isolated:     &isolatedCPUs,
+					},
+				}
+
+				tuned, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(tuned.Spec.Profile).To(HaveLen(6))
+				Expect(*tuned.Spec.Profile[3].Name).To(Equal("openshift-node-performance-test-small"))
+				Expect(*tuned.Spec.Profile[4].Name).To(Equal("openshift-node-performance-test-small-sysctl"))
+				Expect(*tuned.Spec.Profile[4].Data).To(HavePrefix("[main]\ninclude=openshift-node-performance-test-small\n"))
+				Expect(*tuned.Spec.Profile[5].Name).To(Equal("openshift-node-performance-test-small-ksm"))
+
+				Expect(tuned.Spec.Recommend).To(HaveLen(2))
+				Expect(*tuned.Spec.Recommend[1].Profile).To(Equal("openshift-node-performance-test-small-ksm"))
+				Expect(*tuned.Spec.Recommend[1].Priority).To(Equal(uint64(10)))
+			})
+
+			It("should fail to render invalid or unresolved user tuned profiles", func() {
+				profile.Spec.TunedProfiles[0].Data = pointer.StringPtr("[main]\ninclude=openshift-node\n")
+				_, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(`invalid tuned profile "sysctl": line 2: the profile should not include other profiles, the operator includes the parent profile`))
+
+				profile.Spec.TunedProfiles[0].Data = nil
+				profile.Spec.TunedProfiles[0].ConfigMapRef = &performancev2.TunedProfileConfigMapReference{Name: "tuned", Namespace: performancev2.ReferencedConfigMapsNamespace}
+				_, err = NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(`the tuned profile "sysctl" does not have data`))
+			})
+
+			It("should fail to render user tuned profiles that override the generated profile", func() {
+				profile.Spec.TunedProfiles[0].Data = pointer.StringPtr("[variables]\nisolated_cores=1-3\n")
+				_, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(ContainSubstring(`invalid tuned profile "sysctl": line 1: the section "variables" is not allowed`)))
+
+				profile.Spec.TunedProfiles[0].Data = pointer.StringPtr("[sysctl]\nkernel.timer_migration=1\n")
+				_, err = NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(ContainSubstring(`the sysctl "kernel.timer_migration" is owned by the operator`)))
+			})
+
+			It("should report sysctl and sysfs options of the whole chain of recommended profiles", func() {
+				profile.Spec.TunedProfiles[0].Data = pointer.StringPtr("[sysctl]\nkernel.panic=10\nkernel.hung_task_timeout_secs=700\n")
+
+				tuned, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).ToNot(HaveOccurred())
+
+				status := GetTuningStatus(tuned)
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.panic", "10"))
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.hung_task_timeout_secs", "700"))
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.sched_rt_runtime_us", "-1"))
+				Expect(status.Sysfs).To(HaveKeyWithValue("/sys/kernel/mm/ksm/run", "0"))
+			})
+		})
+
+		Context("with nodes of other architectures", func() {
+			It("should render arm64 huge page sizes without dummy kernel arguments", func() {
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitectureARM64
+				defaultSize := performancev2.HugePageSize("32M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []performancev2.HugePage{
+					{Size: "32M", Count: 64},
+					{Size: "1G", Count: 4},
+					{Size: "64K", Count: 128, Node: pointer.Int32Ptr(0)},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(MatchRegexp(`cmdline_hugepages=\+\s*default_hugepagesz=32M\s+hugepagesz=32M\s+hugepages=64\s+hugepagesz=1G\s+hugepages=4\s*\n`))
+				Expect(data).ToNot(ContainSubstring("hugepagesz=64K"))
+			})
+
+			It("should render huge page sizes of arm64 kernels with 64K pages", func() {
+				profile.Annotations = map[string]string{
+					performancev2.ArchitectureAnnotation:   performancev2.ArchitectureARM64,
+					performancev2.KernelPageSizeAnnotation: performancev2.KernelPageSize64K,
+				}
+				defaultSize := performancev2.HugePageSize("512M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []performancev2.HugePage{{Size: "64K", Count: 128}}
+				_, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(`the huge page size "64K" is not supported on the arm64 architecture with 64K kernel pages`))
+
+				profile.Spec.HugePages.Pages = []performancev2.HugePage{{Size: "512M", Count: 4}}
+				data := getTunedProfileData(profile)
+				Expect(data).To(MatchRegexp(`cmdline_hugepages=\+\s*default_hugepagesz=512M\s+hugepagesz=512M\s+hugepages=4\s*\n`))
+			})
+
+			It("should render ppc64le huge page sizes", func() {
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitecturePPC64LE
+				defaultSize := performancev2.HugePageSize("16M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []performancev2.HugePage{{Size: "16G", Count: 1}}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(MatchRegexp(`cmdline_hugepages=\+\s*default_hugepagesz=16M\s+hugepagesz=16G\s+hugepages=1\s*\n`))
+			})
+
+			It("should fail to render huge page sizes not supported by the s390x architecture", func() {
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitectureS390X
+				_, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(MatchError(`the huge page size "1G" is not supported on the s390x architecture`))
+
+				defaultSize := performancev2.HugePageSize("1M")
+				profile.Spec.HugePages.DefaultHugePagesSize = &defaultSize
+				profile.Spec.HugePages.Pages = []performancev2.HugePage{{Size: "2G", Count: 2}}
+				data := getTunedProfileData(profile)
+				Expect(data).To(MatchRegexp(`cmdline_hugepages=\+\s*default_hugepagesz=1M\s+hugepagesz=2G\s+hugepages=2\s*\n`))
+			})
+		})
+
+		Context("with CPU vendors", func() {
+			// compareWithGolden compares the tuned profile data with the golden file of the CPU vendor
+			compareWithGolden := func(data string, vendor performancev2.CPUVendor) {
+				golden, err := ioutil.ReadFile(filepath.Join("testdata", fmt.Sprintf("openshift-node-performance-%s.golden", strings.ToLower(string(vendor)))))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(data).To(Equal(string(golden)))
+			}
+
+			It("should render Intel kernel arguments by default", func() {
+				compareWithGolden(getTunedProfileData(profile), performancev2.CPUVendorIntel)
+			})
+
+			It("should render AMD kernel arguments", func() {
+				vendor := performancev2.CPUVendorAMD
+				profile.Spec.CPU.Vendor = &vendor
+				compareWithGolden(getTunedProfileData(profile), performancev2.CPUVendorAMD)
+			})
+
+			It("should render ARM kernel arguments", func() {
+				profile.Spec.NodeSelector[corev1.LabelArchStable] = performancev2.ArchitectureARM64
+				compareWithGolden(getTunedProfileData(profile), performancev2.CPUVendorARM)
+			})
+
+			It("should select the CPU vendor by the node feature discovery label", func() {
+				profile.Spec.NodeSelector[performancev2.CPUVendorLabel] = string(performancev2.CPUVendorAMD)
+				compareWithGolden(getTunedProfileData(profile), performancev2.CPUVendorAMD)
+			})
+		})
+
+		Context("with the power policy", func() {
+			It("should keep all CPUs in the C1 state without the power policy", func() {
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("force_latency=cstate.id:1|3"))
+				Expect(data).ToNot(ContainSubstring("[cpu_isolated]"))
+				Expect(data).ToNot(ContainSubstring("cmdline_power"))
+			})
+
+			It("should render governors and C-states of reserved and isolated CPUs", func() {
+				powersave := performancev2.CPUGovernorPowersave
+				profile.Spec.PowerPolicy = &performancev2.PowerPolicy{
+					Reserved: &performancev2.CPUPowerSettings{Governor: &powersave},
+					Isolated: &performancev2.CPUPowerSettings{MaxCState: pointer.Int32Ptr(0)},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("[cpu]\n" +
+					"devices=${f:cpulist2devs:${not_isolated_cores_expanded}}\n" +
+					"force_latency=\n" +
+					"governor=powersave\n" +
+					"energy_perf_bias=normal\n\n" +
+					"[cpu_isolated]\n" +
+					"type=cpu\n" +
+					"devices=${f:cpulist2devs:${isolated_cores}}\n" +
+					"governor=performance\n" +
+					"energy_perf_bias=performance\n\n" +
+					"[service]"))
+				Expect(data).To(ContainSubstring("[sysfs]\n/sys/devices/system/cpu/cpu[4-7]/cpuidle/state[1-9]/disable=1\n"))
+				Expect(data).ToNot(ContainSubstring("/sys/devices/system/cpu/cpu[0-3]/"))
+			})
+
+			It("should limit isolated CPUs to the C1 state by default", func() {
+				powersave := performancev2.CPUGovernorPowersave
+				profile.Spec.PowerPolicy = &performancev2.PowerPolicy{
+					Reserved: &performancev2.CPUPowerSettings{Governor: &powersave},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("force_latency=\n"))
+				Expect(data).To(ContainSubstring("[sysfs]\n/sys/devices/system/cpu/cpu[4-7]/cpuidle/state[2-9]/disable=1\n"))
+
+				mode := performancev2.PowerModeUltraLowLatency
+				profile.Spec.PowerPolicy.Mode = &mode
+				Expect(getTunedProfileData(profile)).ToNot(ContainSubstring("cpuidle"))
+			})
+
+			It("should match CPU ranges with globs", func() {
+				for cpus, globs := range map[string][]string{
+					"4-7":           {"[4-7]"},
+					"3":             {"3"},
+					"0-9":           {"[0-9]"},
+					"4-27":          {"[4-9]", "1[0-9]", "2[0-7]"},
+					"8-11,14,20-39": {"[8-9]", "1[0-1]", "14", "[2-3][0-9]"},
+					"2-127":         {"[2-9]", "[1-9][0-9]", "1[0-1][0-9]", "12[0-7]"},
+					"100-299":       {"[1-2][0-9][0-9]"},
+				} {
+					set, err := cpuset.Parse(cpus)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(getCPUListGlobs(set)).To(Equal(globs), cpus)
+				}
+			})
+
+			It("should render kernel arguments of the power mode", func() {
+				mode := performancev2.PowerModeLowLatency
+				profile.Spec.PowerPolicy = &performancev2.PowerPolicy{Mode: &mode}
+				Expect(getTunedProfileData(profile)).To(MatchRegexp(`cmdline_power=\+nmi_watchdog=0 audit=0 mce=off\s*\n`))
+
+				mode = performancev2.PowerModeUltraLowLatency
+				Expect(getTunedProfileData(profile)).To(MatchRegexp(`cmdline_power=\+nmi_watchdog=0 audit=0 mce=off processor.max_cstate=1 intel_idle.max_cstate=0 idle=poll\s*\n`))
+
+				vendor := performancev2.CPUVendorAMD
+				profile.Spec.CPU.Vendor = &vendor
+				Expect(getTunedProfileData(profile)).To(MatchRegexp(`cmdline_power=\+nmi_watchdog=0 audit=0 mce=off processor.max_cstate=1 idle=poll\s*\n`))
+			})
+
+			It("should keep the sysfs section when huge pages are allocated at runtime", func() {
+				profile.Spec.PowerPolicy = &performancev2.PowerPolicy{
+					Reserved: &performancev2.CPUPowerSettings{MaxCState: pointer.Int32Ptr(6)},
+				}
+				profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+				profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+					performancev2.HugePage{Size: "2M", Count: 128, Node: pointer.Int32Ptr(0)},
+				)
+
+				data := getTunedProfileData(profile)
+				Expect(strings.Count(data, "[sysfs]")).To(Equal(1))
+				Expect(data).To(ContainSubstring("/sys/devices/system/cpu/cpu[0-3]/cpuidle/state[7-9]/disable=1\n" +
+					"/sys/devices/system/cpu/cpu[4-7]/cpuidle/state[2-9]/disable=1\n" +
+					"/sys/devices/system/node/node0/hugepages/hugepages-2048kB/nr_hugepages=128\n"))
+			})
+		})
+
+		It("should write huge pages allocated at runtime to the sysfs", func() {
+			manifest := getTunedManifest(profile)
+			Expect(manifest).ToNot(ContainSubstring("[sysfs]"))
+
+			profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+			profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+				performancev2.HugePage{Size: "2M", Count: 128, Node: pointer.Int32Ptr(0)},
+				performancev2.HugePage{Size: "2M", Count: 64, Node: pointer.Int32Ptr(1)},
+			)
+			manifest = getTunedManifest(profile)
+			Expect(manifest).To(ContainSubstring("[sysfs]\\n/sys/devices/system/node/node0/hugepages/hugepages-2048kB/nr_hugepages=128\\n" +
+				"/sys/devices/system/node/node1/hugepages/hugepages-2048kB/nr_hugepages=64\\n"))
+			By("Keeping the dummy 2M huge pages kernel arguments")
+			Expect(cmdlineDummy2MHugePages.MatchString(manifest)).To(BeTrue())
+		})
+
+		Context("with kernel threads", func() {
+			It("should override default groups and add custom groups", func() {
+				rr := performancev2.SchedulingPolicyRoundRobin
+				other := performancev2.SchedulingPolicyOther
+				reserved := performancev2.KernelThreadsAffinityReserved
+				profile.Spec.KernelThreads = &performancev2.KernelThreads{
+					Groups: []performancev2.KernelThreadsGroup{
+						{Name: "rcuc", Policy: &rr, Priority: pointer.Int32Ptr(20)},
+						{Name: "kworker", Policy: &other, Affinity: &reserved},
+						{Name: "irq", Regex: pointer.StringPtr("irq/.*")},
+					},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("[scheduler]\n" +
+					"runtime=0\n" +
+					"group.ksoftirqd=0:f:11:*:ksoftirqd.*\n" +
+					"group.rcuc=0:r:20:*:rcuc.*\n" +
+					"group.kworker=0:o:0:${not_isolated_cpumask}:kworker.*\n" +
+					"group.irq=0:f:11:*:irq/.*\n"))
+			})
+
+			It("should render kernel arguments of the RCU offload mode", func() {
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("cmdline_cpu_part=+nohz=on rcu_nocbs=${isolated_cores} tuned.non_isolcpus"))
+
+				poll := performancev2.RCUOffloadNoCBsPoll
+				profile.Spec.KernelThreads = &performancev2.KernelThreads{RCUOffload: &poll}
+				data = getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("cmdline_cpu_part=+nohz=on rcu_nocbs=${isolated_cores} rcu_nocb_poll tuned.non_isolcpus"))
+
+				none := performancev2.RCUOffloadNone
+				profile.Spec.KernelThreads.RCUOffload = &none
+				data = getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("cmdline_cpu_part=+nohz=on tuned.non_isolcpus"))
+			})
+		})
+
+		It("should stop the stalld service when stalld is disabled", func() {
+			data := getTunedProfileData(profile)
+			Expect(data).To(ContainSubstring("service.stalld=start,enable"))
+
+			profile.Spec.Stalld = &performancev2.Stalld{Enabled: pointer.BoolPtr(false)}
+			data = getTunedProfileData(profile)
+			Expect(data).To(ContainSubstring("service.stalld=stop,disable"))
+		})
+
+		Context("with tuning overrides", func() {
+			It("should replace sysctl options of the profile and add new ones", func() {
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysctl: map[string]string{
+						"kernel.hung_task_timeout_secs": "700",
+						"vm.swappiness":                 "0",
+						"net.core.somaxconn":            "4096",
+						"kernel.panic":                  "10",
+					},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("[sysctl]\nkernel.hung_task_timeout_secs=700\n"))
+				Expect(data).To(ContainSubstring("\nvm.swappiness=0\n"))
+				Expect(data).To(ContainSubstring("kernel.sched_migration_cost_ns=5000000        # latency-performance\n" +
+					"kernel.panic=10\n" +
+					"net.core.somaxconn=4096\n" +
+					"\n" +
+					"[selinux]"))
+				Expect(strings.Count(data, "kernel.hung_task_timeout_secs")).To(Equal(1))
+				Expect(strings.Count(data, "vm.swappiness")).To(Equal(1))
+			})
+
+			It("should merge sysfs overrides with sysfs options of the profile", func() {
+				profile.Spec.HugePages.RuntimeAllocation = pointer.BoolPtr(true)
+				profile.Spec.HugePages.Pages = append(profile.Spec.HugePages.Pages,
+					performancev2.HugePage{Size: "2M", Count: 128, Node: pointer.Int32Ptr(0)},
+				)
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysfs: map[string]string{
+						"/sys/kernel/mm/ksm/run":                                   "0",
+						"/sys/bus/workqueue/devices/writeback/cpumask":             "1",
+						"/sys/devices/virtual/workqueue/cpumask":                   "1",
+						"/sys/devices/system/machinecheck/machinecheck*/ignore_ce": "1",
+					},
+				}
+
+				data := getTunedProfileData(profile)
+				Expect(data).To(ContainSubstring("[sysfs]\n" +
+					"/sys/devices/system/node/node0/hugepages/hugepages-2048kB/nr_hugepages=128\n" +
+					"/sys/bus/workqueue/devices/writeback/cpumask=1\n" +
+					"/sys/devices/system/machinecheck/machinecheck*/ignore_ce=1\n" +
+					"/sys/devices/virtual/workqueue/cpumask=1\n" +
+					"/sys/kernel/mm/ksm/run=0\n"))
+			})
+
+			It("should add the sysfs section when the profile does not have sysfs options", func() {
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysfs: map[string]string{"/sys/kernel/mm/ksm/run": "0"},
+				}
+				Expect(getTunedProfileData(profile)).To(ContainSubstring("[sysfs]\n/sys/kernel/mm/ksm/run=0\n"))
+			})
+
+			It("should fail to override settings owned by the operator", func() {
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysctl: map[string]string{"kernel.sched_rt_runtime_us": "950000"},
+					Sysfs:  map[string]string{"/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages": "128"},
+				}
+
+				_, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(`the sysctl "kernel.sched_rt_runtime_us" is owned by the operator`))
+				Expect(err.Error()).To(ContainSubstring(`the sysfs attribute "/sys/kernel/mm/hugepages/hugepages-2048kB/nr_hugepages" is owned by the operator`))
+			})
+
+			It("should report effective sysctl and sysfs options", func() {
+				profile.Spec.Tuning = &performancev2.Tuning{
+					Sysctl: map[string]string{"kernel.hung_task_timeout_secs": "700", "kernel.panic": "10"},
+					Sysfs:  map[string]string{"/sys/kernel/mm/ksm/run": "0"},
+				}
+
+				tuned, err := NewNodePerformance(testAssetsDir, profile)
+				Expect(err).ToNot(HaveOccurred())
+
+				status := GetTuningStatus(tuned)
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.hung_task_timeout_secs", "700"))
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.panic", "10"))
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.sched_rt_runtime_us", "-1"))
+				Expect(status.Sysctl).To(HaveKeyWithValue("kernel.numa_balancing", "0"))
+				Expect(status.Sysfs).To(Equal(map[string]string{"/sys/kernel/mm/ksm/run": "0"}))
+			})
+		})
+
 		Context("with 1G default huge pages", func() {
 			Context("with requested 2M huge pages allocation on the specified node", func() {
 				It("should append the dummy 2M huge pages kernel arguments", func() {
@@ -0,0 +1,165 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+)
+
+const (
+	labelProfile   = "profile"
+	labelType      = "type"
+	labelState     = "state"
+	labelComponent = "component"
+)
+
+var (
+	// ProfileCondition reports the status of the performance profile conditions, 1 when the condition is true
+	ProfileCondition = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pao_profile_condition",
+			Help: "The status of the performance profile condition, 1 when the condition is true and 0 otherwise.",
+		},
+		[]string{labelProfile, labelType},
+	)
+
+	// ProfileNodes reports the number of nodes selected by the performance profile per rollout state
+	ProfileNodes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "pao_profile_nodes",
+			Help: "The number of nodes selected by the performance profile per rollout state.",
+		},
+		[]string{labelProfile, labelState},
+	)
+
+	// ReconcileDuration reports the time spent on the reconcile of each generated component
+	ReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "pao_reconcile_duration_seconds",
+			Help:    "The time spent on the reconcile of the performance profile component.",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{labelComponent},
+	)
+
+	// ComponentUpdates reports the number of generated component updates, every machine config or
+	// kubelet config update can reboot nodes
+	ComponentUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pao_component_updates_total",
+			Help: "The number of the performance profile component creations and updates.",
+		},
+		[]string{labelProfile, labelComponent},
+	)
+
+	// RenderFailures reports the number of failures to render the performance profile components
+	RenderFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "pao_render_failures_total",
+			Help: "The number of failures to render the performance profile components.",
+		},
+		[]string{labelProfile},
+	)
+)
+
+var profileConditionTypes = []conditionsv1.ConditionType{
+	conditionsv1.ConditionAvailable,
+	conditionsv1.ConditionUpgradeable,
+	conditionsv1.ConditionProgressing,
+	conditionsv1.ConditionDegraded,
+}
+
+var nodeRolloutStates = []performancev2.NodeRolloutState{
+	performancev2.NodeRolloutStateUpdated,
+	performancev2.NodeRolloutStateUpdating,
+	performancev2.NodeRolloutStateDegraded,
+}
+
+// the list of components is needed to delete metrics of the removed profile
+var componentKinds = []string{"MachineConfig", "KubeletConfig", "Tuned", "RuntimeClass"}
+
+func init() {
+	metrics.Registry.MustRegister(
+		ProfileCondition,
+		ProfileNodes,
+		ReconcileDuration,
+		ComponentUpdates,
+		RenderFailures,
+	)
+}
+
+// SetProfileConditions updates the condition metrics of the profile
+func SetProfileConditions(profileName string, conditions []conditionsv1.Condition) {
+	for _, condition := range conditions {
+		value := 0.0
+		if condition.Status == corev1.ConditionTrue {
+			value = 1.0
+		}
+		ProfileCondition.WithLabelValues(profileName, string(condition.Type)).Set(value)
+	}
+}
+
+// SetProfileNodes updates the nodes metrics of the profile
+func SetProfileNodes(profileName string, nodesStatus *performancev2.NodesStatus) {
+	counters := map[performancev2.NodeRolloutState]int32{
+		performancev2.NodeRolloutStateUpdated:  nodesStatus.Updated,
+		performancev2.NodeRolloutStateUpdating: nodesStatus.Updating,
+		performancev2.NodeRolloutStateDegraded: nodesStatus.Degraded,
+	}
+
+	for state, count := range counters {
+		ProfileNodes.WithLabelValues(profileName, string(state)).Set(float64(count))
+	}
+}
+
+// IncComponentUpdates increments the number of updates of the profile component
+func IncComponentUpdates(profileName string, component string) {
+	ComponentUpdates.WithLabelValues(profileName, component).Inc()
+}
+
+// IncRenderFailures increments the number of failures to render the profile components
+func IncRenderFailures(profileName string) {
+	RenderFailures.WithLabelValues(profileName).Inc()
+}
+
+// DeleteProfile removes all metrics of the profile
+func DeleteProfile(profileName string) {
+	for _, conditionType := range profileConditionTypes {
+		ProfileCondition.DeleteLabelValues(profileName, string(conditionType))
+	}
+
+	for _, state := range nodeRolloutStates {
+		ProfileNodes.DeleteLabelValues(profileName, string(state))
+	}
+
+	for _, component := range componentKinds {
+		ComponentUpdates.DeleteLabelValues(profileName, component)
+	}
+
+	RenderFailures.DeleteLabelValues(profileName)
+}
+
+// ReconcileTimer accumulates the time spent on the reconcile of each component
+type ReconcileTimer map[string]time.Duration
+
+// NewReconcileTimer returns the new reconcile timer
+func NewReconcileTimer() ReconcileTimer {
+	return ReconcileTimer{}
+}
+
+// Track adds the time passed since the start to the component reconcile duration
+func (t ReconcileTimer) Track(component string, start time.Time) {
+	t[component] += time.Since(start)
+}
+
+// Observe reports durations of all tracked components
+func (t ReconcileTimer) Observe() {
+	for component, duration := range t {
+		ReconcileDuration.WithLabelValues(component).Observe(duration.Seconds())
+	}
+}
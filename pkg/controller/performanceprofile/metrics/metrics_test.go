@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	corev1 "k8s.io/api/core/v1"
+
+	performancev2 "github.com/openshift-kni/performance-addon-operators/api/v2"
+	conditionsv1 "github.com/openshift/custom-resource-status/conditions/v1"
+)
+
+const testProfileName = "test"
+
+var _ = Describe("Performance profile metrics", func() {
+	AfterEach(func() {
+		DeleteProfile(testProfileName)
+	})
+
+	It("should report the profile conditions", func() {
+		SetProfileConditions(testProfileName, []conditionsv1.Condition{
+			{
+				Type:   conditionsv1.ConditionAvailable,
+				Status: corev1.ConditionFalse,
+			},
+			{
+				Type:   conditionsv1.ConditionDegraded,
+				Status: corev1.ConditionTrue,
+			},
+		})
+
+		Expect(getGaugeValue(ProfileCondition.WithLabelValues(testProfileName, string(conditionsv1.ConditionAvailable)))).To(Equal(0.0))
+		Expect(getGaugeValue(ProfileCondition.WithLabelValues(testProfileName, string(conditionsv1.ConditionDegraded)))).To(Equal(1.0))
+	})
+
+	It("should report the number of nodes per rollout state", func() {
+		SetProfileNodes(testProfileName, &performancev2.NodesStatus{
+			Total:    6,
+			Updated:  3,
+			Updating: 2,
+			Degraded: 1,
+		})
+
+		Expect(getGaugeValue(ProfileNodes.WithLabelValues(testProfileName, string(performancev2.NodeRolloutStateUpdated)))).To(Equal(3.0))
+		Expect(getGaugeValue(ProfileNodes.WithLabelValues(testProfileName, string(performancev2.NodeRolloutStateUpdating)))).To(Equal(2.0))
+		Expect(getGaugeValue(ProfileNodes.WithLabelValues(testProfileName, string(performancev2.NodeRolloutStateDegraded)))).To(Equal(1.0))
+	})
+
+	It("should count component updates and render failures", func() {
+		IncComponentUpdates(testProfileName, "MachineConfig")
+		IncComponentUpdates(testProfileName, "MachineConfig")
+		IncRenderFailures(testProfileName)
+
+		Expect(getCounterValue(ComponentUpdates.WithLabelValues(testProfileName, "MachineConfig"))).To(Equal(2.0))
+		Expect(getCounterValue(RenderFailures.WithLabelValues(testProfileName))).To(Equal(1.0))
+	})
+
+	It("should remove metrics of the deleted profile", func() {
+		IncComponentUpdates(testProfileName, "Tuned")
+		SetProfileNodes(testProfileName, &performancev2.NodesStatus{Updated: 1})
+
+		DeleteProfile(testProfileName)
+
+		Expect(ComponentUpdates.DeleteLabelValues(testProfileName, "Tuned")).To(BeFalse())
+		Expect(ProfileNodes.DeleteLabelValues(testProfileName, string(performancev2.NodeRolloutStateUpdated))).To(BeFalse())
+	})
+
+	It("should observe the accumulated reconcile duration of each component", func() {
+		timer := NewReconcileTimer()
+		timer.Track("RuntimeClass", time.Now().Add(-time.Second))
+		timer.Track("RuntimeClass", time.Now().Add(-time.Second))
+
+		before := getHistogramCount(ReconcileDuration.WithLabelValues("RuntimeClass"))
+		Expect(timer["RuntimeClass"]).To(BeNumerically(">=", 2*time.Second))
+
+		timer.Observe()
+		Expect(getHistogramCount(ReconcileDuration.WithLabelValues("RuntimeClass"))).To(Equal(before + 1))
+	})
+})
+
+func getGaugeValue(gauge prometheus.Gauge) float64 {
+	m := &dto.Metric{}
+	Expect(gauge.Write(m)).To(Succeed())
+	return m.GetGauge().GetValue()
+}
+
+func getCounterValue(counter prometheus.Counter) float64 {
+	m := &dto.Metric{}
+	Expect(counter.Write(m)).To(Succeed())
+	return m.GetCounter().GetValue()
+}
+
+func getHistogramCount(observer prometheus.Observer) uint64 {
+	m := &dto.Metric{}
+	Expect(observer.(prometheus.Metric).Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleCount()
+}
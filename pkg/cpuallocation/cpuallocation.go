@@ -0,0 +1,316 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ */
+
+// Package cpuallocation splits CPUs of the node topology into reserved and isolated CPUs,
+// it is shared by the performance profile creator and the operator
+package cpuallocation
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+)
+
+// MaxTopologyReportSize is the size limit of the termination message of the container that carries the report
+const MaxTopologyReportSize = 4096
+
+// allCores correspond to the value when all the processorCores need to be added to the generated CPUset
+const allCores = -1
+
+// ParseTopology returns the sorted topology from its JSON representation
+func ParseTopology(data string) (*topology.Info, error) {
+	topologyInfo := &topology.Info{}
+	if err := json.Unmarshal([]byte(data), topologyInfo); err != nil {
+		return nil, err
+	}
+
+	if len(topologyInfo.Nodes) == 0 {
+		return nil, fmt.Errorf("the CPU topology does not have NUMA nodes")
+	}
+
+	for _, node := range topologyInfo.Nodes {
+		for _, core := range node.Cores {
+			if len(core.LogicalProcessors) == 0 {
+				return nil, fmt.Errorf("the core %d of the NUMA node %d does not have logical processors", core.ID, node.ID)
+			}
+		}
+	}
+
+	SortTopology(topologyInfo)
+	return topologyInfo, nil
+}
+
+// topologyNode is the part of the NUMA node used by the CPU allocation
+type topologyNode struct {
+	ID    int            `json:"id"`
+	Cores []topologyCore `json:"cores"`
+}
+
+// topologyCore is the part of the core used by the CPU allocation
+type topologyCore struct {
+	ID                int   `json:"id"`
+	LogicalProcessors []int `json:"logical_processors"`
+}
+
+// MarshalTopology returns the JSON representation of the topology read by ParseTopology, only NUMA node IDs,
+// core IDs and logical processors are kept
+func MarshalTopology(topologyInfo *topology.Info) (string, error) {
+	nodes := []topologyNode{}
+	for _, node := range topologyInfo.Nodes {
+		n := topologyNode{ID: node.ID, Cores: []topologyCore{}}
+		for _, core := range node.Cores {
+			n.Cores = append(n.Cores, topologyCore{ID: core.ID, LogicalProcessors: core.LogicalProcessors})
+		}
+		nodes = append(nodes, n)
+	}
+
+	data, err := json.Marshal(struct {
+		Nodes []topologyNode `json:"nodes"`
+	}{Nodes: nodes})
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// FormatTopologyReport returns the compact representation of the topology, every line describes a NUMA node,
+// the NUMA node ID is followed by cores and their logical processors, for example "0 0:0,4 1:1,5", lines are
+// compressed and base64 encoded, the kubelet truncates termination messages of the discovery pod to
+// MaxTopologyReportSize bytes, so the larger report is rejected instead of being truncated
+func FormatTopologyReport(topologyInfo *topology.Info) (string, error) {
+	var lines []string
+	for _, node := range topologyInfo.Nodes {
+		fields := []string{strconv.Itoa(node.ID)}
+		for _, core := range node.Cores {
+			var processors []string
+			for _, processor := range core.LogicalProcessors {
+				processors = append(processors, strconv.Itoa(processor))
+			}
+			fields = append(fields, fmt.Sprintf("%d:%s", core.ID, strings.Join(processors, ",")))
+		}
+		lines = append(lines, strings.Join(fields, " "))
+	}
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.Join(lines, "\n"))); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+
+	report := base64.StdEncoding.EncodeToString(buf.Bytes())
+	if len(report) > MaxTopologyReportSize {
+		return "", fmt.Errorf("the CPU topology report takes %d bytes, more than %d bytes of the termination message", len(report), MaxTopologyReportSize)
+	}
+	return report, nil
+}
+
+// ParseTopologyReport returns the sorted topology from its compact representation, the truncated report
+// fails the decompression
+func ParseTopologyReport(report string) (*topology.Info, error) {
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(report))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the CPU topology report: %v", err)
+	}
+
+	r, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the CPU topology report: %v", err)
+	}
+
+	raw, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress the CPU topology report: %v", err)
+	}
+	data := string(raw)
+
+	topologyInfo := &topology.Info{}
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		nodeID, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("malformed NUMA node ID %q", fields[0])
+		}
+
+		node := &topology.Node{ID: nodeID}
+		for _, field := range fields[1:] {
+			parts := strings.SplitN(field, ":", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("malformed core %q of the NUMA node %d", field, nodeID)
+			}
+
+			coreID, err := strconv.Atoi(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("malformed core %q of the NUMA node %d", field, nodeID)
+			}
+
+			core := &cpu.ProcessorCore{ID: coreID}
+			for _, processor := range strings.Split(parts[1], ",") {
+				processorID, err := strconv.Atoi(processor)
+				if err != nil {
+					return nil, fmt.Errorf("malformed core %q of the NUMA node %d", field, nodeID)
+				}
+				core.LogicalProcessors = append(core.LogicalProcessors, processorID)
+			}
+			node.Cores = append(node.Cores, core)
+		}
+		topologyInfo.Nodes = append(topologyInfo.Nodes, node)
+	}
+
+	if len(topologyInfo.Nodes) == 0 {
+		return nil, fmt.Errorf("the CPU topology does not have NUMA nodes")
+	}
+
+	SortTopology(topologyInfo)
+	return topologyInfo, nil
+}
+
+// SortTopology sorts the topology by NUMA node IDs and CPU IDs
+func SortTopology(topologyInfo *topology.Info) {
+	sort.Slice(topologyInfo.Nodes, func(x, y int) bool {
+		return topologyInfo.Nodes[x].ID < topologyInfo.Nodes[y].ID
+	})
+	for _, node := range topologyInfo.Nodes {
+		for _, core := range node.Cores {
+			sort.Slice(core.LogicalProcessors, func(x, y int) bool {
+				return core.LogicalProcessors[x] < core.LogicalProcessors[y]
+			})
+		}
+		sort.Slice(node.Cores, func(i, j int) bool {
+			return node.Cores[i].LogicalProcessors[0] < node.Cores[j].LogicalProcessors[0]
+		})
+	}
+}
+
+// IsHyperthreadingEnabled returns true when cores of the topology have more than one logical processor
+func IsHyperthreadingEnabled(topologyInfoNodes []*topology.Node) bool {
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			if len(core.LogicalProcessors) > 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SplitAcrossNUMA returns Reserved and Isolated CPUs split across NUMA nodes
+// We identify the right number of CPUs that need to be allocated per NUMA node, meaning reservedPerNuma + (the additional number based on the remainder and the NUMA node)
+// E.g. If the user requests 15 reserved cpus and we have 4 numa nodes, we find reservedPerNuma in this case is 3 and remainder = 3.
+// For each numa node we find a max which keeps track of the cumulative resources that should be allocated for each NUMA node:
+// max = (numaID+1)*reservedPerNuma + (numaNodeNum - remainder)
+// For NUMA node 0 max = (0+1)*3 + 4-3 = 4 remainder is decremented => remainder is 2
+// For NUMA node 1 max = (1+1)*3 + 4-2 = 8 remainder is decremented => remainder is 1
+// For NUMA node 2 max = (2+1)*3 + 4-2 = 12 remainder is decremented => remainder is 0
+// For NUMA Node 3 remainder = 0 so max = 12 + 3 = 15.
+func SplitAcrossNUMA(reservedCPUCount int, htEnabled bool, topologyInfoNodes []*topology.Node) (cpuset.CPUSet, cpuset.CPUSet, error) {
+	reservedCPUSet := cpuset.NewBuilder()
+	var isolatedCPUSet cpuset.CPUSet
+	numaNodeNum := len(topologyInfoNodes)
+
+	max := 0
+	reservedPerNuma := reservedCPUCount / numaNodeNum
+	remainder := reservedCPUCount % numaNodeNum
+	for numaID, node := range topologyInfoNodes {
+		if remainder != 0 {
+			max = (numaID+1)*reservedPerNuma + (numaNodeNum - remainder)
+			remainder--
+		} else {
+			max = max + reservedPerNuma
+		}
+		if max%2 != 0 && htEnabled {
+			return reservedCPUSet.Result(), isolatedCPUSet, fmt.Errorf("can't allocate odd number of CPUs from a NUMA Node")
+		}
+		addCoresToCPUSet(reservedCPUSet, max, node.Cores)
+	}
+	totalCPUSet := TotalCPUSet(topologyInfoNodes)
+	isolatedCPUSet = totalCPUSet.Difference(reservedCPUSet.Result())
+	return reservedCPUSet.Result(), isolatedCPUSet, nil
+}
+
+// Sequential returns Reserved and Isolated CPUs sequentially
+func Sequential(reservedCPUCount int, htEnabled bool, topologyInfoNodes []*topology.Node) (cpuset.CPUSet, cpuset.CPUSet, error) {
+	reservedCPUSet := cpuset.NewBuilder()
+	var isolatedCPUSet cpuset.CPUSet
+	if reservedCPUCount%2 != 0 && htEnabled {
+		return reservedCPUSet.Result(), isolatedCPUSet, fmt.Errorf("can't allocate odd number of CPUs from a NUMA Node")
+	}
+	for _, node := range topologyInfoNodes {
+		addCoresToCPUSet(reservedCPUSet, reservedCPUCount, node.Cores)
+	}
+	totalCPUSet := TotalCPUSet(topologyInfoNodes)
+	isolatedCPUSet = totalCPUSet.Difference(reservedCPUSet.Result())
+	return reservedCPUSet.Result(), isolatedCPUSet, nil
+}
+
+// WholeCores returns Reserved and Isolated CPUs sequentially, reserving all logical processors of each core,
+// so isolated CPUs never share a core with reserved CPUs. The number of reserved CPUs is rounded up to whole cores.
+func WholeCores(reservedCPUCount int, topologyInfoNodes []*topology.Node) (cpuset.CPUSet, cpuset.CPUSet, error) {
+	reservedCPUSet := cpuset.NewBuilder()
+	for _, node := range topologyInfoNodes {
+		for _, core := range node.Cores {
+			if reservedCPUSet.Result().Size() >= reservedCPUCount {
+				break
+			}
+			reservedCPUSet.Add(core.LogicalProcessors...)
+		}
+	}
+	totalCPUSet := TotalCPUSet(topologyInfoNodes)
+	isolatedCPUSet := totalCPUSet.Difference(reservedCPUSet.Result())
+	if isolatedCPUSet.IsEmpty() {
+		return reservedCPUSet.Result(), isolatedCPUSet, fmt.Errorf("can't reserve %d CPUs without leaving isolated CPUs", reservedCPUCount)
+	}
+	return reservedCPUSet.Result(), isolatedCPUSet, nil
+}
+
+// TotalCPUSet returns all CPUs of the topology
+func TotalCPUSet(topologyInfoNodes []*topology.Node) cpuset.CPUSet {
+	totalCPUSet := cpuset.NewBuilder()
+	for _, node := range topologyInfoNodes {
+		//all the cores from node.Cores need to be added, hence allCores is specified as the max value
+		addCoresToCPUSet(totalCPUSet, allCores, node.Cores)
+	}
+	return totalCPUSet.Result()
+}
+
+// addCoresToCPUSet adds logical cores from the slice of *cpu.ProcessorCore to a CPUset till the cpuset size is equal to the max value speicifed
+// In case the max is specified as allCores, all the cores from the slice of *cpu.ProcessorCore are added to the CPUset
+func addCoresToCPUSet(reservedCPUSet cpuset.Builder, max int, cores []*cpu.ProcessorCore) {
+	for _, processorCore := range cores {
+		for _, core := range processorCore.LogicalProcessors {
+			if reservedCPUSet.Result().Size() < max || max == allCores {
+				reservedCPUSet.Add(core)
+			}
+		}
+	}
+}
@@ -0,0 +1,13 @@
+package cpuallocation
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCPUAllocation(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "CPU Allocation Suite")
+}
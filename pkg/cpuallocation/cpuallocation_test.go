@@ -0,0 +1,129 @@
+package cpuallocation
+
+import (
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CPU allocation", func() {
+	var topologyInfoNodes []*topology.Node
+
+	BeforeEach(func() {
+		topologyInfoNodes = []*topology.Node{
+			{
+				ID: 0,
+				Cores: []*cpu.ProcessorCore{
+					{ID: 0, NumThreads: 2, LogicalProcessors: []int{0, 8}},
+					{ID: 1, NumThreads: 2, LogicalProcessors: []int{2, 10}},
+					{ID: 2, NumThreads: 2, LogicalProcessors: []int{4, 12}},
+					{ID: 3, NumThreads: 2, LogicalProcessors: []int{6, 14}},
+				},
+			},
+			{
+				ID: 1,
+				Cores: []*cpu.ProcessorCore{
+					{ID: 0, NumThreads: 2, LogicalProcessors: []int{1, 9}},
+					{ID: 1, NumThreads: 2, LogicalProcessors: []int{3, 11}},
+					{ID: 2, NumThreads: 2, LogicalProcessors: []int{5, 13}},
+					{ID: 3, NumThreads: 2, LogicalProcessors: []int{7, 15}},
+				},
+			},
+		}
+	})
+
+	It("should detect hyperthreading", func() {
+		Expect(IsHyperthreadingEnabled(topologyInfoNodes)).To(BeTrue())
+
+		topologyInfoNodes[0].Cores = []*cpu.ProcessorCore{{ID: 0, NumThreads: 1, LogicalProcessors: []int{0}}}
+		topologyInfoNodes[1].Cores = []*cpu.ProcessorCore{{ID: 0, NumThreads: 1, LogicalProcessors: []int{1}}}
+		Expect(IsHyperthreadingEnabled(topologyInfoNodes)).To(BeFalse())
+	})
+
+	It("should reserve CPUs sequentially", func() {
+		reserved, isolated, err := Sequential(4, true, topologyInfoNodes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reserved.String()).To(Equal("0,2,8,10"))
+		Expect(isolated.String()).To(Equal("1,3-7,9,11-15"))
+	})
+
+	It("should reserve CPUs split across NUMA nodes", func() {
+		reserved, isolated, err := SplitAcrossNUMA(4, true, topologyInfoNodes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reserved.String()).To(Equal("0-1,8-9"))
+		Expect(isolated.String()).To(Equal("2-7,10-15"))
+	})
+
+	It("should reject the odd number of reserved CPUs with hyperthreading", func() {
+		_, _, err := Sequential(3, true, topologyInfoNodes)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should round up reserved CPUs to whole cores", func() {
+		reserved, isolated, err := WholeCores(3, topologyInfoNodes)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(reserved.String()).To(Equal("0,2,8,10"))
+		Expect(isolated.String()).To(Equal("1,3-7,9,11-15"))
+	})
+
+	It("should reject reserving all cores", func() {
+		_, _, err := WholeCores(15, topologyInfoNodes)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should keep the topology through the compact report", func() {
+		report, err := FormatTopologyReport(&topology.Info{Nodes: topologyInfoNodes})
+		Expect(err).ToNot(HaveOccurred())
+
+		topologyInfo, err := ParseTopologyReport(report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(TotalCPUSet(topologyInfo.Nodes).String()).To(Equal("0-15"))
+		Expect(IsHyperthreadingEnabled(topologyInfo.Nodes)).To(BeTrue())
+
+		data, err := MarshalTopology(topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(HavePrefix(`{"nodes":[{"id":0,"cores":[{"id":0,"logical_processors":[0,8]},`))
+
+		parsed, err := ParseTopology(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(FormatTopologyReport(parsed)).To(Equal(report))
+	})
+
+	It("should fit the report of the large topology into the termination message", func() {
+		// 2 sockets with 128 cores and 2 threads per core
+		topologyInfo := &topology.Info{}
+		for nodeID := 0; nodeID < 2; nodeID++ {
+			node := &topology.Node{ID: nodeID}
+			for coreID := 0; coreID < 128; coreID++ {
+				processor := nodeID*128 + coreID
+				node.Cores = append(node.Cores, &cpu.ProcessorCore{
+					ID:                coreID,
+					LogicalProcessors: []int{processor, processor + 256},
+				})
+			}
+			topologyInfo.Nodes = append(topologyInfo.Nodes, node)
+		}
+
+		report, err := FormatTopologyReport(topologyInfo)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(len(report)).To(BeNumerically("<=", MaxTopologyReportSize))
+
+		parsed, err := ParseTopologyReport(report)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(TotalCPUSet(parsed.Nodes).Size()).To(Equal(512))
+	})
+
+	It("should reject the malformed report", func() {
+		_, err := ParseTopologyReport("")
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParseTopologyReport("0 0:0,a")
+		Expect(err).To(HaveOccurred())
+
+		report, err := FormatTopologyReport(&topology.Info{Nodes: topologyInfoNodes})
+		Expect(err).ToNot(HaveOccurred())
+		_, err = ParseTopologyReport(report[:len(report)/2])
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,13 @@
+package hardware
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestHardware(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Hardware Suite")
+}
@@ -0,0 +1,349 @@
+/*
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ * Copyright 2021 Red Hat, Inc.
+ */
+
+// Package hardware reads the hardware information of a node from the ghw snapshot,
+// the same snapshot that is gathered by must-gather and consumed by the performance profile creator
+package hardware
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/topology"
+
+	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+)
+
+const (
+	procMemInfo     = "proc/meminfo"
+	sysClassNet     = "sys/class/net"
+	sysNodeCPUList  = "sys/devices/system/node/node%d/cpulist"
+	sysNodeMemInfo  = "sys/devices/system/node/node%d/meminfo"
+	sysCPUCoreID    = "sys/devices/system/cpu/cpu%d/topology/core_id"
+	pciVendorIDFile = "vendor"
+	pciDeviceIDFile = "device"
+)
+
+const (
+	// maxSnapshotFileSize is the maximal size of a file in the snapshot, the largest files are
+	// /proc/cpuinfo and /proc/interrupts, which take hundreds of kilobytes on large nodes
+	maxSnapshotFileSize = 4 * 1024 * 1024
+	// maxSnapshotSize is the maximal size of all files in the snapshot, so the compressed archive
+	// can not expand to an arbitrary size
+	maxSnapshotSize = 64 * 1024 * 1024
+	// maxSnapshotEntries is the maximal number of entries in the snapshot
+	maxSnapshotEntries = 100000
+)
+
+var (
+	// memTotalRegex matches the total memory line of the meminfo file, for example "MemTotal: 32724976 kB"
+	memTotalRegex = regexp.MustCompile(`^MemTotal:\s+(\d+)\s+kB$`)
+	// numaMemTotalRegex matches the total memory line of the NUMA node meminfo file, for example "Node 0 MemTotal: 32724976 kB"
+	numaMemTotalRegex = regexp.MustCompile(`^Node\s+\d+\s+MemTotal:\s+(\d+)\s+kB$`)
+	// nodeCPUListRegex matches CPU lists of NUMA nodes, for example "sys/devices/system/node/node0/cpulist"
+	nodeCPUListRegex = regexp.MustCompile(`^sys/devices/system/node/node(\d+)/cpulist$`)
+)
+
+// Info contains the hardware information of the node
+type Info struct {
+	// Topology contains NUMA nodes and CPUs of the node, sorted by NUMA node IDs and CPU IDs
+	Topology *topology.Info
+	// TotalUsableMemory is the amount of memory in bytes usable by the system
+	TotalUsableMemory int64
+	// NUMAMemory contains the total memory in bytes of NUMA nodes, it is empty when the snapshot
+	// does not have the memory information of NUMA nodes
+	NUMAMemory map[int]int64
+	// NetDevices contains network devices backed by PCI devices, it is empty when the snapshot
+	// does not have the network information
+	NetDevices []NetDevice
+}
+
+// NetDevice contains the PCI identifiers of the network interface
+type NetDevice struct {
+	InterfaceName string
+	// VendorID and DeviceID are hexadecimal numbers with the 0x prefix, for example 0x8086
+	VendorID string
+	DeviceID string
+}
+
+// snapshot contains files of the snapshot archive, the archive is never unpacked on the disk,
+// symbolic links are kept as their targets and are resolved only inside the archive
+type snapshot struct {
+	files map[string][]byte
+	links map[string]string
+}
+
+// LoadSnapshot returns the hardware information from the content of the ghw snapshot archive
+func LoadSnapshot(data []byte) (*Info, error) {
+	s, err := readSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack the hardware snapshot: %v", err)
+	}
+
+	topologyInfo, err := s.getTopology()
+	if err != nil {
+		return nil, fmt.Errorf("can't obtain topology info from the hardware snapshot: %v", err)
+	}
+
+	memTotal, err := s.getMemTotal(procMemInfo, memTotalRegex)
+	if err != nil {
+		return nil, fmt.Errorf("can't obtain memory info from the hardware snapshot: %v", err)
+	}
+
+	info := &Info{
+		Topology:          topologyInfo,
+		TotalUsableMemory: memTotal,
+		NUMAMemory:        map[int]int64{},
+	}
+
+	for _, node := range topologyInfo.Nodes {
+		memTotal, err := s.getMemTotal(fmt.Sprintf(sysNodeMemInfo, node.ID), numaMemTotalRegex)
+		if err != nil {
+			return nil, err
+		}
+
+		// old snapshots do not have the meminfo of NUMA nodes
+		if memTotal == 0 {
+			info.NUMAMemory = map[int]int64{}
+			break
+		}
+		info.NUMAMemory[node.ID] = memTotal
+	}
+
+	info.NetDevices = s.getNetDevices()
+	return info, nil
+}
+
+// readSnapshot reads the gzipped tar archive in memory, it rejects entries outside of the archive root,
+// hard links, links pointing outside of the archive and special files, and limits the size of the content
+func readSnapshot(data []byte) (*snapshot, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	s := &snapshot{
+		files: map[string][]byte{},
+		links: map[string]string{},
+	}
+
+	var total int64
+	tr := tar.NewReader(gz)
+	for entries := 0; ; entries++ {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return s, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if entries >= maxSnapshotEntries {
+			return nil, fmt.Errorf("the snapshot has more than %d entries", maxSnapshotEntries)
+		}
+
+		name, err := getEntryName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			// directories are implied by names of files
+		case tar.TypeReg, tar.TypeRegA:
+			if header.Size > maxSnapshotFileSize {
+				return nil, fmt.Errorf("the entry %q is larger than %d bytes", header.Name, maxSnapshotFileSize)
+			}
+
+			total += header.Size
+			if total > maxSnapshotSize {
+				return nil, fmt.Errorf("the snapshot content is larger than %d bytes", maxSnapshotSize)
+			}
+
+			content, err := ioutil.ReadAll(io.LimitReader(tr, header.Size))
+			if err != nil {
+				return nil, err
+			}
+			s.files[name] = content
+		case tar.TypeSymlink:
+			// the snapshot links NUMA nodes to CPUs and network interfaces to devices with relative links
+			if path.IsAbs(header.Linkname) || isOutsideRoot(path.Join(path.Dir(name), header.Linkname)) {
+				return nil, fmt.Errorf("the link %q points outside of the snapshot", header.Name)
+			}
+			s.links[name] = header.Linkname
+		default:
+			return nil, fmt.Errorf("the entry %q has the unsupported type %q", header.Name, string(header.Typeflag))
+		}
+	}
+}
+
+// getEntryName returns the clean name of the archive entry relative to the archive root
+func getEntryName(name string) (string, error) {
+	if path.IsAbs(name) {
+		return "", fmt.Errorf("the entry %q has an absolute path", name)
+	}
+
+	for _, element := range strings.Split(name, "/") {
+		if element == ".." {
+			return "", fmt.Errorf("the entry %q points outside of the snapshot", name)
+		}
+	}
+
+	return path.Clean(name), nil
+}
+
+func isOutsideRoot(name string) bool {
+	return name == ".." || strings.HasPrefix(name, "../")
+}
+
+// getTopology returns the sorted topology built from CPU lists of NUMA nodes, CPUs are grouped
+// into cores by their core IDs, the same way ghw does
+func (s *snapshot) getTopology() (*topology.Info, error) {
+	topologyInfo := &topology.Info{}
+	for _, nodeID := range s.getNodeIDs() {
+		content := s.files[fmt.Sprintf(sysNodeCPUList, nodeID)]
+		cpus, err := cpuset.Parse(strings.TrimSpace(string(content)))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the CPU list of the NUMA node %d: %v", nodeID, err)
+		}
+
+		node := &topology.Node{ID: nodeID}
+		cores := map[int]*cpu.ProcessorCore{}
+		for _, cpuID := range cpus.ToSlice() {
+			content, ok := s.files[fmt.Sprintf(sysCPUCoreID, cpuID)]
+			if !ok {
+				return nil, fmt.Errorf("the CPU %d does not have the core ID", cpuID)
+			}
+
+			coreID, err := strconv.Atoi(strings.TrimSpace(string(content)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the core ID of the CPU %d: %v", cpuID, err)
+			}
+
+			core, ok := cores[coreID]
+			if !ok {
+				core = &cpu.ProcessorCore{ID: coreID}
+				cores[coreID] = core
+				node.Cores = append(node.Cores, core)
+			}
+			core.LogicalProcessors = append(core.LogicalProcessors, cpuID)
+			core.NumThreads = uint32(len(core.LogicalProcessors))
+		}
+		topologyInfo.Nodes = append(topologyInfo.Nodes, node)
+	}
+
+	if len(topologyInfo.Nodes) == 0 {
+		return nil, fmt.Errorf("the snapshot does not have NUMA nodes")
+	}
+
+	cpuallocation.SortTopology(topologyInfo)
+	for _, node := range topologyInfo.Nodes {
+		for i, core := range node.Cores {
+			core.Index = i
+		}
+	}
+	return topologyInfo, nil
+}
+
+// getNodeIDs returns sorted IDs of NUMA nodes that have CPU lists
+func (s *snapshot) getNodeIDs() []int {
+	var nodeIDs []int
+	for name := range s.files {
+		match := nodeCPUListRegex.FindStringSubmatch(name)
+		if match == nil {
+			continue
+		}
+
+		nodeID, err := strconv.Atoi(match[1])
+		if err != nil {
+			continue
+		}
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Ints(nodeIDs)
+	return nodeIDs
+}
+
+// getMemTotal returns the total memory in bytes from the meminfo file, or zero when the snapshot does not have it
+func (s *snapshot) getMemTotal(name string, memTotalRegex *regexp.Regexp) (int64, error) {
+	content, ok := s.files[name]
+	if !ok {
+		return 0, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		match := memTotalRegex.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if match == nil {
+			continue
+		}
+
+		kb, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return kb * 1024, nil
+	}
+	return 0, scanner.Err()
+}
+
+// getNetDevices returns network interfaces backed by PCI devices, old snapshots do not have network interfaces
+func (s *snapshot) getNetDevices() []NetDevice {
+	var devices []NetDevice
+	for name, link := range s.links {
+		if path.Dir(name) != sysClassNet {
+			continue
+		}
+
+		// the interface links to the directory under the backing device, for example
+		// ../../devices/pci0000:00/0000:00:1f.6/net/enp0s31f6, virtual interfaces do not have PCI identifiers
+		devicePath := path.Join(sysClassNet, link, "..", "..")
+		vendorID, ok := s.files[path.Join(devicePath, pciVendorIDFile)]
+		if !ok {
+			continue
+		}
+
+		deviceID, ok := s.files[path.Join(devicePath, pciDeviceIDFile)]
+		if !ok {
+			continue
+		}
+
+		devices = append(devices, NetDevice{
+			InterfaceName: path.Base(name),
+			VendorID:      strings.TrimSpace(string(vendorID)),
+			DeviceID:      strings.TrimSpace(string(deviceID)),
+		})
+	}
+
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].InterfaceName < devices[j].InterfaceName
+	})
+	return devices
+}
@@ -0,0 +1,145 @@
+package hardware
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+)
+
+const (
+	// snapshotPath contains the SNO snapshot with the NUMA node meminfo and the virtio network device
+	snapshotPath              = "../../testdata/hardware/sysinfo.tgz"
+	mustGatherSNOSnapshotGlob = "../../testdata/must-gather/must-gather.sno/*/nodes/*/sysinfo.tgz"
+)
+
+var _ = Describe("Hardware snapshot", func() {
+	It("should load the topology, the memory and network devices", func() {
+		data, err := ioutil.ReadFile(snapshotPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		info, err := LoadSnapshot(data)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(info.Topology.Nodes).To(HaveLen(1))
+		Expect(cpuallocation.TotalCPUSet(info.Topology.Nodes).String()).To(Equal("0-11"))
+		Expect(info.TotalUsableMemory).To(Equal(int64(32924120 * 1024)))
+		Expect(info.NUMAMemory).To(Equal(map[int]int64{0: 32924120 * 1024}))
+		Expect(info.NetDevices).To(ConsistOf(NetDevice{
+			InterfaceName: "ens3",
+			VendorID:      "0x1af4",
+			DeviceID:      "0x1000",
+		}))
+	})
+
+	It("should load snapshots without NUMA node memory and network devices", func() {
+		paths, err := filepath.Glob(mustGatherSNOSnapshotGlob)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(paths).To(HaveLen(1))
+
+		data, err := ioutil.ReadFile(paths[0])
+		Expect(err).ToNot(HaveOccurred())
+
+		info, err := LoadSnapshot(data)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cpuallocation.TotalCPUSet(info.Topology.Nodes).String()).To(Equal("0-11"))
+		Expect(info.NUMAMemory).To(BeEmpty())
+		Expect(info.NetDevices).To(BeEmpty())
+	})
+
+	It("should fail to load invalid snapshots", func() {
+		_, err := LoadSnapshot([]byte("invalid"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should load snapshots without unpacking them", func() {
+		data := newArchive(
+			&tar.Header{Name: "sys/devices/system/node/node0/cpulist", Typeflag: tar.TypeReg, Size: 4},
+			&tar.Header{Name: "sys/devices/system/cpu/cpu0/topology/core_id", Typeflag: tar.TypeReg, Size: 2},
+			&tar.Header{Name: "sys/devices/system/cpu/cpu1/topology/core_id", Typeflag: tar.TypeReg, Size: 2},
+			&tar.Header{Name: "sys/class/net/eth0", Typeflag: tar.TypeSymlink, Linkname: "../../devices/pci0000:00/0000:00:03.0/net/eth0"},
+			&tar.Header{Name: "sys/devices/pci0000:00/0000:00:03.0/vendor", Typeflag: tar.TypeReg, Size: 7},
+			&tar.Header{Name: "sys/devices/pci0000:00/0000:00:03.0/device", Typeflag: tar.TypeReg, Size: 7},
+		)
+
+		info, err := LoadSnapshot(data)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cpuallocation.TotalCPUSet(info.Topology.Nodes).String()).To(Equal("0-1"))
+		Expect(info.Topology.Nodes[0].Cores).To(HaveLen(1))
+		Expect(info.NetDevices).To(ConsistOf(NetDevice{
+			InterfaceName: "eth0",
+			VendorID:      "0x8086",
+			DeviceID:      "0x1572",
+		}))
+	})
+
+	It("should reject entries outside of the snapshot", func() {
+		testCases := map[*tar.Header]string{
+			{Name: "/etc/passwd", Typeflag: tar.TypeReg}:                                         "has an absolute path",
+			{Name: "sys/../../etc/passwd", Typeflag: tar.TypeReg}:                                "points outside of the snapshot",
+			{Name: "sys/class/net/eth0", Typeflag: tar.TypeSymlink, Linkname: "/etc"}:            "points outside of the snapshot",
+			{Name: "sys/class/net/eth0", Typeflag: tar.TypeSymlink, Linkname: "../../../../etc"}: "points outside of the snapshot",
+			{Name: "proc/meminfo", Typeflag: tar.TypeLink, Linkname: "proc/cpuinfo"}:             "has the unsupported type",
+		}
+
+		for header, expectedError := range testCases {
+			_, err := LoadSnapshot(newArchive(header))
+			Expect(err).To(HaveOccurred(), header.Name)
+			Expect(err.Error()).To(ContainSubstring(expectedError))
+		}
+	})
+
+	It("should reject large snapshots", func() {
+		_, err := LoadSnapshot(newArchive(&tar.Header{Name: "proc/cpuinfo", Typeflag: tar.TypeReg, Size: maxSnapshotFileSize + 1}))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("is larger than"))
+
+		var headers []*tar.Header
+		for i := 0; i <= maxSnapshotSize/maxSnapshotFileSize; i++ {
+			headers = append(headers, &tar.Header{Name: fmt.Sprintf("proc/irq/%d/node", i), Typeflag: tar.TypeReg, Size: maxSnapshotFileSize})
+		}
+		_, err = LoadSnapshot(newArchive(headers...))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("the snapshot content is larger than"))
+	})
+})
+
+// newArchive returns the gzipped tar archive with entries, the content of CPU lists, core IDs and PCI identifiers
+// is filled in, other regular files are filled with zeros
+func newArchive(headers ...*tar.Header) []byte {
+	content := map[string]string{
+		"sys/devices/system/node/node0/cpulist":        "0-1\n",
+		"sys/devices/system/cpu/cpu0/topology/core_id": "0\n",
+		"sys/devices/system/cpu/cpu1/topology/core_id": "0\n",
+		"sys/devices/pci0000:00/0000:00:03.0/vendor":   "0x8086\n",
+		"sys/devices/pci0000:00/0000:00:03.0/device":   "0x1572\n",
+	}
+
+	buf := &bytes.Buffer{}
+	gz := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gz)
+	for _, header := range headers {
+		Expect(tw.WriteHeader(header)).To(Succeed())
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data := []byte(content[header.Name])
+		if int64(len(data)) != header.Size {
+			data = make([]byte, header.Size)
+		}
+		_, err := tw.Write(data)
+		Expect(err).ToNot(HaveOccurred())
+	}
+	Expect(tw.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+	return buf.Bytes()
+}
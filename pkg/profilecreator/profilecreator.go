@@ -28,10 +28,14 @@ import (
 
 	"github.com/jaypipes/ghw"
 	"github.com/jaypipes/ghw/pkg/cpu"
+	"github.com/jaypipes/ghw/pkg/memory"
+	"github.com/jaypipes/ghw/pkg/net"
 	"github.com/jaypipes/ghw/pkg/option"
 	"github.com/jaypipes/ghw/pkg/topology"
 	log "github.com/sirupsen/logrus"
 
+	"github.com/openshift-kni/performance-addon-operators/pkg/cpuallocation"
+
 	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 	"k8s.io/kubernetes/pkg/kubelet/cm/cpuset"
 
@@ -57,8 +61,13 @@ const (
 	SysInfoFileName = "sysinfo.tgz"
 	// noSMTKernelArg is the kernel arg value to disable SMT in a system
 	noSMTKernelArg = "nosmt"
-	// allCores correspond to the value when all the processorCores need to be added to the generated CPUset
-	allCores = -1
+)
+
+// CPU vendors of nodes, the values match CPU vendors of the performance profile
+const (
+	CPUVendorIntel = "Intel"
+	CPUVendorAMD   = "AMD"
+	CPUVendorARM   = "ARM"
 )
 
 var (
@@ -70,6 +79,18 @@ var (
 	ValidPowerConsumptionModes = []string{"default", "low-latency", "ultra-low-latency"}
 	lowLatencyKernelArgs       = map[string]bool{"nmi_watchdog=0": true, "audit=0": true, "mce=off": true}
 	ultraLowLatencyKernelArgs  = map[string]bool{"processor.max_cstate=1": true, "intel_idle.max_cstate=0": true, "idle=poll": true}
+	// the machine check exceptions and the idle polling are specific to x86 CPUs, so arm64 nodes
+	// disable the CPU idle states via the cpuidle subsystem
+	vendorsLowLatencyKernelArgs = map[string]map[string]bool{
+		CPUVendorIntel: lowLatencyKernelArgs,
+		CPUVendorAMD:   lowLatencyKernelArgs,
+		CPUVendorARM:   {"nmi_watchdog=0": true, "audit=0": true},
+	}
+	vendorsUltraLowLatencyKernelArgs = map[string]map[string]bool{
+		CPUVendorIntel: ultraLowLatencyKernelArgs,
+		CPUVendorAMD:   {"processor.max_cstate=1": true, "idle=poll": true},
+		CPUVendorARM:   {"cpuidle.off=1": true},
+	}
 )
 
 func getMustGatherFullPathsWithFilter(mustGatherPath string, suffix string, filter string) (string, error) {
@@ -227,6 +248,12 @@ func NewGHWHandler(mustGatherDirPath string, node *v1.Node) (*GHWHandler, error)
 	return ghwHandler, nil
 }
 
+// NewGHWHandlerFromUnpackedSnapshot is a handler to use ghw options corresponding to the ghw snapshot
+// already unpacked under the root directory
+func NewGHWHandlerFromUnpackedSnapshot(root string, node *v1.Node) *GHWHandler {
+	return &GHWHandler{snapShotOptions: ghw.WithChroot(root), Node: node}
+}
+
 // GHWHandler is a wrapper around ghw to get the API object
 type GHWHandler struct {
 	snapShotOptions *option.Option
@@ -238,25 +265,42 @@ func (ghwHandler GHWHandler) CPU() (*cpu.Info, error) {
 	return ghw.CPU(ghwHandler.snapShotOptions)
 }
 
+// Memory returns a MemoryInfo struct that contains information about the memory on the host system
+func (ghwHandler GHWHandler) Memory() (*memory.Info, error) {
+	return ghw.Memory(ghwHandler.snapShotOptions)
+}
+
+// Network returns a NetworkInfo struct that contains information about the network interfaces on the host system
+func (ghwHandler GHWHandler) Network() (*net.Info, error) {
+	return ghw.Network(ghwHandler.snapShotOptions)
+}
+
+// GetCPUVendor returns the CPU vendor of the node, arm64 nodes are detected by the node architecture label
+func (ghwHandler GHWHandler) GetCPUVendor() (string, error) {
+	if ghwHandler.Node != nil && ghwHandler.Node.Labels[v1.LabelArchStable] == "arm64" {
+		return CPUVendorARM, nil
+	}
+
+	cpuInfo, err := ghwHandler.CPU()
+	if err != nil {
+		return "", fmt.Errorf("can't obtain CPU info from GHW snapshot: %v", err)
+	}
+
+	for _, processor := range cpuInfo.Processors {
+		if processor.Vendor == "AuthenticAMD" {
+			return CPUVendorAMD, nil
+		}
+	}
+	return CPUVendorIntel, nil
+}
+
 // SortedTopology returns a TopologyInfo struct that contains information about the Topology sorted by numa ids and cpu ids on the host system
 func (ghwHandler GHWHandler) SortedTopology() (*topology.Info, error) {
 	topologyInfo, err := ghw.Topology(ghwHandler.snapShotOptions)
 	if err != nil {
 		return nil, fmt.Errorf("can't obtain topology info from GHW snapshot: %v", err)
 	}
-	sort.Slice(topologyInfo.Nodes, func(x, y int) bool {
-		return topologyInfo.Nodes[x].ID < topologyInfo.Nodes[y].ID
-	})
-	for _, node := range topologyInfo.Nodes {
-		for _, core := range node.Cores {
-			sort.Slice(core.LogicalProcessors, func(x, y int) bool {
-				return core.LogicalProcessors[x] < core.LogicalProcessors[y]
-			})
-		}
-		sort.Slice(node.Cores, func(i, j int) bool {
-			return node.Cores[i].LogicalProcessors[0] < node.Cores[j].LogicalProcessors[0]
-		})
-	}
+	cpuallocation.SortTopology(topologyInfo)
 	return topologyInfo, nil
 }
 
@@ -345,76 +389,16 @@ func (ghwHandler GHWHandler) GetReservedAndIsolatedCPUs(reservedCPUCount int, sp
 }
 
 // getCPUsSplitAcrossNUMA returns Reserved and Isolated CPUs split across NUMA nodes
-// We identify the right number of CPUs that need to be allocated per NUMA node, meaning reservedPerNuma + (the additional number based on the remainder and the NUMA node)
-// E.g. If the user requests 15 reserved cpus and we have 4 numa nodes, we find reservedPerNuma in this case is 3 and remainder = 3.
-// For each numa node we find a max which keeps track of the cumulative resources that should be allocated for each NUMA node:
-// max = (numaID+1)*reservedPerNuma + (numaNodeNum - remainder)
-// For NUMA node 0 max = (0+1)*3 + 4-3 = 4 remainder is decremented => remainder is 2
-// For NUMA node 1 max = (1+1)*3 + 4-2 = 8 remainder is decremented => remainder is 1
-// For NUMA node 2 max = (2+1)*3 + 4-2 = 12 remainder is decremented => remainder is 0
-// For NUMA Node 3 remainder = 0 so max = 12 + 3 = 15.
 func (ghwHandler GHWHandler) getCPUsSplitAcrossNUMA(reservedCPUCount int, htEnabled bool, topologyInfoNodes []*topology.Node) (cpuset.CPUSet, cpuset.CPUSet, error) {
-	reservedCPUSet := cpuset.NewBuilder()
-	var isolatedCPUSet cpuset.CPUSet
-	numaNodeNum := len(topologyInfoNodes)
-
-	max := 0
-	reservedPerNuma := reservedCPUCount / numaNodeNum
-	remainder := reservedCPUCount % numaNodeNum
-	if remainder != 0 {
+	if reservedCPUCount%len(topologyInfoNodes) != 0 {
 		log.Warnf("The reserved CPUs cannot be split equally across NUMA Nodes")
 	}
-	for numaID, node := range topologyInfoNodes {
-		if remainder != 0 {
-			max = (numaID+1)*reservedPerNuma + (numaNodeNum - remainder)
-			remainder--
-		} else {
-			max = max + reservedPerNuma
-		}
-		if max%2 != 0 && htEnabled {
-			return reservedCPUSet.Result(), isolatedCPUSet, fmt.Errorf("can't allocate odd number of CPUs from a NUMA Node")
-		}
-		addCoresToCPUSet(reservedCPUSet, max, node.Cores)
-	}
-	totalCPUSet := totalCPUSetFromTopology(topologyInfoNodes)
-	isolatedCPUSet = totalCPUSet.Difference(reservedCPUSet.Result())
-	return reservedCPUSet.Result(), isolatedCPUSet, nil
+	return cpuallocation.SplitAcrossNUMA(reservedCPUCount, htEnabled, topologyInfoNodes)
 }
 
 // getCPUsSequentially returns Reserved and Isolated CPUs sequentially
 func (ghwHandler GHWHandler) getCPUsSequentially(reservedCPUCount int, htEnabled bool, topologyInfoNodes []*topology.Node) (cpuset.CPUSet, cpuset.CPUSet, error) {
-	reservedCPUSet := cpuset.NewBuilder()
-	var isolatedCPUSet cpuset.CPUSet
-	if reservedCPUCount%2 != 0 && htEnabled {
-		return reservedCPUSet.Result(), isolatedCPUSet, fmt.Errorf("can't allocate odd number of CPUs from a NUMA Node")
-	}
-	for _, node := range topologyInfoNodes {
-		addCoresToCPUSet(reservedCPUSet, reservedCPUCount, node.Cores)
-	}
-	totalCPUSet := totalCPUSetFromTopology(topologyInfoNodes)
-	isolatedCPUSet = totalCPUSet.Difference(reservedCPUSet.Result())
-	return reservedCPUSet.Result(), isolatedCPUSet, nil
-}
-
-func totalCPUSetFromTopology(topologyInfoNodes []*topology.Node) cpuset.CPUSet {
-	totalCPUSet := cpuset.NewBuilder()
-	for _, node := range topologyInfoNodes {
-		//all the cores from node.Cores need to be added, hence allCores is specified as the max value
-		addCoresToCPUSet(totalCPUSet, allCores, node.Cores)
-	}
-	return totalCPUSet.Result()
-}
-
-// addCoresToCPUSet adds logical cores from the slice of *cpu.ProcessorCore to a CPUset till the cpuset size is equal to the max value speicifed
-// In case the max is specified as allCores, all the cores from the slice of *cpu.ProcessorCore are added to the CPUset
-func addCoresToCPUSet(reservedCPUSet cpuset.Builder, max int, cores []*cpu.ProcessorCore) {
-	for _, processorCore := range cores {
-		for _, core := range processorCore.LogicalProcessors {
-			if reservedCPUSet.Result().Size() < max || max == allCores {
-				reservedCPUSet.Add(core)
-			}
-		}
-	}
+	return cpuallocation.Sequential(reservedCPUCount, htEnabled, topologyInfoNodes)
 }
 
 // IsHyperthreadingEnabled checks if hyperthreading is enabled on the system or not
@@ -500,8 +484,8 @@ func ensureSameTopology(topology1, topology2 *topology.Info) error {
 	return nil
 }
 
-// GetAdditionalKernelArgs returns a set of kernel parameters based on the power mode
-func GetAdditionalKernelArgs(powerMode string, disableHT bool) []string {
+// GetAdditionalKernelArgs returns a set of kernel parameters based on the power mode and the CPU vendor
+func GetAdditionalKernelArgs(powerMode string, disableHT bool, vendor string) []string {
 	kernelArgsSet := make(map[string]bool)
 	kernelArgsSlice := make([]string, 0, 6)
 	switch powerMode {
@@ -510,16 +494,16 @@ func GetAdditionalKernelArgs(powerMode string, disableHT bool) []string {
 		kernelArgsSlice = []string{}
 	//low-latency
 	case ValidPowerConsumptionModes[1]:
-		for arg, exist := range lowLatencyKernelArgs {
+		for arg, exist := range vendorsLowLatencyKernelArgs[vendor] {
 			kernelArgsSet[arg] = exist
 		}
 	//ultra-low-latency
 	case ValidPowerConsumptionModes[2]:
 		//computing the union for two sets (lowLatencyKernelArgs,ultraLowLatencyKernelArgs)
-		for arg, exist := range lowLatencyKernelArgs {
+		for arg, exist := range vendorsLowLatencyKernelArgs[vendor] {
 			kernelArgsSet[arg] = exist
 		}
-		for arg, exist := range ultraLowLatencyKernelArgs {
+		for arg, exist := range vendorsUltraLowLatencyKernelArgs[vendor] {
 			kernelArgsSet[arg] = exist
 		}
 	}
@@ -419,6 +419,37 @@ var _ = Describe("PerformanceProfileCreator: Check if Hyperthreading enabled/dis
 	})
 })
 
+var _ = Describe("PerformanceProfileCreator: Detecting the CPU vendor of a system", func() {
+	var mustGatherDirAbsolutePath string
+	var node *v1.Node
+	var handle *GHWHandler
+	var err error
+
+	BeforeEach(func() {
+		node = newTestNode("worker1")
+		mustGatherDirAbsolutePath, err = filepath.Abs(mustGatherDirPath)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Context("Check the CPU vendor of the system", func() {
+		It("Ensure we detect correctly the CPU vendor from the processors of a system", func() {
+			handle, err = NewGHWHandler(mustGatherDirAbsolutePath, node)
+			Expect(err).ToNot(HaveOccurred())
+			vendor, err := handle.GetCPUVendor()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vendor).To(Equal(CPUVendorIntel))
+		})
+		It("Ensure we detect correctly the CPU vendor from the architecture of a node", func() {
+			node.Labels = map[string]string{v1.LabelArchStable: "arm64"}
+			handle, err = NewGHWHandler(mustGatherDirAbsolutePath, node)
+			Expect(err).ToNot(HaveOccurred())
+			vendor, err := handle.GetCPUVendor()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vendor).To(Equal(CPUVendorARM))
+		})
+	})
+})
+
 var _ = Describe("PerformanceProfileCreator: Test Helper Functions getCPUsSplitAcrossNUMA and getCPUsSequentially", func() {
 	var mustGatherDirAbsolutePath string
 	var node *v1.Node
@@ -744,7 +775,7 @@ var _ = Describe("PerformanceProfileCreator: Test Helper Function GetAdditionalK
 		It("Ensure kernel args are populated correctly in case of low-latency ", func() {
 			powerMode = "default"
 			disableHT = false
-			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT)
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorIntel)
 			Expect(kernelArgs).To(BeEquivalentTo([]string{}))
 		})
 
@@ -757,7 +788,7 @@ var _ = Describe("PerformanceProfileCreator: Test Helper Function GetAdditionalK
 				"mce=off",
 				"nmi_watchdog=0",
 			}
-			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT)
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorIntel)
 			sort.Strings(kernelArgs) // sort to avoid inequality due to difference in order
 			Expect(kernelArgs).To(BeEquivalentTo(args))
 		})
@@ -774,7 +805,7 @@ var _ = Describe("PerformanceProfileCreator: Test Helper Function GetAdditionalK
 				"nmi_watchdog=0",
 				"processor.max_cstate=1",
 			}
-			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT)
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorIntel)
 			sort.Strings(kernelArgs) // sort to avoid inequality due to difference in order
 			Expect(kernelArgs).To(BeEquivalentTo(args))
 		})
@@ -792,10 +823,37 @@ var _ = Describe("PerformanceProfileCreator: Test Helper Function GetAdditionalK
 				"nosmt",
 				"processor.max_cstate=1",
 			}
-			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT)
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorIntel)
 			sort.Strings(kernelArgs) // sort to avoid inequality due to difference in order
 			Expect(kernelArgs).To(BeEquivalentTo(args))
 		})
 
 	})
+	Context("Ensure kernel args are populated correctly for the CPU vendor", func() {
+		It("Ensure Intel specific kernel args are skipped in case of AMD ", func() {
+			powerMode = "ultra-low-latency"
+			disableHT = false
+			args := []string{"audit=0",
+				"idle=poll",
+				"mce=off",
+				"nmi_watchdog=0",
+				"processor.max_cstate=1",
+			}
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorAMD)
+			sort.Strings(kernelArgs) // sort to avoid inequality due to difference in order
+			Expect(kernelArgs).To(BeEquivalentTo(args))
+		})
+
+		It("Ensure x86 specific kernel args are skipped in case of ARM ", func() {
+			powerMode = "ultra-low-latency"
+			disableHT = false
+			args := []string{"audit=0",
+				"cpuidle.off=1",
+				"nmi_watchdog=0",
+			}
+			kernelArgs := GetAdditionalKernelArgs(powerMode, disableHT, CPUVendorARM)
+			sort.Strings(kernelArgs) // sort to avoid inequality due to difference in order
+			Expect(kernelArgs).To(BeEquivalentTo(args))
+		})
+	})
 })
@@ -143,6 +143,8 @@ func kniExpectedCloneContent() []string {
 		// KNI-specific CPU infos:
 		"/sys/devices/system/cpu/smt/active",
 		"/proc/sys/kernel/sched_domain/cpu*/domain*/flags",
+		// memory of NUMA nodes, used to validate huge pages
+		"/sys/devices/system/node/node*/meminfo",
 		// BIOS/firmware versions
 		"/sys/class/dmi/id/bios*",
 		"/sys/class/dmi/id/product_family",